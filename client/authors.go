@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Author is an entry of /api/authors. Books refer to it with
+// Contributor.AuthorID.
+type Author struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// SortName orders the list of authors; the server defaults it to Name.
+	SortName  string `json:"sortName,omitempty"`
+	BirthYear string `json:"birthYear,omitempty"`
+	DeathYear string `json:"deathYear,omitempty"`
+	Bio       string `json:"bio,omitempty"`
+}
+
+// ListAuthors returns every author, ordered by sort name.
+func (c *Client) ListAuthors(ctx context.Context) ([]Author, error) {
+	var list []Author
+	if _, err := c.do(ctx, http.MethodGet, "/api/authors", nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetAuthor returns the author with the given id.
+func (c *Client) GetAuthor(ctx context.Context, id string) (*Author, error) {
+	var author Author
+	if _, err := c.do(ctx, http.MethodGet, "/api/authors/"+url.PathEscape(id), nil, &author); err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// CreateAuthor adds an author and returns it as stored.
+func (c *Client) CreateAuthor(ctx context.Context, author Author) (*Author, error) {
+	var created Author
+	if _, err := c.do(ctx, http.MethodPost, "/api/authors", author, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateAuthor changes the non-empty fields of patch on the author with the
+// given id, and returns the author as updated. A new name is copied into
+// the books of the author.
+func (c *Client) UpdateAuthor(ctx context.Context, id string, patch Author) (*Author, error) {
+	var author Author
+	if _, err := c.do(ctx, http.MethodPut, "/api/authors/"+url.PathEscape(id), patch, &author); err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// DeleteAuthor removes the author with the given id. It fails with
+// ErrConflict while books refer to the author.
+func (c *Client) DeleteAuthor(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/api/authors/"+url.PathEscape(id), nil, nil)
+	return err
+}
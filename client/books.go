@@ -0,0 +1,275 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Book is a book as the API sends and receives it. ID is the id of the
+// book, not its MongoID.
+//
+// Author holds the names of the authors separated by commas, Authors
+// everybody who worked on the book. Either can be sent; Authors wins if
+// both are.
+type Book struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Author  string `json:"author,omitempty"`
+	Edition string `json:"edition,omitempty"`
+	Pages   string `json:"pages,omitempty"`
+	Year    string `json:"year,omitempty"`
+	// ISBN is sent as ISBN-10 or ISBN-13 and stored as ISBN-13 without
+	// hyphens.
+	ISBN      string        `json:"isbn,omitempty"`
+	Authors   []Contributor `json:"authors,omitempty"`
+	Publisher string        `json:"publisher,omitempty"`
+	Series    string        `json:"series,omitempty"`
+	// Volume is the number of the book within its series.
+	Volume string `json:"volume,omitempty"`
+	// Tags are free-form; genres come from the list of Genres. Both are
+	// stored lowercase.
+	Tags   []string `json:"tags,omitempty"`
+	Genres []string `json:"genres,omitempty"`
+	// Rating is the average rating of the reviews of the book, and
+	// ReviewCount their number. The server ignores both in requests.
+	Rating      float64 `json:"rating,omitempty"`
+	ReviewCount int     `json:"reviewCount,omitempty"`
+	// Stock is the number of copies on hand, which only AdjustStock
+	// changes; the server ignores it in requests. The book is low on stock
+	// at or below ReorderLevel.
+	Stock        int `json:"stock,omitempty"`
+	ReorderLevel int `json:"reorderLevel,omitempty"`
+	// ListPrice is an amount like "12.99" in Currency, which defaults to
+	// the base currency of the rates. EffectivePrice is the list price
+	// after the best matching price rule; the server ignores it in
+	// requests.
+	ListPrice      string `json:"listPrice,omitempty"`
+	Currency       string `json:"currency,omitempty"`
+	EffectivePrice *Price `json:"effectivePrice,omitempty"`
+	// Copies is the number of copies of the lending library, and Available
+	// those on the shelf. Only AddCopy, RemoveCopy and the loans change
+	// them; the server ignores both in requests.
+	Copies    int `json:"copies,omitempty"`
+	Available int `json:"available,omitempty"`
+}
+
+// Roles of contributors.
+const (
+	RoleAuthor     = "author"
+	RoleEditor     = "editor"
+	RoleTranslator = "translator"
+)
+
+// Contributor is a person who worked on a book. An empty role is read as
+// RoleAuthor. AuthorID refers to an Author, whose name the server fills in.
+type Contributor struct {
+	Name     string `json:"name,omitempty"`
+	Role     string `json:"role,omitempty"`
+	AuthorID string `json:"authorId,omitempty"`
+}
+
+// List returns every book.
+func (c *Client) List(ctx context.Context) ([]Book, error) {
+	var list []Book
+	if _, err := c.do(ctx, http.MethodGet, "/api/books", nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListByRating returns every book, the best rated first.
+func (c *Client) ListByRating(ctx context.Context) ([]Book, error) {
+	var list []Book
+	if _, err := c.do(ctx, http.MethodGet, "/api/books?sort=rating", nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// FindByISBN returns the books with the given ISBN, in any of its forms.
+func (c *Client) FindByISBN(ctx context.Context, isbn string) ([]Book, error) {
+	var list []Book
+	if _, err := c.do(ctx, http.MethodGet, "/api/books?isbn="+url.QueryEscape(isbn), nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// MaxPageSize is the largest page the API returns, larger limits are cut
+// down to it.
+const MaxPageSize = 500
+
+// Page is one page of books.
+type Page struct {
+	Books  []Book
+	Offset int
+	// Total is the number of books in the catalog.
+	Total int
+}
+
+// ListPage returns up to limit books after skipping offset of them.
+func (c *Client) ListPage(ctx context.Context, offset, limit int) (*Page, error) {
+	query := url.Values{}
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+
+	page := &Page{Offset: offset}
+	res, err := c.do(ctx, http.MethodGet, "/api/books?"+query.Encode(), nil, &page.Books)
+	if err != nil {
+		return nil, err
+	}
+	if page.Total, err = strconv.Atoi(res.Header.Get("X-Total-Count")); err != nil {
+		return nil, fmt.Errorf("bookstore: invalid X-Total-Count: %w", err)
+	}
+	return page, nil
+}
+
+// Get returns the book with the given id.
+func (c *Client) Get(ctx context.Context, id string) (*Book, error) {
+	var book Book
+	if _, err := c.do(ctx, http.MethodGet, "/api/books/"+url.PathEscape(id), nil, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// GetIn returns the book with the given id, its effective price converted
+// to currency.
+func (c *Client) GetIn(ctx context.Context, id, currency string) (*Book, error) {
+	var book Book
+	path := "/api/books/" + url.PathEscape(id) + "?currency=" + url.QueryEscape(currency)
+	if _, err := c.do(ctx, http.MethodGet, path, nil, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// Create adds a book and returns it as stored.
+func (c *Client) Create(ctx context.Context, book Book) (*Book, error) {
+	var created Book
+	if _, err := c.do(ctx, http.MethodPost, "/api/books", book, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Update changes the non-empty fields of patch on the book with the given
+// id. The id itself cannot be changed.
+func (c *Client) Update(ctx context.Context, id string, patch Book) error {
+	_, err := c.do(ctx, http.MethodPut, "/api/books/"+url.PathEscape(id), patch, nil)
+	return err
+}
+
+// Delete removes the book with the given id.
+func (c *Client) Delete(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/api/books/"+url.PathEscape(id), nil, nil)
+	return err
+}
+
+// ImportResult reports what Import did with each book.
+type ImportResult struct {
+	Created []string
+	// Existing lists the books skipped because their id was taken.
+	Existing []string
+	// Failed maps the ids of the books that could not be added to why.
+	Failed map[string]error
+}
+
+// Import adds the books one by one. Books whose id is taken are skipped and
+// other failures recorded, so one bad book does not stop the rest. The
+// error is only set when ctx is done.
+func (c *Client) Import(ctx context.Context, list []Book) (*ImportResult, error) {
+	result := &ImportResult{Failed: map[string]error{}}
+	for _, book := range list {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		_, err := c.Create(ctx, book)
+		switch {
+		case err == nil:
+			result.Created = append(result.Created, book.ID)
+		case errors.Is(err, ErrConflict):
+			result.Existing = append(result.Existing, book.ID)
+		default:
+			result.Failed[book.ID] = err
+		}
+	}
+	return result, nil
+}
+
+// Iterator walks through the books page by page, see Client.Books.
+type Iterator struct {
+	c        *Client
+	pageSize int
+	offset   int
+	total    int
+	page     []Book
+	book     Book
+	err      error
+	done     bool
+}
+
+// Books returns an iterator over every book, fetching pageSize books, at
+// most MaxPageSize, per request:
+//
+//	it := c.Books(100)
+//	for it.Next(ctx) {
+//		fmt.Println(it.Book().Title)
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+func (c *Client) Books(pageSize int) *Iterator {
+	// The API cuts larger pages down, which Next would take for the last.
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	return &Iterator{c: c, pageSize: pageSize, total: -1}
+}
+
+// Next advances to the next book, fetching the next page when needed. It
+// returns false at the end or on an error.
+func (it *Iterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+		page, err := it.c.ListPage(ctx, it.offset, it.pageSize)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = page.Books
+		it.total = page.Total
+		it.offset += len(page.Books)
+		it.done = len(page.Books) < it.pageSize || it.offset >= page.Total
+		if len(it.page) == 0 {
+			return false
+		}
+	}
+	it.book, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Book returns the current book.
+func (it *Iterator) Book() Book {
+	return it.book
+}
+
+// Total returns the number of books in the catalog, or -1 before the first
+// page was fetched.
+func (it *Iterator) Total() int {
+	return it.total
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// pageServer serves total books from /api/books, cutting the pages down to
+// MaxPageSize like the API.
+type pageServer struct {
+	total int
+
+	mu     sync.Mutex
+	limits []int
+}
+
+func (s *pageServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	s.mu.Lock()
+	s.limits = append(s.limits, limit)
+	s.mu.Unlock()
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	page := []Book{}
+	for i := offset; i < offset+limit && i < s.total; i++ {
+		page = append(page, Book{ID: strconv.Itoa(i)})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(s.total))
+	json.NewEncoder(w).Encode(page)
+}
+
+func TestBooks(t *testing.T) {
+	tests := []struct {
+		total, pageSize int
+		wantRequests    int
+	}{
+		{0, 10, 1},
+		{25, 10, 3},
+		{20, 10, 2},
+		{1234, 1000, 3},
+		{1000, MaxPageSize + 1, 2},
+	}
+	for _, tt := range tests {
+		srv := &pageServer{total: tt.total}
+		c := setup(t, srv)
+
+		it := c.Books(tt.pageSize)
+		n := 0
+		for it.Next(context.Background()) {
+			if id := it.Book().ID; id != strconv.Itoa(n) {
+				t.Errorf("%d books by %d: book %d has id %s", tt.total, tt.pageSize, n, id)
+			}
+			n++
+		}
+		if err := it.Err(); err != nil {
+			t.Errorf("%d books by %d: %v", tt.total, tt.pageSize, err)
+		}
+		if n != tt.total || it.Total() != tt.total {
+			t.Errorf("%d books by %d: read %d books, Total() = %d", tt.total, tt.pageSize, n, it.Total())
+		}
+		srv.mu.Lock()
+		limits := srv.limits
+		srv.mu.Unlock()
+		if len(limits) != tt.wantRequests {
+			t.Errorf("%d books by %d: %d requests, want %d", tt.total, tt.pageSize, len(limits), tt.wantRequests)
+		}
+		for _, limit := range limits {
+			if limit > MaxPageSize {
+				t.Errorf("%d books by %d: asked for %d books, more than MaxPageSize", tt.total, tt.pageSize, limit)
+			}
+		}
+	}
+}
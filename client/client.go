@@ -0,0 +1,189 @@
+// Package client is a typed Go client for the REST API of the book store,
+// /api/books as described by its OpenAPI document (see internal/openapi).
+// It retries failed requests with backoff and maps the problem details of
+// failed requests onto *Error.
+//
+//	c := client.New("http://localhost:3030")
+//	book, err := c.Create(ctx, client.Book{ID: "1", Title: "Dune", Author: "Frank Herbert"})
+//	if errors.Is(err, client.ErrConflict) {
+//		// a book with this id already exists
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client talks to one book store. Its methods are safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retry      Retry
+	userAgent  string
+	header     http.Header
+}
+
+// Retry configures how failed requests are retried. A request is retried
+// when it could not be sent or the server answered 429, 502, 503 or 504;
+// POST only when the server did not process it (429 and 503). The n-th retry
+// waits Backoff·2ⁿ⁻¹, up to MaxBackoff, with jitter.
+type Retry struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetry is the retry policy of a new client.
+var DefaultRetry = Retry{Attempts: 4, Backoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}
+
+// Option configures a client.
+type Option func(*Client)
+
+// WithHTTPClient makes the client send its requests with hc.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithRetry replaces the retry policy. Retry{} turns retries off.
+func WithRetry(r Retry) Option {
+	return func(c *Client) { c.retry = r }
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// WithHeader adds a header to every request.
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Add(key, value) }
+}
+
+// WithToken authenticates every request with a bearer token, for
+// deployments behind a gateway that asks for one.
+func WithToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// New returns a client for the book store at baseURL, e.g.
+// "http://localhost:3030".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		retry:      DefaultRetry,
+		userAgent:  "bookstore-client/1",
+		header:     http.Header{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// do sends a request, retrying it as configured, and decodes a successful
+// response body into out unless it is nil. It returns the final response
+// with its body already closed.
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) (*http.Response, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := c.send(ctx, method, path, body)
+		if err == nil && res.StatusCode < 400 {
+			defer res.Body.Close()
+			if out != nil {
+				if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+					return res, err
+				}
+			}
+			return res, nil
+		}
+
+		var fail error
+		if err != nil {
+			fail = err
+		} else {
+			fail = readError(res)
+		}
+		if attempt >= c.retry.Attempts || !retryable(method, res, err) || ctx.Err() != nil {
+			return res, fail
+		}
+
+		select {
+		case <-ctx.Done():
+			return res, fail
+		case <-time.After(c.backoff(attempt, res)):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json, application/problem+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return c.httpClient.Do(req)
+}
+
+// retryable reports whether a failed request may be sent again.
+func retryable(method string, res *http.Response, err error) bool {
+	if err != nil {
+		// The request may have reached the server, sending a POST twice
+		// could create the book twice.
+		return method != http.MethodPost
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return method != http.MethodPost
+	}
+	return false
+}
+
+// backoff is the delay before the retry following attempt. A Retry-After
+// header in seconds takes precedence.
+func (c *Client) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if after, err := time.ParseDuration(res.Header.Get("Retry-After") + "s"); err == nil && after > 0 {
+			return after
+		}
+	}
+	d := c.retry.Backoff << (attempt - 1)
+	if c.retry.MaxBackoff > 0 && (d > c.retry.MaxBackoff || d <= 0) {
+		d = c.retry.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Up to a quarter less, so clients failing together do not retry
+	// together.
+	return d - time.Duration(rand.Int63n(int64(d)/4+1))
+}
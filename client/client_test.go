@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// statusServer answers the requests it gets with statuses, one after the
+// other, and 200 once they are used up.
+type statusServer struct {
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	times    []time.Time
+}
+
+func (s *statusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := http.StatusOK
+	if n := len(s.times); n < len(s.statuses) {
+		status = s.statuses[n]
+	}
+	s.times = append(s.times, time.Now())
+	for key, values := range s.header {
+		w.Header()[key] = values
+	}
+	if status == http.StatusOK {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","title":"Dune"}`))
+		return
+	}
+	w.WriteHeader(status)
+}
+
+// requests returns when the requests came in.
+func (s *statusServer) requests() []time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Time(nil), s.times...)
+}
+
+func setup(t *testing.T, srv http.Handler) *Client {
+	t.Helper()
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return New(ts.URL, WithRetry(Retry{Attempts: 3, Backoff: time.Millisecond}))
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		statuses   []int
+		wantCalls  int
+		wantStatus int
+	}{
+		{"success", http.MethodGet, nil, 1, 0},
+		{"503 then success", http.MethodGet, []int{503}, 2, 0},
+		{"502 and 504 then success", http.MethodGet, []int{502, 504}, 3, 0},
+		{"429 then success", http.MethodGet, []int{429}, 2, 0},
+		{"out of attempts", http.MethodGet, []int{503, 503, 503, 503}, 3, 503},
+		{"500 is not retried", http.MethodGet, []int{500}, 1, 500},
+		{"404 is not retried", http.MethodGet, []int{404}, 1, 404},
+		{"PUT 502 then success", http.MethodPut, []int{502}, 2, 0},
+		{"POST 503 then success", http.MethodPost, []int{503}, 2, 0},
+		{"POST 429 then success", http.MethodPost, []int{429}, 2, 0},
+		{"POST 502 is not retried", http.MethodPost, []int{502}, 1, 502},
+		{"POST 504 is not retried", http.MethodPost, []int{504}, 1, 504},
+		{"POST 400 is not retried", http.MethodPost, []int{400}, 1, 400},
+		{"POST 409 is not retried", http.MethodPost, []int{409}, 1, 409},
+	}
+	for _, tt := range tests {
+		srv := &statusServer{statuses: tt.statuses}
+		c := setup(t, srv)
+
+		var in interface{}
+		if tt.method != http.MethodGet {
+			in = Book{ID: "1", Title: "Dune"}
+		}
+		_, err := c.do(context.Background(), tt.method, "/api/books", in, nil)
+
+		if got := len(srv.requests()); got != tt.wantCalls {
+			t.Errorf("%s: %d requests, want %d", tt.name, got, tt.wantCalls)
+		}
+		var e *Error
+		switch {
+		case tt.wantStatus == 0 && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.wantStatus != 0 && (!errors.As(err, &e) || e.StatusCode != tt.wantStatus):
+			t.Errorf("%s: err = %v, want status %d", tt.name, err, tt.wantStatus)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	srv := &statusServer{statuses: []int{503}, header: http.Header{"Retry-After": {"1"}}}
+	c := setup(t, srv)
+
+	if _, err := c.Get(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	}
+	times := srv.requests()
+	if len(times) != 2 {
+		t.Fatalf("%d requests, want 2", len(times))
+	}
+	// The backoff of the client is a millisecond; the server asked for a
+	// second.
+	if wait := times[1].Sub(times[0]); wait < time.Second {
+		t.Errorf("retried after %s, want at least 1s", wait)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := New("http://localhost", WithRetry(Retry{Attempts: 5, Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}))
+	retryAfter := func(value string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": {value}}}
+	}
+	tests := []struct {
+		name     string
+		attempt  int
+		res      *http.Response
+		min, max time.Duration
+	}{
+		{"first retry", 1, nil, 75 * time.Millisecond, 100 * time.Millisecond},
+		{"doubles", 3, nil, 300 * time.Millisecond, 400 * time.Millisecond},
+		{"capped", 5, nil, 750 * time.Millisecond, time.Second},
+		{"retry after", 1, retryAfter("3"), 3 * time.Second, 3 * time.Second},
+		{"retry after above the cap", 1, retryAfter("30"), 30 * time.Second, 30 * time.Second},
+		{"retry after as a date", 1, retryAfter("Wed, 21 Oct 2026 07:28:00 GMT"), 75 * time.Millisecond, 100 * time.Millisecond},
+		{"retry after zero", 2, retryAfter("0"), 150 * time.Millisecond, 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := c.backoff(tt.attempt, tt.res); got < tt.min || got > tt.max {
+			t.Errorf("%s: backoff(%d) = %s, want %s to %s", tt.name, tt.attempt, got, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Errors to match with errors.Is against the *Error of a failed request.
+// They only stand for the status code, whatever the resource: ErrNotFound
+// may be an unknown book as well as an unknown author or cart, ErrConflict
+// a taken id as well as an empty cart. The *Error tells the details.
+var (
+	ErrInvalid  = errors.New("invalid request")
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflict")
+)
+
+// Error is a request the server answered with a 4xx or 5xx status, read
+// from the RFC 7807 problem details of the response.
+type Error struct {
+	StatusCode int
+	// Type is the problem type, e.g. "/problems/validation".
+	Type string
+	// Message is the detail of the problem, or its title if it has none.
+	Message string
+	// Fields tells what is wrong with each field of an invalid request.
+	Fields    []FieldError
+	RequestID string
+}
+
+// FieldError describes what is wrong with one field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("bookstore: %d %s", e.StatusCode, e.Message)
+	for i, f := range e.Fields {
+		if i == 0 {
+			msg += ":"
+		} else {
+			msg += ";"
+		}
+		if f.Field != "" {
+			msg += " " + f.Field
+		}
+		msg += " " + f.Message
+	}
+	return msg
+}
+
+// Is maps the status code onto ErrInvalid, ErrNotFound and ErrConflict.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrInvalid:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+// readError turns a failed response into an *Error and closes its body.
+func readError(res *http.Response) error {
+	defer res.Body.Close()
+
+	e := &Error{StatusCode: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+	var body struct {
+		Type      string       `json:"type"`
+		Title     string       `json:"title"`
+		Detail    string       `json:"detail"`
+		Errors    []FieldError `json:"errors"`
+		RequestID string       `json:"requestId"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if json.Unmarshal(data, &body) != nil {
+		// Not a problem, e.g. from a proxy in front of the API.
+		return e
+	}
+	e.Type = body.Type
+	e.Fields = body.Errors
+	e.RequestID = body.RequestID
+	if body.Detail != "" {
+		e.Message = body.Detail
+	} else if body.Title != "" {
+		e.Message = body.Title
+	}
+	return e
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		// want is the sentinel the error matches; the others must not.
+		want    error
+		wantErr Error
+	}{
+		{
+			name:   "validation",
+			status: http.StatusBadRequest,
+			body:   `{"type":"/problems/validation","title":"Invalid book","errors":[{"field":"title","message":"is required"}],"requestId":"r1"}`,
+			want:   ErrInvalid,
+			wantErr: Error{
+				StatusCode: 400, Type: "/problems/validation", Message: "Invalid book",
+				Fields: []FieldError{{Field: "title", Message: "is required"}}, RequestID: "r1",
+			},
+		},
+		{
+			name:    "not found",
+			status:  http.StatusNotFound,
+			body:    `{"type":"/problems/not-found","title":"Not Found","detail":"Book not found"}`,
+			want:    ErrNotFound,
+			wantErr: Error{StatusCode: 404, Type: "/problems/not-found", Message: "Book not found"},
+		},
+		{
+			name:    "conflict",
+			status:  http.StatusConflict,
+			body:    `{"title":"Conflict","detail":"A book with this ID already exists"}`,
+			want:    ErrConflict,
+			wantErr: Error{StatusCode: 409, Message: "A book with this ID already exists"},
+		},
+		{
+			name:    "not a problem",
+			status:  http.StatusNotFound,
+			body:    `<html>not found</html>`,
+			want:    ErrNotFound,
+			wantErr: Error{StatusCode: 404, Message: "Not Found"},
+		},
+		{
+			name:    "forbidden",
+			status:  http.StatusForbidden,
+			body:    `{"title":"Forbidden"}`,
+			wantErr: Error{StatusCode: 403, Message: "Forbidden"},
+		},
+	}
+	for _, tt := range tests {
+		c := setup(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+
+		_, err := c.Create(context.Background(), Book{ID: "1", Title: "Dune"})
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("%s: err = %v, want an *Error", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*e, tt.wantErr) {
+			t.Errorf("%s: err = %#v, want %#v", tt.name, *e, tt.wantErr)
+		}
+		for _, target := range []error{ErrInvalid, ErrNotFound, ErrConflict} {
+			if got, want := errors.Is(err, target), target == tt.want; got != want {
+				t.Errorf("%s: errors.Is(err, %v) = %v, want %v", tt.name, target, got, want)
+			}
+		}
+	}
+}
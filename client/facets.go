@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Count is a publisher or series with its number of books.
+type Count struct {
+	Name  string `json:"name"`
+	Books int    `json:"books"`
+}
+
+// Publishers returns every publisher with the number of their books,
+// ordered by name.
+func (c *Client) Publishers(ctx context.Context) ([]Count, error) {
+	var list []Count
+	if _, err := c.do(ctx, http.MethodGet, "/api/publishers", nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Series returns every series with the number of its books, ordered by
+// name.
+func (c *Client) Series(ctx context.Context) ([]Count, error) {
+	var list []Count
+	if _, err := c.do(ctx, http.MethodGet, "/api/series", nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
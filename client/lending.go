@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Statuses of copies. On hold copies are set aside for the borrower of a
+// ready hold.
+const (
+	CopyAvailable = "available"
+	CopyOnLoan    = "on_loan"
+	CopyOnHold    = "on_hold"
+)
+
+// Statuses of holds. Waiting holds queue for a copy, ready ones have one set
+// aside.
+const (
+	HoldWaiting   = "waiting"
+	HoldReady     = "ready"
+	HoldFulfilled = "fulfilled"
+	HoldCancelled = "cancelled"
+)
+
+// Copy is a physical copy of a book in the lending library. Borrower has
+// it on loan, or it is set aside for them.
+type Copy struct {
+	Barcode  string    `json:"barcode"`
+	BookID   string    `json:"bookId"`
+	Status   string    `json:"status"`
+	Borrower string    `json:"borrower"`
+	Added    time.Time `json:"added"`
+}
+
+// Loan is the lending of a copy to a borrower. Returned is nil for open
+// loans.
+type Loan struct {
+	ID       string     `json:"id"`
+	Barcode  string     `json:"barcode"`
+	BookID   string     `json:"bookId"`
+	Borrower string     `json:"borrower"`
+	Borrowed time.Time  `json:"borrowed"`
+	Due      time.Time  `json:"due"`
+	Returned *time.Time `json:"returned,omitempty"`
+	Overdue  bool       `json:"overdue"`
+}
+
+// Hold is the request of a borrower for the next copy of a book. Barcode is
+// the copy set aside for a ready hold.
+type Hold struct {
+	ID       string    `json:"id"`
+	BookID   string    `json:"bookId"`
+	Borrower string    `json:"borrower"`
+	Status   string    `json:"status"`
+	Barcode  string    `json:"barcode"`
+	Placed   time.Time `json:"placed"`
+	Updated  time.Time `json:"updated"`
+}
+
+func copiesPath(bookID string) string {
+	return "/api/books/" + url.PathEscape(bookID) + "/copies"
+}
+
+func holdsPath(bookID string) string {
+	return "/api/books/" + url.PathEscape(bookID) + "/holds"
+}
+
+func copyPath(barcode string) string {
+	return "/api/copies/" + url.PathEscape(barcode)
+}
+
+// Copies returns the lending copies of a book, ordered by barcode.
+func (c *Client) Copies(ctx context.Context, bookID string) ([]Copy, error) {
+	var list []Copy
+	if _, err := c.do(ctx, http.MethodGet, copiesPath(bookID), nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// AddCopy adds a copy of a book to the lending library. It is set aside for
+// the oldest waiting hold on the book, if any. The server answers 409 if
+// the barcode is taken.
+func (c *Client) AddCopy(ctx context.Context, bookID, barcode string) (*Copy, error) {
+	body := struct {
+		Barcode string `json:"barcode"`
+	}{barcode}
+	var copy Copy
+	if _, err := c.do(ctx, http.MethodPost, copiesPath(bookID), body, &copy); err != nil {
+		return nil, err
+	}
+	return &copy, nil
+}
+
+// Copy returns the copy with the given barcode.
+func (c *Client) Copy(ctx context.Context, barcode string) (*Copy, error) {
+	var copy Copy
+	if _, err := c.do(ctx, http.MethodGet, copyPath(barcode), nil, &copy); err != nil {
+		return nil, err
+	}
+	return &copy, nil
+}
+
+// RemoveCopy removes a copy from the lending library. The server answers
+// 409 for copies on loan.
+func (c *Client) RemoveCopy(ctx context.Context, barcode string) error {
+	_, err := c.do(ctx, http.MethodDelete, copyPath(barcode), nil, nil)
+	return err
+}
+
+// Lend lends a copy to borrower for days, or 21 days if days is 0. The
+// server answers 409 if the copy is on loan or set aside for somebody else.
+func (c *Client) Lend(ctx context.Context, barcode, borrower string, days int) (*Loan, error) {
+	body := struct {
+		Borrower string `json:"borrower"`
+		Days     int    `json:"days,omitempty"`
+	}{borrower, days}
+	var loan Loan
+	if _, err := c.do(ctx, http.MethodPost, copyPath(barcode)+"/checkout", body, &loan); err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// Return closes the loan of a copy and returns it with the copy, which is
+// set aside for the next hold on its book, if any.
+func (c *Client) Return(ctx context.Context, barcode string) (*Loan, *Copy, error) {
+	var res struct {
+		Loan Loan `json:"loan"`
+		Copy Copy `json:"copy"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, copyPath(barcode)+"/return", nil, &res); err != nil {
+		return nil, nil, err
+	}
+	return &res.Loan, &res.Copy, nil
+}
+
+// Loans returns the open loans of borrower, or every open loan if it is
+// empty, the earliest due first.
+func (c *Client) Loans(ctx context.Context, borrower string) ([]Loan, error) {
+	path := "/api/loans"
+	if borrower != "" {
+		path += "?borrower=" + url.QueryEscape(borrower)
+	}
+	var list []Loan
+	if _, err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Overdue returns the loans past their due time, the longest overdue first.
+func (c *Client) Overdue(ctx context.Context) ([]Loan, error) {
+	var list []Loan
+	if _, err := c.do(ctx, http.MethodGet, "/api/loans/overdue", nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Holds returns the open holds on a book, the ready ones first, then the
+// waiting ones, oldest first.
+func (c *Client) Holds(ctx context.Context, bookID string) ([]Hold, error) {
+	var list []Hold
+	if _, err := c.do(ctx, http.MethodGet, holdsPath(bookID), nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// PlaceHold places a hold on a book for borrower. It is ready right away if
+// a copy was available. The server answers 409 if the borrower already
+// holds the book.
+func (c *Client) PlaceHold(ctx context.Context, bookID, borrower string) (*Hold, error) {
+	body := struct {
+		Borrower string `json:"borrower"`
+	}{borrower}
+	var hold Hold
+	if _, err := c.do(ctx, http.MethodPost, holdsPath(bookID), body, &hold); err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// CancelHold cancels a hold. The copy set aside for a ready hold goes to
+// the next one.
+func (c *Client) CancelHold(ctx context.Context, id string) (*Hold, error) {
+	var hold Hold
+	if _, err := c.do(ctx, http.MethodDelete, "/api/holds/"+url.PathEscape(id), nil, &hold); err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
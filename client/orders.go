@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Statuses of orders. Pending orders are paid or cancelled, paid orders
+// shipped or cancelled.
+const (
+	StatusPending   = "pending"
+	StatusPaid      = "paid"
+	StatusShipped   = "shipped"
+	StatusCancelled = "cancelled"
+)
+
+// CartItem is a book in a cart and the number of copies of it, from 1 to
+// 100.
+type CartItem struct {
+	BookID   string `json:"bookId"`
+	Quantity int    `json:"quantity"`
+}
+
+// Cart holds the books about to be ordered. The server removes carts nobody
+// changed for 30 days.
+type Cart struct {
+	ID      string     `json:"id"`
+	Items   []CartItem `json:"items"`
+	Created time.Time  `json:"created"`
+	Updated time.Time  `json:"updated"`
+}
+
+// OrderLine is a book of an order with its prices at checkout, in the
+// currency of the order. Rule is the id of the price rule giving the
+// discount, if any.
+type OrderLine struct {
+	BookID    string `json:"bookId"`
+	Title     string `json:"title"`
+	Quantity  int    `json:"quantity"`
+	ListPrice string `json:"listPrice"`
+	UnitPrice string `json:"unitPrice"`
+	Rule      string `json:"rule,omitempty"`
+	Total     string `json:"total"`
+}
+
+// Order is a checked-out cart.
+type Order struct {
+	ID       string      `json:"id"`
+	CartID   string      `json:"cartId"`
+	Lines    []OrderLine `json:"lines"`
+	Currency string      `json:"currency"`
+	Total    string      `json:"total"`
+	Status   string      `json:"status"`
+	Created  time.Time   `json:"created"`
+	Updated  time.Time   `json:"updated"`
+}
+
+func cartPath(id string) string {
+	return "/api/carts/" + url.PathEscape(id)
+}
+
+func orderPath(id string) string {
+	return "/api/orders/" + url.PathEscape(id)
+}
+
+// CreateCart creates a cart holding items, which may be empty.
+func (c *Client) CreateCart(ctx context.Context, items []CartItem) (*Cart, error) {
+	body := struct {
+		Items []CartItem `json:"items,omitempty"`
+	}{items}
+	var cart Cart
+	if _, err := c.do(ctx, http.MethodPost, "/api/carts", body, &cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// Cart returns the cart with the given id.
+func (c *Client) Cart(ctx context.Context, id string) (*Cart, error) {
+	var cart Cart
+	if _, err := c.do(ctx, http.MethodGet, cartPath(id), nil, &cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// SetCartItem sets the number of copies of a book in a cart.
+func (c *Client) SetCartItem(ctx context.Context, cartID, bookID string, quantity int) (*Cart, error) {
+	body := struct {
+		Quantity int `json:"quantity"`
+	}{quantity}
+	var cart Cart
+	if _, err := c.do(ctx, http.MethodPut, cartPath(cartID)+"/items/"+url.PathEscape(bookID), body, &cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// RemoveCartItem takes a book out of a cart.
+func (c *Client) RemoveCartItem(ctx context.Context, cartID, bookID string) (*Cart, error) {
+	var cart Cart
+	if _, err := c.do(ctx, http.MethodDelete, cartPath(cartID)+"/items/"+url.PathEscape(bookID), nil, &cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// DeleteCart removes the cart with the given id.
+func (c *Client) DeleteCart(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, cartPath(id), nil, nil)
+	return err
+}
+
+// Checkout orders the books of a cart, priced in currency or, if it is
+// empty, in the base currency of the server, and removes the cart. The
+// server answers 409 if the cart is empty, changed during the checkout or a
+// book has too few copies in stock; nothing is reserved then.
+func (c *Client) Checkout(ctx context.Context, cartID, currency string) (*Order, error) {
+	body := struct {
+		Currency string `json:"currency,omitempty"`
+	}{currency}
+	var order Order
+	if _, err := c.do(ctx, http.MethodPost, cartPath(cartID)+"/checkout", body, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// Orders returns the orders with the given status, or every order if it is
+// empty, newest first.
+func (c *Client) Orders(ctx context.Context, status string) ([]Order, error) {
+	path := "/api/orders"
+	if status != "" {
+		path += "?status=" + url.QueryEscape(status)
+	}
+	var list []Order
+	if _, err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Order returns the order with the given id.
+func (c *Client) Order(ctx context.Context, id string) (*Order, error) {
+	var order Order
+	if _, err := c.do(ctx, http.MethodGet, orderPath(id), nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// SetOrderStatus moves an order to status. The server answers 409 for
+// changes the order does not allow.
+func (c *Client) SetOrderStatus(ctx context.Context, id, status string) (*Order, error) {
+	body := struct {
+		Status string `json:"status"`
+	}{status}
+	var order Order
+	if _, err := c.do(ctx, http.MethodPut, orderPath(id)+"/status", body, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Price is the price of a book after the best discount of the price rules
+// matching it. Rule is the id of that rule, empty without a discount.
+type Price struct {
+	Amount    string `json:"amount"`
+	Currency  string `json:"currency"`
+	ListPrice string `json:"listPrice"`
+	Rule      string `json:"rule,omitempty"`
+}
+
+// Kinds of price rules.
+const (
+	KindPercent = "percent"
+	KindFixed   = "fixed"
+)
+
+// PriceRule is a discount on the books matching all of Tag, Author and the
+// days From to Until; empty conditions match every book. Value is a
+// percentage, or an amount in Currency off the list price.
+type PriceRule struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	Author   string `json:"author,omitempty"`
+	// From and Until are dates like 2025-12-31, both included.
+	From  string `json:"from,omitempty"`
+	Until string `json:"until,omitempty"`
+}
+
+// Rates are the exchange rates prices are converted with: the units of each
+// currency one unit of Base buys.
+type Rates struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+func priceRulePath(id string) string {
+	return "/api/pricing/rules/" + url.PathEscape(id)
+}
+
+// Rates returns the exchange rates of the server.
+func (c *Client) Rates(ctx context.Context) (*Rates, error) {
+	var rates Rates
+	if _, err := c.do(ctx, http.MethodGet, "/api/pricing/rates", nil, &rates); err != nil {
+		return nil, err
+	}
+	return &rates, nil
+}
+
+// PriceRules returns every price rule, ordered by name.
+func (c *Client) PriceRules(ctx context.Context) ([]PriceRule, error) {
+	var list []PriceRule
+	if _, err := c.do(ctx, http.MethodGet, "/api/pricing/rules", nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// PriceRule returns the price rule with the given id.
+func (c *Client) PriceRule(ctx context.Context, id string) (*PriceRule, error) {
+	var rule PriceRule
+	if _, err := c.do(ctx, http.MethodGet, priceRulePath(id), nil, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreatePriceRule adds a price rule and returns it with its id.
+func (c *Client) CreatePriceRule(ctx context.Context, rule PriceRule) (*PriceRule, error) {
+	var created PriceRule
+	if _, err := c.do(ctx, http.MethodPost, "/api/pricing/rules", rule, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdatePriceRule changes the non-empty fields of patch on the price rule
+// with the given id. Conditions cannot be cleared this way; delete the rule
+// and create it again without them.
+func (c *Client) UpdatePriceRule(ctx context.Context, id string, patch PriceRule) (*PriceRule, error) {
+	var updated PriceRule
+	if _, err := c.do(ctx, http.MethodPut, priceRulePath(id), patch, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeletePriceRule removes the price rule with the given id.
+func (c *Client) DeletePriceRule(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, priceRulePath(id), nil, nil)
+	return err
+}
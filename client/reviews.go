@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Review is a customer's rating of a book, from 1 to 5, with an optional
+// text. The server sets the id, the book id and the times.
+type Review struct {
+	ID       string    `json:"id,omitempty"`
+	BookID   string    `json:"bookId,omitempty"`
+	Reviewer string    `json:"reviewer,omitempty"`
+	Rating   int       `json:"rating,omitempty"`
+	Text     string    `json:"text,omitempty"`
+	Created  time.Time `json:"created,omitempty"`
+	Updated  time.Time `json:"updated,omitempty"`
+}
+
+// reviewBody is what the server accepts of a review.
+type reviewBody struct {
+	Reviewer string `json:"reviewer,omitempty"`
+	Rating   int    `json:"rating,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+func reviewsPath(bookID string) string {
+	return "/api/books/" + url.PathEscape(bookID) + "/reviews"
+}
+
+// ListReviews returns the reviews of a book, newest first.
+func (c *Client) ListReviews(ctx context.Context, bookID string) ([]Review, error) {
+	var list []Review
+	if _, err := c.do(ctx, http.MethodGet, reviewsPath(bookID), nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CreateReview adds a review to a book and returns it as stored.
+func (c *Client) CreateReview(ctx context.Context, bookID string, review Review) (*Review, error) {
+	var created Review
+	body := reviewBody{review.Reviewer, review.Rating, review.Text}
+	if _, err := c.do(ctx, http.MethodPost, reviewsPath(bookID), body, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateReview changes the non-empty fields of patch on a review of a book,
+// and returns the review as updated.
+func (c *Client) UpdateReview(ctx context.Context, bookID, id string, patch Review) (*Review, error) {
+	var review Review
+	body := reviewBody{patch.Reviewer, patch.Rating, patch.Text}
+	if _, err := c.do(ctx, http.MethodPut, reviewsPath(bookID)+"/"+url.PathEscape(id), body, &review); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// DeleteReview removes a review of a book.
+func (c *Client) DeleteReview(ctx context.Context, bookID, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, reviewsPath(bookID)+"/"+url.PathEscape(id), nil, nil)
+	return err
+}
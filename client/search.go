@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Query selects books for Search. Empty fields match every book.
+type Query struct {
+	// Text is part of the title or author, ignoring case.
+	Text  string
+	Genre string
+	// Author is the name of any contributor.
+	Author string
+	// Decade is the first year of a decade, like "1810".
+	Decade string
+	Tag    string
+}
+
+// Facets counts the books found by Search per genre, author and decade.
+type Facets struct {
+	Genre  []Count `json:"genre"`
+	Author []Count `json:"author"`
+	Decade []Count `json:"decade"`
+}
+
+// SearchResult is the answer of Search.
+type SearchResult struct {
+	Books  []Book `json:"books"`
+	Facets Facets `json:"facets"`
+}
+
+// Search returns the books matching q, ordered by title, and their facets.
+func (c *Client) Search(ctx context.Context, q Query) (*SearchResult, error) {
+	params := url.Values{}
+	for name, value := range map[string]string{
+		"q": q.Text, "genre": q.Genre, "author": q.Author, "decade": q.Decade, "tag": q.Tag,
+	} {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
+
+	var result SearchResult
+	if _, err := c.do(ctx, http.MethodGet, "/api/search?"+params.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Genres returns the genres books can be filed under.
+func (c *Client) Genres(ctx context.Context) ([]string, error) {
+	var list []string
+	if _, err := c.do(ctx, http.MethodGet, "/api/genres", nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// AddTags adds tags to the book with the given id, keeping the tags it
+// already has, and returns the book.
+func (c *Client) AddTags(ctx context.Context, id string, tags ...string) (*Book, error) {
+	var book Book
+	body := map[string][]string{"tags": tags}
+	if _, err := c.do(ctx, http.MethodPost, "/api/books/"+url.PathEscape(id)+"/tags", body, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// RemoveTag removes a tag from the book with the given id and returns the
+// book.
+func (c *Client) RemoveTag(ctx context.Context, id, tag string) (*Book, error) {
+	var book Book
+	path := "/api/books/" + url.PathEscape(id) + "/tags/" + url.PathEscape(tag)
+	if _, err := c.do(ctx, http.MethodDelete, path, nil, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// StockMovement is a change of the stock of a book with its reason, and the
+// stock after it.
+type StockMovement struct {
+	BookID string    `json:"bookId"`
+	Delta  int       `json:"delta"`
+	Reason string    `json:"reason"`
+	Stock  int       `json:"stock"`
+	Time   time.Time `json:"time"`
+}
+
+// Stock is the stock of a book with its movements, newest first.
+type Stock struct {
+	Stock        int             `json:"stock"`
+	ReorderLevel int             `json:"reorderLevel"`
+	Movements    []StockMovement `json:"movements"`
+}
+
+type stockBody struct {
+	Delta  int    `json:"delta"`
+	Reason string `json:"reason"`
+}
+
+func stockPath(bookID string) string {
+	return "/api/books/" + url.PathEscape(bookID) + "/stock"
+}
+
+// Stock returns the stock of a book.
+func (c *Client) Stock(ctx context.Context, bookID string) (*Stock, error) {
+	var stock Stock
+	if _, err := c.do(ctx, http.MethodGet, stockPath(bookID), nil, &stock); err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
+
+// AdjustStock adds delta to the stock of a book. The server answers 409 if
+// there are fewer than -delta copies left.
+func (c *Client) AdjustStock(ctx context.Context, bookID string, delta int, reason string) (*StockMovement, error) {
+	var movement StockMovement
+	if _, err := c.do(ctx, http.MethodPost, stockPath(bookID), stockBody{delta, reason}, &movement); err != nil {
+		return nil, err
+	}
+	return &movement, nil
+}
+
+// LowStock returns the books at or below their reorder level, the lowest
+// stock first.
+func (c *Client) LowStock(ctx context.Context) ([]Book, error) {
+	var list []Book
+	if _, err := c.do(ctx, http.MethodGet, "/api/stock/low", nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
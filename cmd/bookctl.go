@@ -0,0 +1,1138 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/client"
+	"gopkg.in/yaml.v3"
+)
+
+// bookctl manages the catalog of any deployment of the book store over its
+// REST API, e.g.
+//
+//	go run cmd/bookctl.go books list
+//	go run cmd/bookctl.go -d prod books get 42
+//	go run cmd/bookctl.go books create -id 42 -title Dune -author "Frank Herbert"
+//	go run cmd/bookctl.go books export > catalog.ndjson
+//	go run cmd/bookctl.go -url http://localhost:3030 books import < catalog.ndjson
+//
+// Deployments are read from a YAML config file, by default
+// $XDG_CONFIG_HOME/bookctl/config.yaml:
+//
+//	current: local
+//	deployments:
+//	  local:
+//	    url: http://localhost:3030
+//	  prod:
+//	    url: https://books.example.com
+//	    token: s3cr3t
+//
+// BOOKCTL_URL and BOOKCTL_TOKEN override the deployment, the -url and
+// -token flags override both.
+
+const usage = `usage: bookctl [flags] <command>
+
+commands:
+  books list [-isbn isbn] [-sort rating]
+                              list all books, or those with an ISBN
+  books search [flags]        list the books matching -q, -genre, -author,
+                              -decade and -tag
+  books get <id> [-currency c]
+                              get a single book, its effective price in c
+  books create [book flags]   create a book, from flags or a JSON book on stdin
+  books update <id> [flags]   update the non-empty fields of a book
+  books delete <id>...        delete books
+  books tag <id> <tag>...     add tags to a book
+  books untag <id> <tag>...   remove tags from a book
+  books reviews <id>          list the reviews of a book
+  books review <id> -reviewer name -rating 1-5 [-text text]
+                              review a book
+  books stock <id> [-delta n -reason reason]
+                              print the stock of a book and its movements,
+                              or adjust it by n copies
+  books copies <id> [-add barcode]
+                              list the lending copies of a book, or add one
+  books holds <id> [-borrower name]
+                              list the holds on a book, or place one
+  books import [file]         create the books of an NDJSON file or stdin,
+                              skipping those whose id is taken
+  books export                print every book as NDJSON
+  authors                     list the authors, ordered by sort name, and
+                              their number of books
+  publishers                  list the publishers and their number of books
+  series                      list the series and their number of books
+  genres                      list the genres books can be filed under
+  low-stock                   list the books at or below their reorder level
+  price-rules                 list the discount rules of the effective prices
+  price-rule create -name name -kind percent|fixed -value n [-currency c]
+             [-tag tag] [-author name] [-from date] [-until date]
+                              add a discount rule
+  price-rule delete <id>...   delete discount rules
+  rates                       print the exchange rates
+  cart create [book[=n]]...   create a cart with n copies of each book, 1 if
+                              n is left out
+  cart get <id>               print the books of a cart
+  cart set <id> <book> <n>    set the copies of a book in a cart, 0 removes it
+  cart checkout <id> [-currency c]
+                              order the books of a cart
+  orders [-status status]     list the orders, newest first
+  order get <id>              print the books of an order
+  order status <id> <status>  move an order to paid, shipped or cancelled
+  copy get <barcode>          print a lending copy
+  copy remove <barcode>...    remove lending copies
+  lend <barcode> -borrower name [-days n]
+                              lend a copy, for 21 days by default
+  return <barcode>            return a copy
+  hold cancel <id>            cancel a hold
+  loans [-borrower name] [-overdue]
+                              list the open loans, the earliest due first
+  stats                       print figures about the catalog
+
+book flags:
+  -id, -title, -author, -edition, -pages, -year, -isbn, -publisher,
+  -series, -volume, -tags, -genres (both separated by commas),
+  -reorder-level, -list-price, -currency
+
+flags:
+`
+
+const (
+	outputTable  = "table"
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+)
+
+type bookctlConfig struct {
+	Current     string                       `yaml:"current"`
+	Deployments map[string]bookctlDeployment `yaml:"deployments"`
+}
+
+type bookctlDeployment struct {
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"`
+}
+
+func main() {
+	configPath := flag.String("config", defaultConfigPath(), "path of the config file")
+	deployment := flag.String("d", "", "deployment of the config file to use, instead of its current one")
+	url := flag.String("url", "", "base URL of the API, e.g. http://localhost:3030")
+	token := flag.String("token", "", "bearer token sent with every request")
+	output := flag.String("o", outputTable, "output format: table, json or ndjson")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of the whole command")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	switch *output {
+	case outputTable, outputJSON, outputNDJSON:
+	default:
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", *output)
+		os.Exit(2)
+	}
+
+	target, err := resolveDeployment(*configPath, *deployment)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if v := os.Getenv("BOOKCTL_URL"); v != "" {
+		target.URL = v
+	}
+	if v := os.Getenv("BOOKCTL_TOKEN"); v != "" {
+		target.Token = v
+	}
+	if *url != "" {
+		target.URL = *url
+	}
+	if *token != "" {
+		target.Token = *token
+	}
+
+	opts := []client.Option{client.WithUserAgent("bookctl/1")}
+	if target.Token != "" {
+		opts = append(opts, client.WithToken(target.Token))
+	}
+	c := client.New(target.URL, opts...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if err := runBookctl(ctx, c, *output, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func defaultConfigPath() string {
+	if v := os.Getenv("BOOKCTL_CONFIG"); v != "" {
+		return v
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bookctl", "config.yaml")
+}
+
+// resolveDeployment picks a deployment of the config file. A missing config
+// file is fine as long as no deployment is asked for, the API is then
+// expected on localhost.
+func resolveDeployment(path, name string) (bookctlDeployment, error) {
+	fallback := bookctlDeployment{URL: "http://localhost:3030"}
+
+	info, err := os.Stat(path)
+	if path == "" || errors.Is(err, os.ErrNotExist) {
+		if name != "" {
+			return fallback, fmt.Errorf("deployment %q not found, there is no config file", name)
+		}
+		return fallback, nil
+	}
+	if err != nil {
+		return fallback, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fallback, err
+	}
+	var config bookctlConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fallback, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	if name == "" {
+		name = config.Current
+	}
+	if name == "" {
+		return fallback, nil
+	}
+	target, ok := config.Deployments[name]
+	if !ok {
+		return fallback, fmt.Errorf("deployment %q not found in %s", name, path)
+	}
+	if target.Token != "" && info.Mode().Perm()&0o077 != 0 {
+		fmt.Fprintf(os.Stderr, "warning: %s holds a token but can be read by other users, consider chmod 600\n", path)
+	}
+	if target.URL == "" {
+		target.URL = fallback.URL
+	}
+	return target, nil
+}
+
+func runBookctl(ctx context.Context, c *client.Client, output string, args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	switch {
+	case args[0] == "books" && len(args) >= 2:
+		return runBooks(ctx, c, output, args[1], args[2:])
+
+	case args[0] == "authors" && len(args) == 1:
+		authors, err := c.ListAuthors(ctx)
+		if err != nil {
+			return err
+		}
+		list, err := c.List(ctx)
+		if err != nil {
+			return err
+		}
+		return printAuthors(output, authors, list)
+
+	case args[0] == "publishers" && len(args) == 1:
+		list, err := c.Publishers(ctx)
+		if err != nil {
+			return err
+		}
+		return printCounts(output, "PUBLISHER", list)
+
+	case args[0] == "series" && len(args) == 1:
+		list, err := c.Series(ctx)
+		if err != nil {
+			return err
+		}
+		return printCounts(output, "SERIES", list)
+
+	case args[0] == "genres" && len(args) == 1:
+		list, err := c.Genres(ctx)
+		if err != nil {
+			return err
+		}
+		return printStrings(output, "GENRE", list)
+
+	case args[0] == "low-stock" && len(args) == 1:
+		list, err := c.LowStock(ctx)
+		if err != nil {
+			return err
+		}
+		return printBooks(output, list)
+
+	case args[0] == "price-rules" && len(args) == 1:
+		list, err := c.PriceRules(ctx)
+		if err != nil {
+			return err
+		}
+		return printPriceRules(output, list)
+
+	case args[0] == "price-rule" && len(args) >= 2 && args[1] == "create":
+		var rule client.PriceRule
+		fs := flag.NewFlagSet("price-rule create", flag.ExitOnError)
+		fs.StringVar(&rule.Name, "name", "", "name of the rule")
+		fs.StringVar(&rule.Kind, "kind", client.KindPercent, "percent or fixed")
+		fs.StringVar(&rule.Value, "value", "", "percentage, or amount off the list price")
+		fs.StringVar(&rule.Currency, "currency", "", "currency of a fixed amount")
+		fs.StringVar(&rule.Tag, "tag", "", "only books with this tag")
+		fs.StringVar(&rule.Author, "author", "", "only books by this author")
+		fs.StringVar(&rule.From, "from", "", "first day, like 2025-07-01")
+		fs.StringVar(&rule.Until, "until", "", "last day, like 2025-08-31")
+		if err := fs.Parse(args[2:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		created, err := c.CreatePriceRule(ctx, rule)
+		if err != nil {
+			return err
+		}
+		return printPriceRules(output, []client.PriceRule{*created})
+
+	case args[0] == "price-rule" && len(args) >= 3 && args[1] == "delete":
+		for _, id := range args[2:] {
+			if err := c.DeletePriceRule(ctx, id); err != nil {
+				return fmt.Errorf("price rule %s: %w", id, err)
+			}
+		}
+		return nil
+
+	case args[0] == "rates" && len(args) == 1:
+		rates, err := c.Rates(ctx)
+		if err != nil {
+			return err
+		}
+		if output == outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(rates)
+		}
+		currencies := make([]string, 0, len(rates.Rates))
+		for currency := range rates.Rates {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+		return printRows(output, currencies, []string{"CURRENCY", "PER " + rates.Base}, func(currency string) []string {
+			return []string{currency, strconv.FormatFloat(rates.Rates[currency], 'f', -1, 64)}
+		})
+
+	case args[0] == "cart" && len(args) >= 2:
+		return runCart(ctx, c, output, args[1], args[2:])
+
+	case args[0] == "orders" && len(args) >= 1:
+		fs := flag.NewFlagSet("orders", flag.ExitOnError)
+		status := fs.String("status", "", "only the orders with this status")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		list, err := c.Orders(ctx, *status)
+		if err != nil {
+			return err
+		}
+		return printOrders(output, list)
+
+	case args[0] == "order" && len(args) == 3 && args[1] == "get":
+		order, err := c.Order(ctx, args[2])
+		if err != nil {
+			return err
+		}
+		return printOrder(output, order)
+
+	case args[0] == "order" && len(args) == 4 && args[1] == "status":
+		order, err := c.SetOrderStatus(ctx, args[2], args[3])
+		if err != nil {
+			return err
+		}
+		return printOrders(output, []client.Order{*order})
+
+	case args[0] == "copy" && len(args) == 3 && args[1] == "get":
+		copy, err := c.Copy(ctx, args[2])
+		if err != nil {
+			return err
+		}
+		return printCopies(output, []client.Copy{*copy})
+
+	case args[0] == "copy" && len(args) >= 3 && args[1] == "remove":
+		for _, barcode := range args[2:] {
+			if err := c.RemoveCopy(ctx, barcode); err != nil {
+				return fmt.Errorf("copy %s: %w", barcode, err)
+			}
+		}
+		return nil
+
+	case args[0] == "lend" && len(args) >= 2:
+		fs := flag.NewFlagSet("lend", flag.ExitOnError)
+		borrower := fs.String("borrower", "", "borrower of the copy")
+		days := fs.Int("days", 0, "loan period in days, 21 by default")
+		if err := fs.Parse(args[2:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		loan, err := c.Lend(ctx, args[1], *borrower, *days)
+		if err != nil {
+			return err
+		}
+		return printLoans(output, []client.Loan{*loan})
+
+	case args[0] == "return" && len(args) == 2:
+		loan, copy, err := c.Return(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		if output == outputTable && copy.Status == client.CopyOnHold {
+			fmt.Printf("set aside for %s\n", copy.Borrower)
+		}
+		return printLoans(output, []client.Loan{*loan})
+
+	case args[0] == "hold" && len(args) == 3 && args[1] == "cancel":
+		hold, err := c.CancelHold(ctx, args[2])
+		if err != nil {
+			return err
+		}
+		return printHolds(output, []client.Hold{*hold})
+
+	case args[0] == "loans" && len(args) >= 1:
+		fs := flag.NewFlagSet("loans", flag.ExitOnError)
+		borrower := fs.String("borrower", "", "only the loans of this borrower")
+		overdue := fs.Bool("overdue", false, "only the loans past their due time")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		var list []client.Loan
+		var err error
+		if *overdue {
+			list, err = c.Overdue(ctx)
+			if *borrower != "" {
+				list = slices.DeleteFunc(list, func(l client.Loan) bool { return l.Borrower != *borrower })
+			}
+		} else {
+			list, err = c.Loans(ctx, *borrower)
+		}
+		if err != nil {
+			return err
+		}
+		return printLoans(output, list)
+
+	case args[0] == "stats" && len(args) == 1:
+		list, err := c.List(ctx)
+		if err != nil {
+			return err
+		}
+		return printStats(output, list)
+	}
+
+	flag.Usage()
+	os.Exit(2)
+	return nil
+}
+
+func runBooks(ctx context.Context, c *client.Client, output, command string, args []string) error {
+	switch {
+	case command == "list":
+		fs := flag.NewFlagSet("list", flag.ExitOnError)
+		isbn := fs.String("isbn", "", "only the books with this ISBN-10 or ISBN-13")
+		order := fs.String("sort", "", "rating to list the best rated books first")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		var list []client.Book
+		var err error
+		switch {
+		case *isbn != "":
+			list, err = c.FindByISBN(ctx, *isbn)
+		case *order == "rating":
+			list, err = c.ListByRating(ctx)
+		case *order != "":
+			return fmt.Errorf("unknown sort order %q", *order)
+		default:
+			list, err = c.List(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		return printBooks(output, list)
+
+	case command == "search":
+		var q client.Query
+		fs := flag.NewFlagSet("search", flag.ExitOnError)
+		fs.StringVar(&q.Text, "q", "", "part of the title or author")
+		fs.StringVar(&q.Genre, "genre", "", "genre")
+		fs.StringVar(&q.Author, "author", "", "name of any contributor")
+		fs.StringVar(&q.Decade, "decade", "", "first year of a decade, like 1810")
+		fs.StringVar(&q.Tag, "tag", "", "tag")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		result, err := c.Search(ctx, q)
+		if err != nil {
+			return err
+		}
+		return printBooks(output, result.Books)
+
+	case command == "get" && len(args) >= 1:
+		fs := flag.NewFlagSet("get", flag.ExitOnError)
+		currency := fs.String("currency", "", "currency of the effective price")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		get := c.Get
+		if *currency != "" {
+			get = func(ctx context.Context, id string) (*client.Book, error) {
+				return c.GetIn(ctx, id, *currency)
+			}
+		}
+		book, err := get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return printBooks(output, []client.Book{*book})
+
+	case command == "create":
+		book, err := bookFromArgs("create", args)
+		if err != nil {
+			return err
+		}
+		created, err := c.Create(ctx, book)
+		if err != nil {
+			return err
+		}
+		return printBooks(output, []client.Book{*created})
+
+	case command == "update" && len(args) >= 1:
+		patch, err := bookFromArgs("update", args[1:])
+		if err != nil {
+			return err
+		}
+		if err := c.Update(ctx, args[0], patch); err != nil {
+			return err
+		}
+		book, err := c.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return printBooks(output, []client.Book{*book})
+
+	case command == "delete" && len(args) >= 1:
+		for _, id := range args {
+			if err := c.Delete(ctx, id); err != nil {
+				return fmt.Errorf("%s: %w", id, err)
+			}
+		}
+		return nil
+
+	case command == "tag" && len(args) >= 2:
+		book, err := c.AddTags(ctx, args[0], args[1:]...)
+		if err != nil {
+			return err
+		}
+		return printBooks(output, []client.Book{*book})
+
+	case command == "untag" && len(args) >= 2:
+		var book *client.Book
+		for _, tag := range args[1:] {
+			var err error
+			if book, err = c.RemoveTag(ctx, args[0], tag); err != nil {
+				return fmt.Errorf("%s: %w", tag, err)
+			}
+		}
+		return printBooks(output, []client.Book{*book})
+
+	case command == "reviews" && len(args) == 1:
+		list, err := c.ListReviews(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return printReviews(output, list)
+
+	case command == "review" && len(args) >= 1:
+		var review client.Review
+		fs := flag.NewFlagSet("review", flag.ExitOnError)
+		fs.StringVar(&review.Reviewer, "reviewer", "", "name of the reviewer")
+		fs.IntVar(&review.Rating, "rating", 0, "rating from 1 to 5")
+		fs.StringVar(&review.Text, "text", "", "text of the review")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		created, err := c.CreateReview(ctx, args[0], review)
+		if err != nil {
+			return err
+		}
+		return printReviews(output, []client.Review{*created})
+
+	case command == "stock" && len(args) >= 1:
+		fs := flag.NewFlagSet("stock", flag.ExitOnError)
+		delta := fs.Int("delta", 0, "copies to add, negative to take out")
+		reason := fs.String("reason", "", "reason of the adjustment")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		if fs.NFlag() > 0 {
+			movement, err := c.AdjustStock(ctx, args[0], *delta, *reason)
+			if err != nil {
+				return err
+			}
+			return printMovements(output, []client.StockMovement{*movement})
+		}
+		stock, err := c.Stock(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if output == outputTable {
+			fmt.Printf("stock %d, reorder level %d\n", stock.Stock, stock.ReorderLevel)
+		}
+		return printMovements(output, stock.Movements)
+
+	case command == "copies" && len(args) >= 1:
+		fs := flag.NewFlagSet("copies", flag.ExitOnError)
+		barcode := fs.String("add", "", "barcode of a copy to add")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		if fs.NFlag() > 0 {
+			copy, err := c.AddCopy(ctx, args[0], *barcode)
+			if err != nil {
+				return err
+			}
+			return printCopies(output, []client.Copy{*copy})
+		}
+		list, err := c.Copies(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return printCopies(output, list)
+
+	case command == "holds" && len(args) >= 1:
+		fs := flag.NewFlagSet("holds", flag.ExitOnError)
+		borrower := fs.String("borrower", "", "borrower to place a hold for")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		if fs.NFlag() > 0 {
+			hold, err := c.PlaceHold(ctx, args[0], *borrower)
+			if err != nil {
+				return err
+			}
+			return printHolds(output, []client.Hold{*hold})
+		}
+		list, err := c.Holds(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return printHolds(output, list)
+
+	case command == "import" && len(args) <= 1:
+		in := io.Reader(os.Stdin)
+		if len(args) == 1 && args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		list, err := readBooks(in)
+		if err != nil {
+			return err
+		}
+		result, err := c.Import(ctx, list)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%d created, %d already existing, %d failed\n",
+			len(result.Created), len(result.Existing), len(result.Failed))
+		if len(result.Failed) > 0 {
+			ids := make([]string, 0, len(result.Failed))
+			for id := range result.Failed {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			for _, id := range ids {
+				fmt.Fprintf(os.Stderr, "  %s: %v\n", id, result.Failed[id])
+			}
+			return errors.New("import incomplete")
+		}
+		return nil
+
+	case command == "export" && len(args) == 0:
+		// Always NDJSON, so that an export can be imported again.
+		out := bufio.NewWriter(os.Stdout)
+		defer out.Flush()
+		enc := json.NewEncoder(out)
+		it := c.Books(client.MaxPageSize)
+		for it.Next(ctx) {
+			if err := enc.Encode(it.Book()); err != nil {
+				return err
+			}
+		}
+		return it.Err()
+	}
+
+	flag.Usage()
+	os.Exit(2)
+	return nil
+}
+
+func runCart(ctx context.Context, c *client.Client, output, command string, args []string) error {
+	switch {
+	case command == "create":
+		var items []client.CartItem
+		for _, arg := range args {
+			id, n, hasN := strings.Cut(arg, "=")
+			item := client.CartItem{BookID: id, Quantity: 1}
+			if hasN {
+				var err error
+				if item.Quantity, err = strconv.Atoi(n); err != nil {
+					return fmt.Errorf("invalid quantity of book %s: %s", id, n)
+				}
+			}
+			items = append(items, item)
+		}
+		cart, err := c.CreateCart(ctx, items)
+		if err != nil {
+			return err
+		}
+		return printCart(output, cart)
+
+	case command == "get" && len(args) == 1:
+		cart, err := c.Cart(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return printCart(output, cart)
+
+	case command == "set" && len(args) == 3:
+		n, err := strconv.Atoi(args[2])
+		if err != nil {
+			return fmt.Errorf("invalid quantity: %s", args[2])
+		}
+		var cart *client.Cart
+		if n == 0 {
+			cart, err = c.RemoveCartItem(ctx, args[0], args[1])
+		} else {
+			cart, err = c.SetCartItem(ctx, args[0], args[1], n)
+		}
+		if err != nil {
+			return err
+		}
+		return printCart(output, cart)
+
+	case command == "checkout" && len(args) >= 1:
+		fs := flag.NewFlagSet("checkout", flag.ExitOnError)
+		currency := fs.String("currency", "", "currency of the prices, the base currency by default")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		order, err := c.Checkout(ctx, args[0], *currency)
+		if err != nil {
+			return err
+		}
+		return printOrder(output, order)
+	}
+
+	flag.Usage()
+	os.Exit(2)
+	return nil
+}
+
+// bookFromArgs reads a book from the book flags, or from a JSON book on
+// stdin when no flag is given and stdin is not a terminal.
+func bookFromArgs(command string, args []string) (client.Book, error) {
+	var book client.Book
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	fs.StringVar(&book.ID, "id", "", "id of the book, not the MongoID")
+	fs.StringVar(&book.Title, "title", "", "title")
+	fs.StringVar(&book.Author, "author", "", "author")
+	fs.StringVar(&book.Edition, "edition", "", "edition")
+	fs.StringVar(&book.Pages, "pages", "", "number of pages")
+	fs.StringVar(&book.Year, "year", "", "year of publication")
+	fs.StringVar(&book.ISBN, "isbn", "", "ISBN-10 or ISBN-13")
+	fs.StringVar(&book.Publisher, "publisher", "", "publisher")
+	fs.StringVar(&book.Series, "series", "", "name of the series")
+	fs.StringVar(&book.Volume, "volume", "", "number of the book within its series")
+	fs.Func("tags", "tags, separated by commas", func(v string) error {
+		book.Tags = splitList(v)
+		return nil
+	})
+	fs.Func("genres", "genres, separated by commas", func(v string) error {
+		book.Genres = splitList(v)
+		return nil
+	})
+	fs.IntVar(&book.ReorderLevel, "reorder-level", 0, "stock at which the book is low on stock")
+	fs.StringVar(&book.ListPrice, "list-price", "", "list price, like 12.99")
+	fs.StringVar(&book.Currency, "currency", "", "currency of the list price, like EUR")
+	if err := fs.Parse(args); err != nil {
+		return book, err
+	}
+	if fs.NArg() > 0 {
+		return book, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	if fs.NFlag() == 0 && !isTerminal(os.Stdin) {
+		list, err := readBooks(os.Stdin)
+		if err != nil {
+			return book, err
+		}
+		if len(list) != 1 {
+			return book, fmt.Errorf("expected one book on stdin, got %d", len(list))
+		}
+		return list[0], nil
+	}
+	return book, nil
+}
+
+// splitList splits a flag value like "gothic, monsters" at its commas.
+func splitList(v string) []string {
+	var ret []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// readBooks reads NDJSON, one book per line, or a JSON array of books.
+func readBooks(in io.Reader) ([]client.Book, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []client.Book
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &list); err != nil {
+			return nil, fmt.Errorf("invalid books: %w", err)
+		}
+		return list, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	for {
+		var book client.Book
+		err := dec.Decode(&book)
+		if err == io.EOF {
+			return list, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid book #%d: %w", len(list)+1, err)
+		}
+		list = append(list, book)
+	}
+}
+
+// printRows prints values as JSON, NDJSON or as a table with the given
+// header and one row per value.
+func printRows[T any](output string, values []T, header []string, row func(T) []string) error {
+	switch output {
+	case outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if values == nil {
+			values = []T{}
+		}
+		return enc.Encode(values)
+	case outputNDJSON:
+		enc := json.NewEncoder(os.Stdout)
+		for _, v := range values {
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, v := range values {
+		fmt.Fprintln(w, strings.Join(row(v), "\t"))
+	}
+	return w.Flush()
+}
+
+func printBooks(output string, list []client.Book) error {
+	return printRows(output, list, []string{"ID", "TITLE", "AUTHOR", "EDITION", "PAGES", "YEAR", "ISBN", "GENRES", "TAGS", "RATING", "STOCK", "AVAIL", "PRICE"},
+		func(b client.Book) []string {
+			var rating string
+			if b.ReviewCount > 0 {
+				rating = fmt.Sprintf("%.1f (%d)", b.Rating, b.ReviewCount)
+			}
+			var available string
+			if b.Copies > 0 {
+				available = fmt.Sprintf("%d/%d", b.Available, b.Copies)
+			}
+			var price string
+			if p := b.EffectivePrice; p != nil {
+				price = p.Amount + " " + p.Currency
+			}
+			return []string{b.ID, b.Title, b.Author, b.Edition, b.Pages, b.Year, b.ISBN,
+				strings.Join(b.Genres, ","), strings.Join(b.Tags, ","), rating, strconv.Itoa(b.Stock), available, price}
+		})
+}
+
+func printCart(output string, cart *client.Cart) error {
+	if output == outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cart)
+	}
+	if output == outputTable {
+		fmt.Printf("cart %s\n", cart.ID)
+	}
+	return printRows(output, cart.Items, []string{"BOOK", "QUANTITY"}, func(item client.CartItem) []string {
+		return []string{item.BookID, strconv.Itoa(item.Quantity)}
+	})
+}
+
+func printOrders(output string, list []client.Order) error {
+	return printRows(output, list, []string{"ID", "CREATED", "STATUS", "BOOKS", "TOTAL"},
+		func(o client.Order) []string {
+			copies := 0
+			for _, l := range o.Lines {
+				copies += l.Quantity
+			}
+			return []string{o.ID, o.Created.Format(time.DateTime), o.Status, strconv.Itoa(copies), o.Total + " " + o.Currency}
+		})
+}
+
+// printOrder prints an order with one row per book.
+func printOrder(output string, order *client.Order) error {
+	if output == outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(order)
+	}
+	if output == outputTable {
+		fmt.Printf("order %s, %s, total %s %s\n", order.ID, order.Status, order.Total, order.Currency)
+	}
+	return printRows(output, order.Lines, []string{"BOOK", "TITLE", "QUANTITY", "LIST PRICE", "PRICE", "TOTAL"},
+		func(l client.OrderLine) []string {
+			return []string{l.BookID, l.Title, strconv.Itoa(l.Quantity), l.ListPrice, l.UnitPrice, l.Total}
+		})
+}
+
+func printPriceRules(output string, list []client.PriceRule) error {
+	return printRows(output, list, []string{"ID", "NAME", "DISCOUNT", "TAG", "AUTHOR", "FROM", "UNTIL"},
+		func(r client.PriceRule) []string {
+			discount := r.Value + "%"
+			if r.Kind == client.KindFixed {
+				discount = r.Value + " " + r.Currency
+			}
+			return []string{r.ID, r.Name, discount, r.Tag, r.Author, r.From, r.Until}
+		})
+}
+
+func printCopies(output string, list []client.Copy) error {
+	return printRows(output, list, []string{"BARCODE", "BOOK", "STATUS", "BORROWER", "ADDED"},
+		func(c client.Copy) []string {
+			return []string{c.Barcode, c.BookID, c.Status, c.Borrower, c.Added.Format(time.DateTime)}
+		})
+}
+
+func printLoans(output string, list []client.Loan) error {
+	return printRows(output, list, []string{"ID", "BARCODE", "BOOK", "BORROWER", "BORROWED", "DUE", "RETURNED"},
+		func(l client.Loan) []string {
+			due := l.Due.Format(time.DateOnly)
+			if l.Overdue {
+				due += " (overdue)"
+			}
+			var returned string
+			if l.Returned != nil {
+				returned = l.Returned.Format(time.DateOnly)
+			}
+			return []string{l.ID, l.Barcode, l.BookID, l.Borrower, l.Borrowed.Format(time.DateOnly), due, returned}
+		})
+}
+
+func printHolds(output string, list []client.Hold) error {
+	return printRows(output, list, []string{"ID", "BOOK", "BORROWER", "STATUS", "COPY", "PLACED"},
+		func(h client.Hold) []string {
+			return []string{h.ID, h.BookID, h.Borrower, h.Status, h.Barcode, h.Placed.Format(time.DateTime)}
+		})
+}
+
+func printMovements(output string, list []client.StockMovement) error {
+	return printRows(output, list, []string{"TIME", "DELTA", "STOCK", "REASON"},
+		func(m client.StockMovement) []string {
+			return []string{m.Time.Format(time.DateTime), fmt.Sprintf("%+d", m.Delta), strconv.Itoa(m.Stock), m.Reason}
+		})
+}
+
+func printReviews(output string, list []client.Review) error {
+	return printRows(output, list, []string{"ID", "REVIEWER", "RATING", "CREATED", "TEXT"},
+		func(r client.Review) []string {
+			return []string{r.ID, r.Reviewer, strconv.Itoa(r.Rating), r.Created.Format(time.DateOnly), r.Text}
+		})
+}
+
+type authorCount struct {
+	client.Author
+	Books int `json:"books"`
+}
+
+// contributors returns the names of everybody who worked on b. Servers
+// from before the list only send the author string.
+func contributors(b client.Book) []string {
+	if len(b.Authors) == 0 {
+		return []string{b.Author}
+	}
+	var names []string
+	for _, c := range b.Authors {
+		if !slices.Contains(names, c.Name) {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// printAuthors prints the authors in the order of the server, with the
+// number of books referring to each of them by id. Contributors without an
+// author entry are left out.
+func printAuthors(output string, authors []client.Author, list []client.Book) error {
+	counts := map[string]int{}
+	for _, b := range list {
+		var ids []string
+		for _, c := range b.Authors {
+			if c.AuthorID != "" && !slices.Contains(ids, c.AuthorID) {
+				ids = append(ids, c.AuthorID)
+				counts[c.AuthorID]++
+			}
+		}
+	}
+	rows := make([]authorCount, 0, len(authors))
+	for _, a := range authors {
+		rows = append(rows, authorCount{a, counts[a.ID]})
+	}
+
+	return printRows(output, rows, []string{"ID", "NAME", "SORT NAME", "BORN", "DIED", "BOOKS"}, func(a authorCount) []string {
+		return []string{a.ID, a.Name, a.SortName, a.BirthYear, a.DeathYear, strconv.Itoa(a.Books)}
+	})
+}
+
+func printCounts(output, name string, list []client.Count) error {
+	return printRows(output, list, []string{name, "BOOKS"}, func(c client.Count) []string {
+		return []string{c.Name, strconv.Itoa(c.Books)}
+	})
+}
+
+func printStrings(output, name string, list []string) error {
+	return printRows(output, list, []string{name}, func(s string) []string {
+		return []string{s}
+	})
+}
+
+type catalogStats struct {
+	Books        int     `json:"books"`
+	Authors      int     `json:"authors"`
+	EarliestYear int     `json:"earliestYear,omitempty"`
+	LatestYear   int     `json:"latestYear,omitempty"`
+	TotalPages   int     `json:"totalPages"`
+	AveragePages float64 `json:"averagePages"`
+}
+
+// printStats prints figures about the catalog. Years and pages that are not
+// plain numbers are left out of them.
+func printStats(output string, list []client.Book) error {
+	stats := catalogStats{Books: len(list)}
+	authors := map[string]bool{}
+	withPages := 0
+	for _, b := range list {
+		for _, name := range contributors(b) {
+			authors[name] = true
+		}
+		if year, err := strconv.Atoi(b.Year); err == nil {
+			if stats.EarliestYear == 0 || year < stats.EarliestYear {
+				stats.EarliestYear = year
+			}
+			if year > stats.LatestYear {
+				stats.LatestYear = year
+			}
+		}
+		if pages, err := strconv.Atoi(b.Pages); err == nil {
+			stats.TotalPages += pages
+			withPages++
+		}
+	}
+	stats.Authors = len(authors)
+	if withPages > 0 {
+		stats.AveragePages = float64(stats.TotalPages) / float64(withPages)
+	}
+
+	if output == outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+	return printRows(output, []catalogStats{stats}, []string{"BOOKS", "AUTHORS", "YEARS", "PAGES", "AVG PAGES"},
+		func(s catalogStats) []string {
+			years := "-"
+			if s.LatestYear != 0 {
+				years = fmt.Sprintf("%d-%d", s.EarliestYear, s.LatestYear)
+			}
+			return []string{strconv.Itoa(s.Books), strconv.Itoa(s.Authors), years,
+				strconv.Itoa(s.TotalPages), strconv.FormatFloat(s.AveragePages, 'f', 1, 64)}
+		})
+}
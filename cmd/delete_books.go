@@ -3,26 +3,18 @@ package main
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"time"
 
+	"github.com/CAPS-Cloud/exercises/internal/catalog"
+	"github.com/CAPS-Cloud/exercises/internal/openapi"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
 	"github.com/labstack/echo/v4"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-type BookStore struct {
-	ID          string `bson:"id"`
-	BookName    string `bson:"bookname"`
-	BookAuthor  string `bson:"bookauthor"`
-	BookEdition string `bson:"bookedition"`
-	BookPages   string `bson:"bookpages"`
-	BookYear    string `bson:"bookyear"`
-}
-
 func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -51,25 +43,12 @@ func main() {
 		}
 	}()
 
-	coll := client.Database("exercise-1").Collection("information")
+	repo := catalog.NewFromEnv(client.Database("exercise-1"))
 
 	e := echo.New()
-	e.DELETE("/api/books/:id", func(c echo.Context) error {
-		id := c.Param("id")
-		filter := bson.M{"id": id}
-		result, err := coll.DeleteOne(context.TODO(), filter)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to delete book",
-			})
-		}
-		if result.DeletedCount == 0 {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": "Book not found",
-			})
-		}
-		return c.NoContent(http.StatusOK)
-	})
+	problem.Register(e)
+	e.Use(openapi.Validator(openapi.OptionsFromEnv()))
+	e.DELETE("/api/books/:id", catalog.DeleteHandler(repo))
 
 	e.Logger.Fatal(e.Start(":3034"))
 }
@@ -3,48 +3,18 @@ package main
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"time"
 
+	"github.com/CAPS-Cloud/exercises/internal/catalog"
+	"github.com/CAPS-Cloud/exercises/internal/openapi"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
 	"github.com/labstack/echo/v4"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-type BookStore struct {
-	ID          string `bson:"id"`
-	BookName    string `bson:"bookname"`
-	BookAuthor  string `bson:"bookauthor"`
-	BookEdition string `bson:"bookedition"`
-	BookPages   string `bson:"bookpages"`
-	BookYear    string `bson:"bookyear"`
-}
-
-func getAllBooksForAPI(coll *mongo.Collection) []map[string]interface{} {
-	cursor, err := coll.Find(context.TODO(), bson.D{{}})
-	var results []BookStore
-	if err = cursor.All(context.TODO(), &results); err != nil {
-		panic(err)
-	}
-
-	var ret []map[string]interface{}
-	for _, res := range results {
-		ret = append(ret, map[string]interface{}{
-			"id":      res.ID,
-			"title":   res.BookName,
-			"author":  res.BookAuthor,
-			"pages":   res.BookPages,
-			"edition": res.BookEdition,
-			"year":    res.BookYear,
-		})
-	}
-
-	return ret
-}
-
 func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -73,13 +43,13 @@ func main() {
 		}
 	}()
 
-	coll := client.Database("exercise-1").Collection("information")
+	repo := catalog.NewFromEnv(client.Database("exercise-1"))
 
 	e := echo.New()
-	e.GET("/api/books", func(c echo.Context) error {
-		books := getAllBooksForAPI(coll)
-		return c.JSON(http.StatusOK, books)
-	})
+	problem.Register(e)
+	e.Use(openapi.Validator(openapi.OptionsFromEnv()))
+	e.GET("/api/books", catalog.ListHandler(repo))
+	e.GET("/api/books/:id", catalog.GetHandler(repo))
 
 	e.Logger.Fatal(e.Start(":3031"))
 }
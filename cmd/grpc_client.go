@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/bookpb"
+	"github.com/CAPS-Cloud/exercises/internal/rpc"
+)
+
+// A small client for trying out the gRPC book service, e.g.
+//
+//	go run cmd/grpc_client.go list
+//	go run cmd/grpc_client.go create '{"id":"42","title":"Dune","author":"Frank Herbert"}'
+//	go run cmd/grpc_client.go update 42 '{"year":"1965"}'
+//	go run cmd/grpc_client.go watch
+//
+// Books are read and printed in the JSON shape of the REST API.
+
+const usage = `usage: grpc_client [-addr host:port] <command>
+
+commands:
+  list                  list all books
+  get <id>              get a single book
+  create <json>         create a book
+  update <id> <json>    update the non-empty fields of a book
+  delete <id>           delete a book
+  watch [stream seq]    print book events as they happen
+`
+
+func main() {
+	addr := flag.String("addr", "localhost:3035", "address of the gRPC book service")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	client, conn, err := rpc.Dial(*addr)
+	if err != nil {
+		fmt.Printf("failed to connect to %s: %v\n", *addr, err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	if err := run(client, flag.Args()); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func run(client bookpb.BookServiceClient, args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	if args[0] != "watch" {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		stream, err := client.List(ctx, &bookpb.ListBooksRequest{})
+		if err != nil {
+			return err
+		}
+		for {
+			book, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			printJSON(book)
+		}
+
+	case args[0] == "get" && len(args) == 2:
+		book, err := client.Get(ctx, &bookpb.GetBookRequest{Id: args[1]})
+		if err != nil {
+			return err
+		}
+		printJSON(book)
+
+	case args[0] == "create" && len(args) == 2:
+		var book bookpb.Book
+		if err := json.Unmarshal([]byte(args[1]), &book); err != nil {
+			return fmt.Errorf("invalid book: %w", err)
+		}
+		created, err := client.Create(ctx, &bookpb.CreateBookRequest{Book: &book})
+		if err != nil {
+			return err
+		}
+		printJSON(created)
+
+	case args[0] == "update" && len(args) == 3:
+		var book bookpb.Book
+		if err := json.Unmarshal([]byte(args[2]), &book); err != nil {
+			return fmt.Errorf("invalid book: %w", err)
+		}
+		updated, err := client.Update(ctx, &bookpb.UpdateBookRequest{Id: args[1], Book: &book})
+		if err != nil {
+			return err
+		}
+		printJSON(updated)
+
+	case args[0] == "delete" && len(args) == 2:
+		_, err := client.Delete(ctx, &bookpb.DeleteBookRequest{Id: args[1]})
+		return err
+
+	case args[0] == "watch" && (len(args) == 1 || len(args) == 3):
+		req := &bookpb.WatchBooksRequest{}
+		if len(args) == 3 {
+			req.Stream = args[1]
+			if _, err := fmt.Sscan(args[2], &req.Since); err != nil {
+				return fmt.Errorf("invalid sequence number %q", args[2])
+			}
+		}
+		stream, err := client.Watch(ctx, req)
+		if err != nil {
+			return err
+		}
+		for {
+			ev, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+			printJSON(ev)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	return nil
+}
+
+func printJSON(v interface{}) {
+	out, _ := json.Marshal(v)
+	fmt.Println(string(out))
+}
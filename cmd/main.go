@@ -5,15 +5,34 @@ import (
 	"os"
 	"bytes"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"html/template"
 	"io"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/CAPS-Cloud/exercises/internal/backup"
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/catalog"
+	"github.com/CAPS-Cloud/exercises/internal/content"
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"github.com/CAPS-Cloud/exercises/internal/gql"
+	"github.com/CAPS-Cloud/exercises/internal/migrate"
+	"github.com/CAPS-Cloud/exercises/internal/openapi"
+	"github.com/CAPS-Cloud/exercises/internal/outbox"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/CAPS-Cloud/exercises/internal/rpc"
+	"github.com/CAPS-Cloud/exercises/internal/seed"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"github.com/CAPS-Cloud/exercises/internal/webhooks"
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -26,12 +45,32 @@ import (
 // More on these "tags" like `bson:"_id,omitempty"`: https://go.dev/wiki/Well-known-struct-tags
 type BookStore struct {
 	MongoID     primitive.ObjectID `bson:"_id,omitempty"`
-	ID          string
-	BookName    string
-	BookAuthor  string
-	BookEdition string
-	BookPages   string
-	BookYear    string
+	ID          string             `bson:"id"`
+	BookName    string             `bson:"bookname"`
+	BookAuthor  string             `bson:"bookauthor"`
+	BookEdition string             `bson:"bookedition"`
+	BookPages   string             `bson:"bookpages"`
+	BookYear    string             `bson:"bookyear"`
+	ISBN        string             `bson:"isbn"`
+	// Co-authors, editors and translators, see books.Contributor.
+	Authors   []books.Contributor `bson:"authors"`
+	Publisher string              `bson:"publisher"`
+	Series    string              `bson:"series"`
+	Volume    string              `bson:"volume"`
+	Tags      []string            `bson:"tags"`
+	Genres    []string            `bson:"genres"`
+	// Average rating and number of the reviews, see catalog.RegisterReviews.
+	Rating      float64 `bson:"rating"`
+	ReviewCount int     `bson:"review_count"`
+	// Copies on hand, see catalog.RegisterStock.
+	Stock int `bson:"stock"`
+	// Copies of the lending library and how many are on the shelf, see
+	// catalog.RegisterLending.
+	Copies    int `bson:"copies"`
+	Available int `bson:"available"`
+	// List price, without the discounts of catalog.RegisterPricing.
+	ListPrice string `bson:"list_price"`
+	Currency  string `bson:"currency"`
 }
 
 // Wraps the "Template" struct to associate a necessary method
@@ -81,7 +120,7 @@ func prepareDatabase(client *mongo.Client, dbName string, collecName string) (*m
 		return nil, err
 	}
 	if !slices.Contains(names, collecName) {
-		cmd := bson.D{{"create", collecName}}
+		cmd := bson.D{{Key: "create", Value: collecName}}
 		var result bson.M
 		if err = db.RunCommand(context.TODO(), cmd).Decode(&result); err != nil {
 			log.Fatal(err)
@@ -93,74 +132,13 @@ func prepareDatabase(client *mongo.Client, dbName string, collecName string) (*m
 	return coll, nil
 }
 
-// Here we prepare some fictional data and we insert it into the database
-// the first time we connect to it. Otherwise, we check if it already exists.
-func prepareData(client *mongo.Client, coll *mongo.Collection) {
-	startData := []BookStore{
-		{
-			ID:          "example1",
-			BookName:    "The Vortex",
-			BookAuthor:  "José Eustasio Rivera",
-			BookEdition: "958-30-0804-4",
-			BookPages:   "292",
-			BookYear:    "1924",
-		},
-		{
-			ID:          "example2",
-			BookName:    "Frankenstein",
-			BookAuthor:  "Mary Shelley",
-			BookEdition: "978-3-649-64609-9",
-			BookPages:   "280",
-			BookYear:    "1818",
-		},
-		{
-			ID:          "example3",
-			BookName:    "The Black Cat",
-			BookAuthor:  "Edgar Allan Poe",
-			BookEdition: "978-3-99168-238-7",
-			BookPages:   "280",
-			BookYear:    "1843",
-		},
-	}
-
-	// This syntax helps us iterate over arrays. It behaves similar to Python
-	// However, range always returns a tuple: (idx, elem). You can ignore the idx
-	// by using _.
-	// In the topic of function returns: sadly, there is no standard on return types from function. Most functions
-	// return a tuple with (res, err), but this is not granted. Some functions
-	// might return a ret value that includes res and the err, others might have
-	// an out parameter.
-	for _, book := range startData {
-		cursor, err := coll.Find(context.TODO(), book)
-		var results []BookStore
-		if err = cursor.All(context.TODO(), &results); err != nil {
-			panic(err)
-		}
-		if len(results) > 1 {
-			log.Fatal("more records were found")
-		} else if len(results) == 0 {
-			result, err := coll.InsertOne(context.TODO(), book)
-			if err != nil {
-				panic(err)
-			} else {
-				fmt.Printf("%+v\n", result)
-			}
-
-		} else {
-			for _, res := range results {
-				cursor.Decode(&res)
-				fmt.Printf("%+v\n", res)
-			}
-		}
-	}
-}
-
 // Generic method to perform "SELECT * FROM BOOKS" (if this was SQL, which
 // it is not :D ), and then we convert it into an array of map. In Golang, you
 // define a map by writing map[<key type>]<value type>{<key>:<value>}.
 // interface{} is a special type in Golang, basically a wildcard...
-func findAllBooks(coll *mongo.Collection) []map[string]interface{} {
-	cursor, err := coll.Find(context.TODO(), bson.D{{}})
+// filter selects the books, bson.D{{}} selects all of them.
+func findAllBooks(coll *mongo.Collection, filter interface{}) []map[string]interface{} {
+	cursor, err := coll.Find(context.TODO(), filter)
 	var results []BookStore
 	if err = cursor.All(context.TODO(), &results); err != nil {
 		panic(err)
@@ -173,59 +151,382 @@ func findAllBooks(coll *mongo.Collection) []map[string]interface{} {
 			"BookName":    res.BookName,
 			"BookAuthor":  res.BookAuthor,
 			"BookEdition": res.BookEdition,
+			"ISBN":        res.ISBN,
 			"BookPages":   res.BookPages,
+			"Genres":      strings.Join(res.Genres, ", "),
+			"Stars":       ratingStars(res.Rating),
+			"Rating":      res.Rating,
+			"Reviews":     res.ReviewCount,
+			"Stock":       res.Stock,
+			"Available":   available(res.Available, res.Copies),
+			"Price":       listPrice(res.ListPrice, res.Currency),
 		})
 	}
 
 	return ret
 }
 
-func getAllBooksForAPI(coll *mongo.Collection) []map[string]interface{} {
+// Renders an average rating as five stars, e.g. ★★★★☆ for 3.6.
+func ratingStars(rating float64) string {
+	n := int(math.Round(rating))
+	n = max(0, min(n, 5))
+	return strings.Repeat("★", n) + strings.Repeat("☆", 5-n)
+}
+
+// Renders a list price with its currency, e.g. "12.99 EUR", or nothing for
+// books without a price.
+func listPrice(amount, currency string) string {
+	if amount == "" {
+		return ""
+	}
+	return amount + " " + currency
+}
+
+// Renders the lending copies on the shelf out of all copies, e.g. "2 / 3",
+// or nothing for books the library does not lend.
+func available(n, copies int) string {
+	if copies == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d / %d", n, copies)
+}
+
+// Prepares the data of the "book-event" template: the event type and the
+// changed row, in the same shape as the rows of findAllBooks.
+func bookEventView(ev events.Event) map[string]interface{} {
+	row := map[string]interface{}{
+		"ID":          ev.MongoID.Hex(),
+		"BookName":    ev.Book.BookName,
+		"BookAuthor":  ev.Book.BookAuthor,
+		"BookEdition": ev.Book.BookEdition,
+		"ISBN":        ev.Book.ISBN,
+		"BookPages":   ev.Book.BookPages,
+		"Genres":      strings.Join(ev.Book.Genres, ", "),
+		"Stars":       ratingStars(ev.Book.Rating),
+		"Rating":      ev.Book.Rating,
+		"Reviews":     ev.Book.ReviewCount,
+		"Stock":       ev.Book.Stock,
+		"Available":   available(ev.Book.Available, ev.Book.Copies),
+		"Price":       listPrice(ev.Book.ListPrice, ev.Book.Currency),
+	}
+	// Updated rows replace the existing row with the same id
+	if ev.Type == events.BookUpdated {
+		row["OOB"] = "true"
+	}
+
+	return map[string]interface{}{
+		"Type": ev.Type,
+		"Row":  row,
+	}
+}
+
+// Prepares the data of the "book-table" template: the rows of the books
+// matching q, a chip per facet value narrowing them down further, and a chip
+// per active filter removing it again. Every chip links to /books with the
+// resulting query. The table follows the events of the books of q only.
+func bookTableView(q catalog.Query, rows []map[string]interface{}, facets *catalog.Facets) map[string]interface{} {
+	link := func(q catalog.Query) string {
+		return "/books?" + q.Params().Encode()
+	}
+
+	var groups []map[string]interface{}
+	for _, g := range []struct {
+		label  string
+		counts []catalog.Count
+		set    func(*catalog.Query, string)
+	}{
+		{"Genre", facets.Genre, func(q *catalog.Query, v string) { q.Genre = v }},
+		{"Author", facets.Author, func(q *catalog.Query, v string) { q.Author = v }},
+		{"Decade", facets.Decade, func(q *catalog.Query, v string) { q.Decade = v }},
+	} {
+		var chips []map[string]interface{}
+		for _, count := range g.counts {
+			narrowed := q
+			g.set(&narrowed, count.Name)
+			chips = append(chips, map[string]interface{}{
+				"Name":  count.Name,
+				"Books": count.Books,
+				"URL":   link(narrowed),
+			})
+		}
+		if len(chips) > 0 {
+			groups = append(groups, map[string]interface{}{"Label": g.label, "Chips": chips})
+		}
+	}
+
+	var active []map[string]interface{}
+	for _, f := range []struct {
+		label, value string
+		clear        func(*catalog.Query)
+	}{
+		{"Search", q.Text, func(q *catalog.Query) { q.Text = "" }},
+		{"Genre", q.Genre, func(q *catalog.Query) { q.Genre = "" }},
+		{"Author", q.Author, func(q *catalog.Query) { q.Author = "" }},
+		{"Decade", q.Decade, func(q *catalog.Query) { q.Decade = "" }},
+		{"Tag", q.Tag, func(q *catalog.Query) { q.Tag = "" }},
+	} {
+		if f.value == "" {
+			continue
+		}
+		widened := q
+		f.clear(&widened)
+		active = append(active, map[string]interface{}{
+			"Label": f.label,
+			"Value": f.value,
+			"URL":   link(widened),
+		})
+	}
+
+	return map[string]interface{}{
+		"Rows":   rows,
+		"Facets": groups,
+		"Active": active,
+		"Events": "/books/events?" + q.Params().Encode(),
+	}
+}
+
+// Prepares the data of the "create-form" template: one entry per field with
+// its value and what is wrong with it, if anything. The rules are the ones
+// of books.Request, the same as for the REST API.
+func createFormView(req books.Request, errs validation.Errors, created string) map[string]interface{} {
+	messages := map[string]string{}
+	for _, e := range errs {
+		messages[e.Field] = e.Message
+	}
+
+	// Empty rather than 0 for books that are never reordered
+	var reorderLevel string
+	if req.ReorderLevel != 0 {
+		reorderLevel = strconv.Itoa(req.ReorderLevel)
+	}
+
+	var fields []map[string]interface{}
+	for _, f := range []struct {
+		name, label, value string
+		required           bool
+	}{
+		{"id", "ID", req.ID, true},
+		{"title", "Title", req.Title, true},
+		{"author", "Author", req.Author, true},
+		{"isbn", "ISBN", req.ISBN, false},
+		{"edition", "Edition", req.Edition, false},
+		{"pages", "Pages", req.Pages, false},
+		{"year", "Year", req.Year, false},
+		{"publisher", "Publisher", req.Publisher, false},
+		{"series", "Series", req.Series, false},
+		{"volume", "Volume", req.Volume, false},
+		{"genres", "Genres", strings.Join(req.Genres, ", "), false},
+		{"tags", "Tags", strings.Join(req.Tags, ", "), false},
+		{"reorderLevel", "Reorder level", reorderLevel, false},
+		{"listPrice", "List price", req.ListPrice, false},
+		{"currency", "Currency", req.Currency, false},
+	} {
+		fields = append(fields, map[string]interface{}{
+			"Name":     f.name,
+			"Label":    f.label,
+			"Value":    f.value,
+			"Required": f.required,
+			"Error":    messages[f.name],
+		})
+	}
+
+	return map[string]interface{}{
+		"Fields":  fields,
+		"Created": created,
+	}
+}
+
+// Lists everybody who worked on a book, ordered by name, with the number of
+// their books. Co-authors are counted each on their own.
+func findAllAuthors(coll *mongo.Collection) []map[string]interface{} {
 	cursor, err := coll.Find(context.TODO(), bson.D{{}})
 	var results []BookStore
 	if err = cursor.All(context.TODO(), &results); err != nil {
 		panic(err)
 	}
 
-	var ret []map[string]interface{}
+	counts := map[string]int{}
 	for _, res := range results {
+		book := books.Book{BookAuthor: res.BookAuthor, Authors: res.Authors}
+		seen := map[string]bool{}
+		for _, c := range book.Contributors() {
+			if !seen[c.Name] {
+				seen[c.Name] = true
+				counts[c.Name]++
+			}
+		}
+	}
+
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var ret []map[string]interface{}
+	for _, name := range names {
 		ret = append(ret, map[string]interface{}{
-			"id":      res.ID,
-			"title":   res.BookName,
-			"author":  res.BookAuthor,
-			"pages":   res.BookPages,
-			"edition": res.BookEdition,
-			"year":    res.BookYear,
+			"Name":  name,
+			"Books": counts[name],
 		})
 	}
 
 	return ret
 }
 
-func findAllAuthors(coll *mongo.Collection) []map[string]interface{} {
-	cursor, err := coll.Find(context.TODO(), bson.D{{}})
-	var results []BookStore
+// Groups the books of every series, ordered by the name of the series, with
+// their volumes in order.
+func findAllSeries(coll *mongo.Collection) []map[string]interface{} {
+	cursor, err := coll.Find(context.TODO(), bson.M{"series": bson.M{"$nin": bson.A{"", nil}}})
+	var results []books.Book
 	if err = cursor.All(context.TODO(), &results); err != nil {
 		panic(err)
 	}
 
-	var ret []map[string]interface{}
+	volumes := map[string][]books.Book{}
+	var names []string
 	for _, res := range results {
+		if _, ok := volumes[res.Series]; !ok {
+			names = append(names, res.Series)
+		}
+		volumes[res.Series] = append(volumes[res.Series], res)
+	}
+	slices.Sort(names)
+
+	var ret []map[string]interface{}
+	for _, name := range names {
+		list := volumes[name]
+		books.SortByVolume(list)
+
+		var rows []map[string]interface{}
+		for _, b := range list {
+			rows = append(rows, map[string]interface{}{
+				"Volume":     b.Volume,
+				"BookName":   b.BookName,
+				"BookAuthor": b.BookAuthor,
+				"Publisher":  b.Publisher,
+			})
+		}
 		ret = append(ret, map[string]interface{}{
-			"ID":         res.MongoID.Hex(),
-			"BookAuthor": res.BookAuthor,
+			"Name":  name,
+			"Books": rows,
 		})
 	}
 
-	// print ret
-	for _, r := range ret {
-		fmt.Printf("%+v\n", r)
+	return ret
+}
+
+// Runs one of the maintenance commands. args[0] is the name of the command,
+// the rest are its own flags and arguments.
+func runCommand(client *mongo.Client, args []string) error {
+	ctx := context.Background()
+	fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+	dbName := fs.String("db", "exercise-1", "database to work on")
+
+	switch args[0] {
+	case "backup":
+		out := fs.String("out", "", "archive to write (default backups/<db>-<timestamp>.tar.gz)")
+		collections := fs.String("collections", "", "comma separated collections to dump (default all)")
+		fs.Parse(args[1:])
+
+		path := *out
+		if path == "" {
+			if err := os.MkdirAll("backups", 0o755); err != nil {
+				return err
+			}
+			path = fmt.Sprintf("backups/%s-%s.tar.gz", *dbName, time.Now().UTC().Format("20060102T150405Z"))
+		}
+		var names []string
+		if *collections != "" {
+			names = strings.Split(*collections, ",")
+		}
+
+		manifest, err := backup.Backup(ctx, client.Database(*dbName), path, names)
+		if err != nil {
+			return err
+		}
+		for _, c := range manifest.Collections {
+			fmt.Printf("dumped %s.%s: %d documents\n", manifest.Database, c.Name, c.Documents)
+		}
+		fmt.Printf("backup written to %s\n", path)
+
+	case "restore":
+		drop := fs.Bool("drop", false, "replace the existing documents of the restored collections")
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: restore [-db name] [-drop] <archive>")
+		}
+
+		manifest, err := backup.Restore(ctx, client.Database(*dbName), fs.Arg(0), backup.RestoreOptions{Drop: *drop})
+		if err != nil {
+			return err
+		}
+		for _, c := range manifest.Collections {
+			fmt.Printf("restored %s.%s: %d documents\n", *dbName, c.Name, c.Documents)
+		}
+
+	case "migrate":
+		fs.Parse(args[1:])
+		migrator := migrate.New(client.Database(*dbName))
+
+		// up and down take an optional number: the target version for up,
+		// the number of steps to revert for down.
+		n := 0
+		if fs.NArg() > 1 {
+			var err error
+			if n, err = strconv.Atoi(fs.Arg(1)); err != nil || n < 0 {
+				return fmt.Errorf("invalid number %q", fs.Arg(1))
+			}
+		}
+
+		switch fs.Arg(0) {
+		case "up":
+			applied, err := migrator.Up(ctx, n)
+			for _, m := range applied {
+				fmt.Printf("applied migration %d: %s\n", m.Version, m.Description)
+			}
+			return err
+		case "down":
+			if n == 0 {
+				n = 1
+			}
+			reverted, err := migrator.Down(ctx, n)
+			for _, m := range reverted {
+				fmt.Printf("reverted migration %d: %s\n", m.Version, m.Description)
+			}
+			return err
+		case "status":
+			statuses, err := migrator.Status(ctx)
+			if err != nil {
+				return err
+			}
+			for _, st := range statuses {
+				applied := "pending"
+				if st.Applied {
+					applied = "applied " + st.AppliedAt.Format(time.RFC3339)
+				}
+				fmt.Printf("%4d  %-40s %s\n", st.Version, st.Description, applied)
+			}
+		default:
+			return fmt.Errorf("usage: migrate [-db name] up [version] | down [steps] | status")
+		}
 	}
 
-	return ret
+	return nil
 }
 
 func main() {
+	// The initial data is loaded from fixtures: --seed=demo (the default) loads
+	// the built-in demo books, --seed=file loads the YAML or JSON file given by
+	// --seed-file, and --seed=none skips seeding altogether.
+	seedOpts := seed.RegisterFlags(flag.CommandLine, seed.ModeDemo)
+	// Pending schema migrations are applied before the server starts, unless
+	// disabled with --migrate=false. They can also be run with the migrate
+	// command.
+	autoMigrate := flag.Bool("migrate", true, "apply pending schema migrations on startup")
+	grpcAddr := flag.String("grpc-addr", ":3035", "address of the gRPC book service, empty to disable it")
+	flag.Parse()
+
 	// Connect to the database. Such defer keywords are used once the local
 	// context returns; for this case, the local context is the main function
 	// By user defer function, we make sure we don't leave connections
@@ -255,16 +556,79 @@ func main() {
 	// This is another way to specify the call of a function. You can define inline
 	// functions (or anonymous functions, similar to the behavior in Python)
 	defer func() {
-		if err = client.Disconnect(ctx); err != nil {
+		if err = client.Disconnect(context.Background()); err != nil {
 			panic(err)
 		}
 	}()
 
+	// Maintenance commands run instead of the server, e.g.
+	//   go run cmd/main.go backup -out catalog.tar.gz
+	//   go run cmd/main.go restore -db exercise-1-copy catalog.tar.gz
+	//   go run cmd/main.go migrate status
+	switch flag.Arg(0) {
+	case "backup", "restore", "migrate":
+		if err := runCommand(client, flag.Args()); err != nil {
+			fmt.Printf("%s failed: %v\n", flag.Arg(0), err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// You can use such name for the database and collection, or come up with
 	// one by yourself!
 	coll, err := prepareDatabase(client, "exercise-1", "information")
 
-	prepareData(client, coll)
+	// Migrations and seeding may take longer than connecting, so they do
+	// not run under its timeout.
+	if *autoMigrate {
+		applied, err := migrate.New(client.Database("exercise-1")).Up(context.Background(), 0)
+		for _, m := range applied {
+			fmt.Printf("applied migration %d: %s\n", m.Version, m.Description)
+		}
+		if err != nil {
+			fmt.Printf("failed to migrate the database: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	report, err := seed.Run(context.Background(), coll, seedOpts)
+	if err != nil {
+		fmt.Printf("failed to seed the database: %v\n", err)
+		os.Exit(1)
+	}
+	if report != nil {
+		fmt.Print(report)
+	}
+
+	// The repository is shared by the REST and gRPC APIs. Every change is
+	// recorded in its outbox; the relay turns them into webhook deliveries,
+	// which are sent in the background so a slow receiver never delays an
+	// API response.
+	repo := catalog.NewFromEnv(client.Database("exercise-1"))
+	box := repo.Outbox()
+	hooks := webhooks.NewStore(client.Database("exercise-1"))
+	go func() {
+		relay := outbox.NewRelay(box, func(ctx context.Context, entry outbox.Entry) error {
+			return hooks.Enqueue(ctx, entry.Event, entry.Data)
+		})
+		if err := relay.Run(context.Background()); err != nil {
+			log.Printf("outbox relay stopped: %v", err)
+		}
+	}()
+	go func() {
+		if err := webhooks.NewWorker(hooks).Run(context.Background()); err != nil {
+			log.Printf("webhook worker stopped: %v", err)
+		}
+	}()
+
+	// The feed follows the book events in the outbox, including those of the
+	// split services, and pushes them to the open book tables.
+	feed := events.NewFeed(box, coll)
+	go func() {
+		if err := feed.Run(context.Background()); err != nil {
+			log.Printf("event feed stopped: %v", err)
+		}
+	}()
 
 	// Here we prepare the server
 	e := echo.New()
@@ -272,10 +636,18 @@ func main() {
 	// Define our custom renderer
 	e.Renderer = loadTemplates()
 
+	// Every error, including those of Echo itself like 404, is answered
+	// with RFC 7807 problem details carrying the request id.
+	problem.Register(e)
+
 	// Log the requests. Please have a look at echo's documentation on more
 	// middleware
 	e.Use(LoggerRR)
 
+	// Check requests to /api/books against the OpenAPI document, and with
+	// OPENAPI_VALIDATE_RESPONSES=true the responses as well.
+	e.Use(openapi.Validator(openapi.OptionsFromEnv()))
+
 	e.Static("/css", "css")
 
 	// Endpoint definition. Here, we divided into two groups: top-level routes
@@ -286,9 +658,43 @@ func main() {
 		return c.Render(200, "index", nil)
 	})
 
+	// Takes the query parameters of GET /api/search, which the filter chips
+	// of the table link to.
 	e.GET("/books", func(c echo.Context) error {
-		books := findAllBooks(coll)
-		return c.Render(200, "book-table", books)
+		q, err := catalog.QueryFromParams(c.QueryParams())
+		var invalid *catalog.ValidationError
+		if errors.As(err, &invalid) {
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		}
+		facets, err := repo.Facets(c.Request().Context(), q)
+		if err != nil {
+			return err
+		}
+		books := findAllBooks(coll, q.Filter())
+		return c.Render(200, "book-table", bookTableView(q, books, facets))
+	})
+
+	// Takes the query of /books: books created while filters are active are
+	// only added to the table if they match them.
+	e.GET("/books/events", func(c echo.Context) error {
+		q, err := catalog.QueryFromParams(c.QueryParams())
+		var invalid *catalog.ValidationError
+		if errors.As(err, &invalid) {
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		}
+		filtered := len(q.Params()) > 0
+
+		return events.ServeSSE(c, feed, func(ev events.Event) ([]byte, error) {
+			if ev.Type == events.BookCreated && filtered {
+				n, err := coll.CountDocuments(c.Request().Context(), bson.M{"$and": bson.A{q.Filter(), bson.M{"_id": ev.MongoID}}})
+				if err != nil || n == 0 {
+					return nil, err
+				}
+			}
+			var buf bytes.Buffer
+			err := c.Echo().Renderer.Render(&buf, "book-event", bookEventView(ev), c)
+			return buf.Bytes(), err
+		})
 	})
 
 	e.GET("/authors", func(c echo.Context) error {
@@ -296,17 +702,72 @@ func main() {
 		return c.Render(200, "authors", authors)
 	})
 
+	e.GET("/series", func(c echo.Context) error {
+		series := findAllSeries(coll)
+		return c.Render(200, "series", series)
+	})
+
 	e.GET("/years", func(c echo.Context) error {
-		books := findAllBooks(coll)
+		books := findAllBooks(coll, bson.D{{}})
 		return c.Render(200, "years", books)
 	})
 
+	// The books whose stock is at or below their reorder level.
+	e.GET("/low-stock", func(c echo.Context) error {
+		list, err := repo.LowStock(c.Request().Context())
+		if err != nil {
+			return err
+		}
+		return c.Render(200, "low-stock", list)
+	})
+
+	// Every order, newest first.
+	e.GET("/orders", func(c echo.Context) error {
+		list, err := repo.Orders(c.Request().Context(), "")
+		if err != nil {
+			return err
+		}
+		return c.Render(200, "orders", list)
+	})
+
+	// The loans past their due time, the longest overdue first.
+	e.GET("/overdue", func(c echo.Context) error {
+		list, err := repo.Overdue(c.Request().Context(), time.Now())
+		if err != nil {
+			return err
+		}
+		return c.Render(200, "overdue", list)
+	})
+
 	e.GET("/search", func(c echo.Context) error {
 		return c.Render(200, "search-bar", nil)
 	})
 
 	e.GET("/create", func(c echo.Context) error {
-		return c.NoContent(http.StatusNoContent)
+		return c.Render(200, "create-form", createFormView(books.Request{}, nil, ""))
+	})
+
+	// The form is validated like POST /api/books. A form with errors is
+	// rendered again with 422, which index.html lets htmx swap in.
+	e.POST("/create", func(c echo.Context) error {
+		book, err := content.DecodeBook(c)
+		if err == nil {
+			_, err = repo.Create(c.Request().Context(), book)
+		}
+
+		var fields validation.Errors
+		var invalid *catalog.ValidationError
+		switch {
+		case err == nil:
+			return c.Render(200, "create-form", createFormView(books.Request{}, nil, book.BookName))
+		case errors.As(err, &invalid):
+			fields = invalid.Fields
+		case err == catalog.ErrConflict:
+			fields = validation.Errors{{Field: "id", Message: "is already taken"}}
+		case !errors.As(err, &fields):
+			return err
+		}
+		return c.Render(http.StatusUnprocessableEntity, "create-form", createFormView(books.NewRequest(book), fields, ""))
 	})
 
 	// You will have to expand on the allowed methods for the path
@@ -315,160 +776,67 @@ func main() {
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Reference/Methods
 	// It specifies the expected returned codes for each type of request
 	// method.
-	e.GET("/api/books", func(c echo.Context) error {
-		books := getAllBooksForAPI(coll)
-		return c.JSON(http.StatusOK, books)
-	})
+	e.GET("/api/books", catalog.ListHandler(repo))
+	e.GET("/api/books/:id", catalog.GetHandler(repo))
+	e.POST("/api/books", catalog.CreateHandler(repo))
+	e.PUT("/api/books/:id", catalog.UpdateHandler(repo))
+	e.DELETE("/api/books/:id", catalog.DeleteHandler(repo))
 
-	e.POST("/api/books", func(c echo.Context) error {
-		// Parse the incoming JSON with the client-side format
-		var requestData map[string]string
-		if err := c.Bind(&requestData); err != nil {
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"error": "Invalid request body",
-			})
-		}
+	// Authors with an entry of their own, which books refer to by id.
+	catalog.RegisterAuthors(e, repo)
 
-		// Extract fields with appropriate validation
-		id, hasID := requestData["id"]
-		title, hasTitle := requestData["title"]
-		author, hasAuthor := requestData["author"]
+	// The publishers and series of the catalog with their number of books.
+	e.GET("/api/publishers", catalog.PublishersHandler(repo))
+	e.GET("/api/series", catalog.SeriesHandler(repo))
 
-		// Check for required fields
-		if !hasID || id == "" || !hasTitle || title == "" || !hasAuthor || author == "" {
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"error": "Missing required fields: id, title, and author are mandatory",
-			})
-		}
+	// Tags of a book, the genre taxonomy, and search with counts per genre,
+	// author and decade.
+	catalog.RegisterSearch(e, repo)
 
-		// Create a BookStore object from the request data
-		newBook := BookStore{
-			ID:          id,
-			BookName:    title,
-			BookAuthor:  author,
-			BookPages:   requestData["pages"],   // Optional fields
-			BookEdition: requestData["edition"], // Optional fields
-			BookYear:    requestData["year"],    // Optional fields
-		}
+	// Reviews of a book, whose average rating and number the book carries.
+	catalog.RegisterReviews(e, repo)
 
-		// Check if a book with this ID already exists
-		filter := bson.M{"id": newBook.ID}
-		existingCount, err := coll.CountDocuments(context.TODO(), filter)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to check for existing book",
-			})
-		}
+	// Stock on hand, adjusted atomically, and the books to reorder.
+	catalog.RegisterStock(e, repo)
 
-		// If a book with this ID already exists, return an error
-		if existingCount > 0 {
-			return c.JSON(http.StatusConflict, map[string]string{
-				"error": "A book with this ID already exists",
-			})
-		}
+	// Discount rules and the exchange rates behind the effective prices.
+	catalog.RegisterPricing(e, repo)
 
-		// Insert the book into the database
-		_, err = coll.InsertOne(context.TODO(), newBook)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to create book",
-			})
-		}
+	// Carts, and the orders checked out from them with their stock reserved.
+	catalog.RegisterOrders(e, repo)
 
-		// Format the response in the same way as GET /api/books returns data
-		response := map[string]interface{}{
-			"id":      newBook.ID,
-			"title":   newBook.BookName,
-			"author":  newBook.BookAuthor,
-			"pages":   newBook.BookPages,
-			"edition": newBook.BookEdition,
-			"year":    newBook.BookYear,
-		}
+	// The lending library: copies with barcodes, loans and the hold queue.
+	catalog.RegisterLending(e, repo)
 
-		// Return 201 Created with the newly created book
-		return c.JSON(http.StatusCreated, response)
+	// Pushes book.created, book.updated and book.deleted messages over a
+	// WebSocket, see events.ServeWebSocket for resuming after a disconnect.
+	e.GET("/api/events", func(c echo.Context) error {
+		return events.ServeWebSocket(c, feed)
 	})
 
-	e.PUT("/api/books/:id", func(c echo.Context) error {
-		// Get the ID from path parameter
-		id := c.Param("id")
-
-		// Parse request body
-		var requestData map[string]string
-		if err := c.Bind(&requestData); err != nil {
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"error": "Invalid request body",
-			})
-		}
-
-		// Find the book by ID (not MongoID)
-		filter := bson.M{"id": id}
-		var existingBook BookStore
-		err := coll.FindOne(context.TODO(), filter).Decode(&existingBook)
-		if err != nil {
-			if err == mongo.ErrNoDocuments {
-				return c.JSON(http.StatusNotFound, map[string]string{
-					"error": "Book not found",
-				})
-			}
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Database error",
-			})
-		}
-
-		// Update fields from request
-		if title, ok := requestData["title"]; ok && title != "" {
-			existingBook.BookName = title
-		}
-		if author, ok := requestData["author"]; ok && author != "" {
-			existingBook.BookAuthor = author
-		}
-		if edition, ok := requestData["edition"]; ok && edition != "" {
-			existingBook.BookEdition = edition
-		}
-		if pages, ok := requestData["pages"]; ok && pages != "" {
-			existingBook.BookPages = pages
-		}
-		if year, ok := requestData["year"]; ok && year != "" {
-			existingBook.BookYear = year
-		}
-
-		// Update the book in the database
-		_, err = coll.ReplaceOne(context.TODO(), filter, existingBook)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to update book",
-			})
-		}
-
-		return c.NoContent(http.StatusOK)
-	})
+	webhooks.Register(e, hooks)
 
-	e.DELETE("/api/books/:id", func(c echo.Context) error {
-		// Get the ID from path parameter
-		id := c.Param("id")
+	// Queries and mutations over the same catalog, plus GraphiQL at GET
+	// /graphql to try them out.
+	gql.Register(e, repo)
 
-		// Create filter to find the book by ID (not MongoID)
-		filter := bson.M{"id": id}
+	// The OpenAPI document of /api/books and a Swagger UI page for it.
+	openapi.Register(e)
 
-		// Perform the deletion
-		result, err := coll.DeleteOne(context.TODO(), filter)
+	// The gRPC book service runs next to Echo in the same process, on its
+	// own port.
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to delete book",
-			})
-		}
-
-		// Check if any document was actually deleted
-		if result.DeletedCount == 0 {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": "Book not found",
-			})
+			fmt.Printf("failed to listen for gRPC on %s: %v\n", *grpcAddr, err)
+			os.Exit(1)
 		}
-
-		// Return 200 OK as specified in the requirements
-		return c.NoContent(http.StatusOK)
-	})
+		go func() {
+			if err := rpc.NewServer(repo, feed).Serve(lis); err != nil {
+				log.Printf("gRPC server stopped: %v", err)
+			}
+		}()
+	}
 
 	// We start the server and bind it to port 3030. For future references, this
 	// is the application's port and not the external one. For this first exercise,
@@ -485,6 +853,13 @@ func LoggerRR(next echo.HandlerFunc) echo.HandlerFunc {
 		req := c.Request()
 		res := c.Response()
 
+		// Event streams and WebSockets stay open for as long as the client
+		// is connected, so there is no single response to log.
+		if strings.Contains(req.Header.Get(echo.HeaderAccept), "text/event-stream") ||
+			req.Header.Get(echo.HeaderUpgrade) != "" {
+			return next(c)
+		}
+
 		// ----- Clone request body so handlers can still read it -----
 		var reqBody []byte
 		if req.Body != nil {
@@ -498,6 +873,10 @@ func LoggerRR(next echo.HandlerFunc) echo.HandlerFunc {
 
 		start := time.Now()
 		err := next(c)
+		if err != nil {
+			// Write the error now, so that it is logged below.
+			c.Error(err)
+		}
 		elapsed := time.Since(start)
 
 		// ----- Build nicely formatted output -----
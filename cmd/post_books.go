@@ -3,26 +3,18 @@ package main
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"time"
 
+	"github.com/CAPS-Cloud/exercises/internal/catalog"
+	"github.com/CAPS-Cloud/exercises/internal/openapi"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
 	"github.com/labstack/echo/v4"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-type BookStore struct {
-	ID          string `bson:"id"`
-	BookName    string `bson:"bookname"`
-	BookAuthor  string `bson:"bookauthor"`
-	BookEdition string `bson:"bookedition"`
-	BookPages   string `bson:"bookpages"`
-	BookYear    string `bson:"bookyear"`
-}
-
 func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -51,68 +43,12 @@ func main() {
 		}
 	}()
 
-	coll := client.Database("exercise-1").Collection("information")
+	repo := catalog.NewFromEnv(client.Database("exercise-1"))
 
 	e := echo.New()
-	e.POST("/api/books", func(c echo.Context) error {
-		var requestData map[string]string
-		if err := c.Bind(&requestData); err != nil {
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"error": "Invalid request body",
-			})
-		}
-
-		id, hasID := requestData["id"]
-		title, hasTitle := requestData["title"]
-		author, hasAuthor := requestData["author"]
-
-		if !hasID || id == "" || !hasTitle || title == "" || !hasAuthor || author == "" {
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"error": "Missing required fields: id, title, and author are mandatory",
-			})
-		}
-
-		newBook := BookStore{
-			ID:          id,
-			BookName:    title,
-			BookAuthor:  author,
-			BookPages:   requestData["pages"],
-			BookEdition: requestData["edition"],
-			BookYear:    requestData["year"],
-		}
-
-		filter := bson.M{"id": newBook.ID}
-		existingCount, err := coll.CountDocuments(context.TODO(), filter)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to check for existing book",
-			})
-		}
-
-		if existingCount > 0 {
-			return c.JSON(http.StatusConflict, map[string]string{
-				"error": "A book with this ID already exists",
-			})
-		}
-
-		_, err = coll.InsertOne(context.TODO(), newBook)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to create book",
-			})
-		}
-
-		response := map[string]interface{}{
-			"id":      newBook.ID,
-			"title":   newBook.BookName,
-			"author":  newBook.BookAuthor,
-			"pages":   newBook.BookPages,
-			"edition": newBook.BookEdition,
-			"year":    newBook.BookYear,
-		}
-
-		return c.JSON(http.StatusCreated, response)
-	})
+	problem.Register(e)
+	e.Use(openapi.Validator(openapi.OptionsFromEnv()))
+	e.POST("/api/books", catalog.CreateHandler(repo))
 
 	e.Logger.Fatal(e.Start(":3032"))
 }
@@ -3,26 +3,18 @@ package main
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"time"
 
+	"github.com/CAPS-Cloud/exercises/internal/catalog"
+	"github.com/CAPS-Cloud/exercises/internal/openapi"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
 	"github.com/labstack/echo/v4"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-type BookStore struct {
-	ID          string `bson:"id"`
-	BookName    string `bson:"bookname"`
-	BookAuthor  string `bson:"bookauthor"`
-	BookEdition string `bson:"bookedition"`
-	BookPages   string `bson:"bookpages"`
-	BookYear    string `bson:"bookyear"`
-}
-
 func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -51,57 +43,12 @@ func main() {
 		}
 	}()
 
-	coll := client.Database("exercise-1").Collection("information")
+	repo := catalog.NewFromEnv(client.Database("exercise-1"))
 
 	e := echo.New()
-	e.PUT("/api/books/:id", func(c echo.Context) error {
-		id := c.Param("id")
-		var requestData map[string]string
-		if err := c.Bind(&requestData); err != nil {
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"error": "Invalid request body",
-			})
-		}
-
-		filter := bson.M{"id": id}
-		var existingBook BookStore
-		err := coll.FindOne(context.TODO(), filter).Decode(&existingBook)
-		if err != nil {
-			if err == mongo.ErrNoDocuments {
-				return c.JSON(http.StatusNotFound, map[string]string{
-					"error": "Book not found",
-				})
-			}
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Database error",
-			})
-		}
-
-		if title, ok := requestData["title"]; ok && title != "" {
-			existingBook.BookName = title
-		}
-		if author, ok := requestData["author"]; ok && author != "" {
-			existingBook.BookAuthor = author
-		}
-		if edition, ok := requestData["edition"]; ok && edition != "" {
-			existingBook.BookEdition = edition
-		}
-		if pages, ok := requestData["pages"]; ok && pages != "" {
-			existingBook.BookPages = pages
-		}
-		if year, ok := requestData["year"]; ok && year != "" {
-			existingBook.BookYear = year
-		}
-
-		_, err = coll.ReplaceOne(context.TODO(), filter, existingBook)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to update book",
-			})
-		}
-
-		return c.NoContent(http.StatusOK)
-	})
+	problem.Register(e)
+	e.Use(openapi.Validator(openapi.OptionsFromEnv()))
+	e.PUT("/api/books/:id", catalog.UpdateHandler(repo))
 
 	e.Logger.Fatal(e.Start(":3033"))
 }
@@ -2,15 +2,28 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 
 	// TODO: import template logic from shared/internal package
 	// "html/template"
 	// "io"
+	"log"
+	"net"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/CAPS-Cloud/exercises/internal/catalog"
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"github.com/CAPS-Cloud/exercises/internal/gql"
+	"github.com/CAPS-Cloud/exercises/internal/migrate"
+	"github.com/CAPS-Cloud/exercises/internal/openapi"
+	"github.com/CAPS-Cloud/exercises/internal/outbox"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/CAPS-Cloud/exercises/internal/rpc"
+	"github.com/CAPS-Cloud/exercises/internal/seed"
+	"github.com/CAPS-Cloud/exercises/internal/webhooks"
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -20,6 +33,13 @@ import (
 // TODO: Remove duplicate Template, loadTemplates, and Render definitions. Use shared package instead.
 
 func main() {
+	// The split services share one database, so only the root service
+	// migrates and seeds it. Seeding is off unless --seed (or SEED) asks for it.
+	seedOpts := seed.RegisterFlags(flag.CommandLine, seed.ModeNone)
+	autoMigrate := flag.Bool("migrate", true, "apply pending schema migrations on startup")
+	grpcAddr := flag.String("grpc-addr", ":3035", "address of the gRPC book service, empty to disable it")
+	flag.Parse()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -47,7 +67,62 @@ func main() {
 		}
 	}()
 
+	coll := client.Database("exercise-1").Collection("information")
+
+	// Migrations and seeding may take longer than connecting, so they do
+	// not run under its timeout.
+	if *autoMigrate {
+		applied, err := migrate.New(client.Database("exercise-1")).Up(context.Background(), 0)
+		for _, m := range applied {
+			fmt.Printf("applied migration %d: %s\n", m.Version, m.Description)
+		}
+		if err != nil {
+			fmt.Printf("failed to migrate the database: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	report, err := seed.Run(context.Background(), coll, seedOpts)
+	if err != nil {
+		fmt.Printf("failed to seed the database: %v\n", err)
+		os.Exit(1)
+	}
+	if report != nil {
+		fmt.Print(report)
+	}
+
+	// The book services only record their changes in the outbox. They are
+	// relayed to webhook deliveries and sent from here; nginx routes
+	// /api/webhooks here as well.
+	repo := catalog.NewFromEnv(client.Database("exercise-1"))
+	box := repo.Outbox()
+	hooks := webhooks.NewStore(client.Database("exercise-1"))
+	go func() {
+		relay := outbox.NewRelay(box, func(ctx context.Context, entry outbox.Entry) error {
+			return hooks.Enqueue(ctx, entry.Event, entry.Data)
+		})
+		if err := relay.Run(context.Background()); err != nil {
+			log.Printf("outbox relay stopped: %v", err)
+		}
+	}()
+	go func() {
+		if err := webhooks.NewWorker(hooks).Run(context.Background()); err != nil {
+			log.Printf("webhook worker stopped: %v", err)
+		}
+	}()
+
+	// The feed follows the shared outbox, so it sees the writes of all split
+	// services. nginx routes /api/events here.
+	feed := events.NewFeed(box, coll)
+	go func() {
+		if err := feed.Run(context.Background()); err != nil {
+			log.Printf("event feed stopped: %v", err)
+		}
+	}()
+
 	e := echo.New()
+	problem.Register(e)
+	e.Use(openapi.Validator(openapi.OptionsFromEnv()))
 	// e.Renderer = loadTemplates() // TODO: set renderer from shared package
 
 	e.GET("/", func(c echo.Context) error {
@@ -55,5 +130,38 @@ func main() {
 		// return c.Render(200, "index", nil)
 	})
 
+	e.GET("/api/events", func(c echo.Context) error {
+		return events.ServeWebSocket(c, feed)
+	})
+
+	webhooks.Register(e, hooks)
+	catalog.RegisterAuthors(e, repo)
+	e.GET("/api/publishers", catalog.PublishersHandler(repo))
+	e.GET("/api/series", catalog.SeriesHandler(repo))
+	catalog.RegisterSearch(e, repo)
+	catalog.RegisterReviews(e, repo)
+	catalog.RegisterStock(e, repo)
+	catalog.RegisterPricing(e, repo)
+	catalog.RegisterOrders(e, repo)
+	catalog.RegisterLending(e, repo)
+
+	gql.Register(e, repo)
+	openapi.Register(e)
+
+	// The gRPC book service runs next to Echo in the same process, on its
+	// own port.
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			fmt.Printf("failed to listen for gRPC on %s: %v\n", *grpcAddr, err)
+			os.Exit(1)
+		}
+		go func() {
+			if err := rpc.NewServer(repo, feed).Serve(lis); err != nil {
+				log.Printf("gRPC server stopped: %v", err)
+			}
+		}()
+	}
+
 	e.Logger.Fatal(e.Start(":3030"))
 }
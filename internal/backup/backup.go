@@ -0,0 +1,376 @@
+// Package backup dumps the collections of a database into a compressed
+// archive and loads them back.
+//
+// An archive is a gzip-compressed tar file with one "<collection>.jsonl"
+// entry per collection, holding one document per line in canonical Extended
+// JSON so ObjectIDs and other BSON types survive the round trip, followed by
+// a "manifest.json" entry describing the archive.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// FormatVersion is the archive layout written by Backup. Restore accepts
+// archives up to this version.
+const FormatVersion = 1
+
+const manifestName = "manifest.json"
+
+// insertBatch is the number of documents sent per InsertMany on restore.
+const insertBatch = 500
+
+// Manifest describes the content of an archive.
+type Manifest struct {
+	Version     int          `json:"version"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Database    string       `json:"database"`
+	Collections []Collection `json:"collections"`
+}
+
+// Collection is the manifest entry of a single dumped collection.
+type Collection struct {
+	Name      string `json:"name"`
+	Documents int    `json:"documents"`
+	SHA256    string `json:"sha256"`
+}
+
+func entryName(collection string) string {
+	return collection + ".jsonl"
+}
+
+// Backup writes the given collections of db to path. When collections is
+// empty, every collection of the database is dumped except the system ones.
+// The archive is written to a temporary file next to path and renamed when
+// it is complete, so a failed backup never leaves a truncated archive.
+func Backup(ctx context.Context, db *mongo.Database, path string, collections []string) (_ *Manifest, err error) {
+	if len(collections) == 0 {
+		names, err := db.ListCollectionNames(ctx, bson.D{})
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if !strings.HasPrefix(name, "system.") {
+				collections = append(collections, name)
+			}
+		}
+		slices.Sort(collections)
+	}
+
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	manifest := &Manifest{
+		Version:   FormatVersion,
+		CreatedAt: time.Now().UTC(),
+		Database:  db.Name(),
+	}
+	for _, name := range collections {
+		entry, err := dumpCollection(ctx, tw, db.Collection(name), dir, manifest.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("dump %s: %w", name, err)
+		}
+		manifest.Collections = append(manifest.Collections, *entry)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, manifestName, data, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, os.Rename(f.Name(), path)
+}
+
+// dumpCollection adds the documents of coll to the archive. A tar header
+// holds the size of its entry, so they are spooled to a temporary file in
+// dir first and hashed on the way, rather than kept in memory.
+func dumpCollection(ctx context.Context, tw *tar.Writer, coll *mongo.Collection, dir string, modTime time.Time) (*Collection, error) {
+	spool, err := os.CreateTemp(dir, ".backup-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	cursor, err := coll.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	hash := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(spool, hash))
+	entry := &Collection{Name: coll.Name()}
+	for cursor.Next(ctx) {
+		line, err := bson.MarshalExtJSON(cursor.Current, true, false)
+		if err != nil {
+			return nil, err
+		}
+		w.Write(line)
+		if err := w.WriteByte('\n'); err != nil {
+			return nil, err
+		}
+		entry.Documents++
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	entry.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	size, err := spool.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	hdr := &tar.Header{
+		Name:    entryName(coll.Name()),
+		Mode:    0o644,
+		Size:    size,
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(tw, spool); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// Validate reads the whole archive and checks it against its manifest:
+// the format version, the presence, checksum and document count of every
+// collection, and that every line is a valid Extended JSON document.
+func Validate(path string) (*Manifest, error) {
+	var manifest *Manifest
+	seen := make(map[string]Collection)
+
+	err := walk(path, func(name string, r io.Reader) error {
+		if name == manifestName {
+			manifest = &Manifest{}
+			return json.NewDecoder(r).Decode(manifest)
+		}
+
+		collection, ok := strings.CutSuffix(name, ".jsonl")
+		if !ok {
+			return fmt.Errorf("unexpected archive entry %q", name)
+		}
+
+		hash := sha256.New()
+		entry := Collection{Name: collection}
+		err := scanDocuments(io.TeeReader(r, hash), func(bson.D) error {
+			entry.Documents++
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		entry.SHA256 = hex.EncodeToString(hash.Sum(nil))
+		seen[collection] = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest == nil {
+		return nil, errors.New("archive has no manifest")
+	}
+	if manifest.Version < 1 || manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+	for _, want := range manifest.Collections {
+		got, ok := seen[want.Name]
+		if !ok {
+			return nil, fmt.Errorf("collection %s is listed in the manifest but missing", want.Name)
+		}
+		if got.SHA256 != want.SHA256 {
+			return nil, fmt.Errorf("collection %s: checksum mismatch", want.Name)
+		}
+		if got.Documents != want.Documents {
+			return nil, fmt.Errorf("collection %s: expected %d documents, found %d", want.Name, want.Documents, got.Documents)
+		}
+		delete(seen, want.Name)
+	}
+	for name := range seen {
+		return nil, fmt.Errorf("collection %s is not listed in the manifest", name)
+	}
+
+	return manifest, nil
+}
+
+// RestoreOptions controls how an archive is loaded.
+type RestoreOptions struct {
+	// Drop removes the existing documents of every restored collection.
+	// The collections and their indexes are kept, archives hold documents
+	// only. Without it, restoring into a non-empty collection fails.
+	Drop bool
+}
+
+// Restore validates the archive at path and loads it into db, which may be
+// a different database than the one the archive was taken from.
+func Restore(ctx context.Context, db *mongo.Database, path string, opts RestoreOptions) (*Manifest, error) {
+	manifest, err := Validate(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	for _, c := range manifest.Collections {
+		coll := db.Collection(c.Name)
+		if opts.Drop {
+			if _, err := coll.DeleteMany(ctx, bson.D{}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		count, err := coll.EstimatedDocumentCount(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("collection %s.%s is not empty, use -drop to replace it", db.Name(), c.Name)
+		}
+	}
+
+	err = walk(path, func(name string, r io.Reader) error {
+		collection, ok := strings.CutSuffix(name, ".jsonl")
+		if !ok {
+			return nil
+		}
+		coll := db.Collection(collection)
+
+		batch := make([]interface{}, 0, insertBatch)
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			_, err := coll.InsertMany(ctx, batch)
+			batch = batch[:0]
+			return err
+		}
+		err := scanDocuments(r, func(doc bson.D) error {
+			batch = append(batch, doc)
+			if len(batch) == insertBatch {
+				return flush()
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("restore %s: %w", collection, err)
+		}
+		return flush()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// walk calls fn for every file entry of the archive at path.
+func walk(path string, fn func(name string, r io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func scanDocuments(r io.Reader, fn func(bson.D) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON(scanner.Bytes(), true, &doc); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,84 @@
+package bookpb
+
+import (
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/pricing"
+)
+
+// FromBook converts a book into its protobuf message.
+func FromBook(b books.Book) *Book {
+	return &Book{
+		Id:             b.ID,
+		Title:          b.BookName,
+		Author:         b.BookAuthor,
+		Edition:        b.BookEdition,
+		Pages:          b.BookPages,
+		Year:           b.BookYear,
+		Isbn:           b.ISBN,
+		Authors:        fromContributors(b.Contributors()),
+		Publisher:      b.Publisher,
+		Series:         b.Series,
+		Volume:         b.Volume,
+		Tags:           b.Tags,
+		Genres:         b.Genres,
+		Rating:         b.Rating,
+		ReviewCount:    int32(b.ReviewCount),
+		Stock:          int32(b.Stock),
+		ReorderLevel:   int32(b.ReorderLevel),
+		ListPrice:      b.ListPrice,
+		Currency:       b.Currency,
+		EffectivePrice: fromPrice(b.EffectivePrice),
+		Copies:         int32(b.Copies),
+		Available:      int32(b.Available),
+	}
+}
+
+func fromPrice(p *pricing.Price) *Price {
+	if p == nil {
+		return nil
+	}
+	return &Price{Amount: p.Amount, Currency: p.Currency, ListPrice: p.ListPrice, Rule: p.Rule}
+}
+
+func fromContributors(list []books.Contributor) []*Contributor {
+	ret := make([]*Contributor, 0, len(list))
+	for _, c := range list {
+		ret = append(ret, &Contributor{Name: c.Name, Role: c.Role, AuthorId: c.AuthorID})
+	}
+	return ret
+}
+
+// ToBook converts the message into a book. A nil message gives an empty
+// book. The rating, the stock, the effective price and the lending copies
+// are left out, requests cannot set them.
+func (m *Book) ToBook() books.Book {
+	if m == nil {
+		return books.Book{}
+	}
+	return books.Book{
+		ID:           m.Id,
+		BookName:     m.Title,
+		BookAuthor:   m.Author,
+		BookEdition:  m.Edition,
+		BookPages:    m.Pages,
+		BookYear:     m.Year,
+		ISBN:         m.Isbn,
+		Authors:      toContributors(m.Authors),
+		Publisher:    m.Publisher,
+		Series:       m.Series,
+		Volume:       m.Volume,
+		Tags:         m.Tags,
+		Genres:       m.Genres,
+		ReorderLevel: int(m.ReorderLevel),
+		ListPrice:    m.ListPrice,
+		Currency:     m.Currency,
+	}
+}
+
+func toContributors(list []*Contributor) []books.Contributor {
+	var ret []books.Contributor
+	for _, c := range list {
+		ret = append(ret, books.Contributor{Name: c.GetName(), Role: c.GetRole(), AuthorID: c.GetAuthorId()})
+	}
+	return ret
+}
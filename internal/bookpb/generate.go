@@ -0,0 +1,5 @@
+// Package bookpb contains the protobuf messages and gRPC stubs of the book
+// service, generated from bookstore.proto with gogo/protobuf.
+package bookpb
+
+//go:generate protoc --gogofaster_out=plugins=grpc,paths=source_relative:. bookstore.proto
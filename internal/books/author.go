@@ -0,0 +1,53 @@
+package books
+
+import "errors"
+
+// AuthorCollection holds the authors books can refer to.
+const AuthorCollection = "authors"
+
+// ErrAuthorNotFound is returned when no author has the requested id.
+var ErrAuthorNotFound = errors.New("author not found")
+
+// Author is a person with an entry of their own, which the contributors of
+// books refer to by ID. Like the fields of books, the years are strings.
+type Author struct {
+	ID   string `bson:"id" json:"id" validate:"required,max=64"`
+	Name string `bson:"name" json:"name" validate:"required,max=200"`
+	// SortName orders lists of authors, e.g. "Shelley, Mary". It defaults to
+	// the name.
+	SortName  string `bson:"sort_name" json:"sortName" validate:"max=200"`
+	BirthYear string `bson:"birth_year" json:"birthYear" validate:"year"`
+	DeathYear string `bson:"death_year" json:"deathYear" validate:"year"`
+	Bio       string `bson:"bio" json:"bio" validate:"max=5000"`
+}
+
+// API returns the author in the form used by the /api/authors endpoints.
+func (a Author) API() map[string]interface{} {
+	return map[string]interface{}{
+		"id":        a.ID,
+		"name":      a.Name,
+		"sortName":  a.SortName,
+		"birthYear": a.BirthYear,
+		"deathYear": a.DeathYear,
+		"bio":       a.Bio,
+	}
+}
+
+// Merge copies the non-empty fields of patch into a, except for the id.
+func (a *Author) Merge(patch Author) {
+	if patch.Name != "" {
+		a.Name = patch.Name
+	}
+	if patch.SortName != "" {
+		a.SortName = patch.SortName
+	}
+	if patch.BirthYear != "" {
+		a.BirthYear = patch.BirthYear
+	}
+	if patch.DeathYear != "" {
+		a.DeathYear = patch.DeathYear
+	}
+	if patch.Bio != "" {
+		a.Bio = patch.Bio
+	}
+}
@@ -0,0 +1,191 @@
+// Package books holds the book model shared by the monolith and the split
+// services, so every binary reads and writes the same document shape.
+package books
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/CAPS-Cloud/exercises/internal/pricing"
+)
+
+// Default location of the catalog.
+const (
+	Database   = "exercise-1"
+	Collection = "information"
+)
+
+// ErrNotFound is returned when no book has the requested id.
+var ErrNotFound = errors.New("book not found")
+
+// Book is a single catalog entry. The bson tags match the keys the services
+// already store in the "information" collection; the json, yaml and xml
+// tags match the shape of the REST API (id, title, author, ...).
+//
+// BookAuthor is kept next to Authors for the clients and services that only
+// know the single author string; NormalizeAuthors keeps the two in line.
+type Book struct {
+	ID          string `bson:"id" json:"id" yaml:"id" xml:"id"`
+	BookName    string `bson:"bookname" json:"title" yaml:"title" xml:"title"`
+	BookAuthor  string `bson:"bookauthor" json:"author" yaml:"author" xml:"author"`
+	BookEdition string `bson:"bookedition" json:"edition" yaml:"edition" xml:"edition"`
+	BookPages   string `bson:"bookpages" json:"pages" yaml:"pages" xml:"pages"`
+	BookYear    string `bson:"bookyear" json:"year" yaml:"year" xml:"year"`
+	// ISBN is stored as ISBN-13 without hyphens, see the isbn package.
+	ISBN string `bson:"isbn" json:"isbn" yaml:"isbn" xml:"isbn"`
+	// Authors lists everybody who worked on the book, in order.
+	Authors   []Contributor `bson:"authors" json:"authors" yaml:"authors" xml:"authors>contributor"`
+	Publisher string        `bson:"publisher" json:"publisher" yaml:"publisher" xml:"publisher"`
+	Series    string        `bson:"series" json:"series" yaml:"series" xml:"series"`
+	// Volume is the number of the book within its series.
+	Volume string `bson:"volume" json:"volume" yaml:"volume" xml:"volume"`
+	// Tags are free-form, genres come from the Genres list. Both are
+	// stored normalized, see NormalizeTags.
+	Tags   []string `bson:"tags" json:"tags" yaml:"tags" xml:"tags>tag"`
+	Genres []string `bson:"genres" json:"genres" yaml:"genres" xml:"genres>genre"`
+	// Rating is the average rating of the reviews of the book, 0 without
+	// any, and ReviewCount their number. The catalog updates both whenever
+	// a review is written; requests cannot set them.
+	Rating      float64 `bson:"rating,omitempty" json:"rating" yaml:"-" xml:"rating"`
+	ReviewCount int     `bson:"review_count,omitempty" json:"reviewCount" yaml:"-" xml:"reviewCount"`
+	// Stock is the number of copies on hand. Only stock adjustments change
+	// it, so it never goes below 0. A book is low on stock when Stock is at
+	// most ReorderLevel; 0 means it is never reordered.
+	Stock        int `bson:"stock,omitempty" json:"stock" yaml:"-" xml:"stock"`
+	ReorderLevel int `bson:"reorder_level" json:"reorderLevel" yaml:"reorderLevel" xml:"reorderLevel"`
+	// Copies is the number of physical copies the lending library has of
+	// the book, and Available the number of those on the shelf, neither on
+	// loan nor set aside for a hold. The catalog keeps both up to date,
+	// see the lending package.
+	Copies    int `bson:"copies,omitempty" json:"copies" yaml:"-" xml:"copies"`
+	Available int `bson:"available,omitempty" json:"available" yaml:"-" xml:"available"`
+	// ListPrice is an amount like "12.99" in Currency, both empty for books
+	// without a price. EffectivePrice is the price after the price rules;
+	// it is computed for the responses of the API and never stored, see
+	// catalog.Repository.Price.
+	ListPrice      string         `bson:"list_price" json:"listPrice" yaml:"listPrice" xml:"listPrice"`
+	Currency       string         `bson:"currency" json:"currency" yaml:"currency" xml:"currency"`
+	EffectivePrice *pricing.Price `bson:"-" json:"effectivePrice,omitempty" yaml:"-" xml:"effectivePrice,omitempty"`
+}
+
+// API returns the book in the form used by the /api/books endpoints.
+func (b Book) API() map[string]interface{} {
+	ret := map[string]interface{}{
+		"id":           b.ID,
+		"title":        b.BookName,
+		"author":       b.BookAuthor,
+		"pages":        b.BookPages,
+		"edition":      b.BookEdition,
+		"year":         b.BookYear,
+		"isbn":         b.ISBN,
+		"authors":      contributorsAPI(b.Contributors()),
+		"publisher":    b.Publisher,
+		"series":       b.Series,
+		"volume":       b.Volume,
+		"tags":         nonNil(b.Tags),
+		"genres":       nonNil(b.Genres),
+		"rating":       b.Rating,
+		"reviewCount":  b.ReviewCount,
+		"stock":        b.Stock,
+		"reorderLevel": b.ReorderLevel,
+		"copies":       b.Copies,
+		"available":    b.Available,
+		"listPrice":    b.ListPrice,
+		"currency":     b.Currency,
+	}
+	if b.EffectivePrice != nil {
+		ret["effectivePrice"] = b.EffectivePrice.API()
+	}
+	return ret
+}
+
+// nonNil makes missing lists encode as empty ones.
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+func contributorsAPI(list []Contributor) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(list))
+	for _, c := range list {
+		m := map[string]interface{}{"name": c.Name, "role": c.Role}
+		if c.AuthorID != "" {
+			m["authorId"] = c.AuthorID
+		}
+		ret = append(ret, m)
+	}
+	return ret
+}
+
+// Merge copies the non-empty fields of patch into b, except for the id. A
+// list of authors replaces the one of b, as does an author string; so do
+// lists of tags and genres.
+func (b *Book) Merge(patch Book) {
+	if patch.BookName != "" {
+		b.BookName = patch.BookName
+	}
+	if len(patch.Authors) > 0 || patch.BookAuthor != "" {
+		b.Authors = patch.Authors
+		b.BookAuthor = patch.BookAuthor
+		b.NormalizeAuthors()
+	}
+	if patch.BookEdition != "" {
+		b.BookEdition = patch.BookEdition
+	}
+	if patch.BookPages != "" {
+		b.BookPages = patch.BookPages
+	}
+	if patch.BookYear != "" {
+		b.BookYear = patch.BookYear
+	}
+	if patch.ISBN != "" {
+		b.ISBN = patch.ISBN
+	}
+	if patch.Publisher != "" {
+		b.Publisher = patch.Publisher
+	}
+	if patch.Series != "" {
+		b.Series = patch.Series
+	}
+	if patch.Volume != "" {
+		b.Volume = patch.Volume
+	}
+	if len(patch.Tags) > 0 {
+		b.Tags = patch.Tags
+	}
+	if len(patch.Genres) > 0 {
+		b.Genres = patch.Genres
+	}
+	if patch.ReorderLevel != 0 {
+		b.ReorderLevel = patch.ReorderLevel
+	}
+	if patch.ListPrice != "" {
+		b.ListPrice = patch.ListPrice
+	}
+	if patch.Currency != "" {
+		b.Currency = patch.Currency
+	}
+}
+
+// SortByVolume orders the books of a series by their volume number. Books
+// without a valid number come last, ties are ordered by title.
+func SortByVolume(list []Book) {
+	volume := func(b Book) int {
+		n, err := strconv.Atoi(b.Volume)
+		if err != nil || n <= 0 {
+			return math.MaxInt
+		}
+		return n
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		vi, vj := volume(list[i]), volume(list[j])
+		if vi != vj {
+			return vi < vj
+		}
+		return list[i].BookName < list[j].BookName
+	})
+}
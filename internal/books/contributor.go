@@ -0,0 +1,80 @@
+package books
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Roles of the contributors of a book.
+const (
+	RoleAuthor     = "author"
+	RoleEditor     = "editor"
+	RoleTranslator = "translator"
+)
+
+// Contributor is a person who worked on a book, in one of the roles above.
+// An empty role is read as RoleAuthor.
+//
+// AuthorID refers to an Author. Its name is then copied into Name, and kept
+// up to date when the author is renamed.
+type Contributor struct {
+	Name     string `bson:"name" json:"name" yaml:"name" xml:",chardata" msgpack:"name" validate:"required_without=authorId,max=200"`
+	Role     string `bson:"role" json:"role" yaml:"role,omitempty" xml:"role,attr,omitempty" msgpack:"role" validate:"oneof=author editor translator"`
+	AuthorID string `bson:"author_id,omitempty" json:"authorId,omitempty" yaml:"authorId,omitempty" xml:"authorId,attr,omitempty" msgpack:"authorId,omitempty" validate:"max=64"`
+}
+
+// authorSeparator splits strings like "Ahsanul Bari, Anupom Syam" or
+// "Kernighan and Ritchie" into names.
+var authorSeparator = regexp.MustCompile(`\s*(?:[,;&]|\band\b)\s*`)
+
+// SplitAuthors reads the single author string the API has always accepted
+// as a list of authors.
+func SplitAuthors(s string) []Contributor {
+	var ret []Contributor
+	for _, name := range authorSeparator.Split(s, -1) {
+		if name = strings.TrimSpace(name); name != "" {
+			ret = append(ret, Contributor{Name: name, Role: RoleAuthor})
+		}
+	}
+	return ret
+}
+
+// JoinAuthors is the author string of a list of contributors: the names of
+// the authors, or of everybody if nobody is listed as author.
+func JoinAuthors(list []Contributor) string {
+	var authors, all []string
+	for _, c := range list {
+		if c.Role == RoleAuthor || c.Role == "" {
+			authors = append(authors, c.Name)
+		}
+		all = append(all, c.Name)
+	}
+	if len(authors) == 0 {
+		authors = all
+	}
+	return strings.Join(authors, ", ")
+}
+
+// Contributors returns the contributors of b, also for books stored before
+// the list existed, which only have an author string.
+func (b Book) Contributors() []Contributor {
+	if len(b.Authors) == 0 {
+		return SplitAuthors(b.BookAuthor)
+	}
+	return b.Authors
+}
+
+// NormalizeAuthors brings the list and the author string in line. The list
+// wins if both are set; without it the author string is split into one.
+func (b *Book) NormalizeAuthors() {
+	list := b.Contributors()
+	b.Authors = make([]Contributor, 0, len(list))
+	for _, c := range list {
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Role == "" {
+			c.Role = RoleAuthor
+		}
+		b.Authors = append(b.Authors, c)
+	}
+	b.BookAuthor = JoinAuthors(b.Authors)
+}
@@ -0,0 +1,113 @@
+package books
+
+import (
+	"github.com/CAPS-Cloud/exercises/internal/isbn"
+	"github.com/CAPS-Cloud/exercises/internal/pricing"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+)
+
+func init() {
+	// ISBN-10 or ISBN-13 with a valid check digit, hyphens and spaces
+	// allowed.
+	validation.Register("isbn", func(value, _ string) string {
+		if _, err := isbn.Parse(value); err != nil {
+			return err.Error()
+		}
+		return ""
+	})
+}
+
+// Request is a book as clients send it to create or change it. Its
+// validate tags declare what a valid book is, and every way into the
+// catalog checks books against them: the REST, gRPC and GraphQL APIs, the
+// HTML form and the seed files.
+type Request struct {
+	ID      string `json:"id" xml:"id" msgpack:"id" validate:"required,max=64"`
+	Title   string `json:"title" xml:"title" msgpack:"title" validate:"required,max=300"`
+	Author  string `json:"author" xml:"author" msgpack:"author" validate:"required_without=authors,max=200"`
+	Edition string `json:"edition" xml:"edition" msgpack:"edition" validate:"max=100"`
+	Pages   string `json:"pages" xml:"pages" msgpack:"pages" validate:"positive"`
+	Year    string `json:"year" xml:"year" msgpack:"year" validate:"year"`
+	ISBN    string `json:"isbn" xml:"isbn" msgpack:"isbn" validate:"isbn"`
+	// Authors replaces Author when both are given. A new book needs one of
+	// them.
+	Authors   []Contributor `json:"authors" xml:"authors>contributor" msgpack:"authors" validate:"dive"`
+	Publisher string        `json:"publisher" xml:"publisher" msgpack:"publisher" validate:"max=200"`
+	Series    string        `json:"series" xml:"series" msgpack:"series" validate:"max=200"`
+	Volume    string        `json:"volume" xml:"volume" msgpack:"volume" validate:"positive"`
+	// Tags may be blank, they are dropped by NormalizeTags.
+	Tags   []string `json:"tags" xml:"tags>tag" msgpack:"tags" validate:"max=50"`
+	Genres []string `json:"genres" xml:"genres>genre" msgpack:"genres" validate:"genre"`
+	// ReorderLevel is the stock at which the book is reported as low on
+	// stock.
+	ReorderLevel int `json:"reorderLevel" xml:"reorderLevel" msgpack:"reorderLevel" validate:"positive"`
+	// Currency defaults to the base currency of the rates table when a
+	// list price is given.
+	ListPrice string `json:"listPrice" xml:"listPrice" msgpack:"listPrice" validate:"amount"`
+	Currency  string `json:"currency" xml:"currency" msgpack:"currency" validate:"currency"`
+	// Rating, ReviewCount, Stock, Copies, Available and EffectivePrice are
+	// accepted so that books read from the API can be sent back as they
+	// are, but they are ignored: the reviews, stock adjustments, copies and
+	// price rules decide them.
+	Rating         float64       `json:"rating" xml:"rating" msgpack:"rating"`
+	ReviewCount    int           `json:"reviewCount" xml:"reviewCount" msgpack:"reviewCount"`
+	Stock          int           `json:"stock" xml:"stock" msgpack:"stock"`
+	Copies         int           `json:"copies" xml:"copies" msgpack:"copies"`
+	Available      int           `json:"available" xml:"available" msgpack:"available"`
+	EffectivePrice pricing.Price `json:"effectivePrice" xml:"effectivePrice" msgpack:"effectivePrice"`
+}
+
+// NewRequest returns the request for b.
+func NewRequest(b Book) Request {
+	return Request{
+		ID:           b.ID,
+		Title:        b.BookName,
+		Author:       b.BookAuthor,
+		Edition:      b.BookEdition,
+		Pages:        b.BookPages,
+		Year:         b.BookYear,
+		ISBN:         b.ISBN,
+		Authors:      b.Authors,
+		Publisher:    b.Publisher,
+		Series:       b.Series,
+		Volume:       b.Volume,
+		Tags:         b.Tags,
+		Genres:       b.Genres,
+		ReorderLevel: b.ReorderLevel,
+		ListPrice:    b.ListPrice,
+		Currency:     b.Currency,
+	}
+}
+
+// Book returns the book of the request.
+func (r Request) Book() Book {
+	return Book{
+		ID:           r.ID,
+		BookName:     r.Title,
+		BookAuthor:   r.Author,
+		BookEdition:  r.Edition,
+		BookPages:    r.Pages,
+		BookYear:     r.Year,
+		ISBN:         r.ISBN,
+		Authors:      r.Authors,
+		Publisher:    r.Publisher,
+		Series:       r.Series,
+		Volume:       r.Volume,
+		Tags:         r.Tags,
+		Genres:       r.Genres,
+		ReorderLevel: r.ReorderLevel,
+		ListPrice:    r.ListPrice,
+		Currency:     r.Currency,
+	}
+}
+
+// Validate checks a new book. The error is a validation.Errors.
+func (r Request) Validate() error {
+	return validation.Struct(r)
+}
+
+// ValidatePatch checks the fields an update sets; it may leave out any of
+// them.
+func (r Request) ValidatePatch() error {
+	return validation.Partial(r)
+}
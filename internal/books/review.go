@@ -0,0 +1,56 @@
+package books
+
+import (
+	"errors"
+	"time"
+)
+
+// ReviewCollection holds the reviews of books.
+const ReviewCollection = "reviews"
+
+// ErrReviewNotFound is returned when a book has no review with the
+// requested id.
+var ErrReviewNotFound = errors.New("review not found")
+
+// Review is the rating of a book by a customer, from 1 to 5 stars, with an
+// optional text. The catalog keeps the average rating and the number of
+// reviews of every book in the book itself, see Book.Rating.
+type Review struct {
+	// ID and BookID are set by the catalog, like the times.
+	ID       string `bson:"id" json:"id"`
+	BookID   string `bson:"book_id" json:"bookId"`
+	Reviewer string `bson:"reviewer" json:"reviewer" validate:"required,max=100"`
+	Rating   int    `bson:"rating" json:"rating" validate:"required,between=1 5"`
+	Text     string `bson:"text" json:"text" validate:"max=5000"`
+
+	Created time.Time `bson:"created" json:"-"`
+	Updated time.Time `bson:"updated" json:"-"`
+}
+
+// API returns the review in the form used by the /api/books/:id/reviews
+// endpoints.
+func (r Review) API() map[string]interface{} {
+	return map[string]interface{}{
+		"id":       r.ID,
+		"bookId":   r.BookID,
+		"reviewer": r.Reviewer,
+		"rating":   r.Rating,
+		"text":     r.Text,
+		"created":  r.Created.UTC().Format(time.RFC3339),
+		"updated":  r.Updated.UTC().Format(time.RFC3339),
+	}
+}
+
+// Merge copies the rating, reviewer and text of patch into r where they are
+// set.
+func (r *Review) Merge(patch Review) {
+	if patch.Reviewer != "" {
+		r.Reviewer = patch.Reviewer
+	}
+	if patch.Rating != 0 {
+		r.Rating = patch.Rating
+	}
+	if patch.Text != "" {
+		r.Text = patch.Text
+	}
+}
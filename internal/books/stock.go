@@ -0,0 +1,37 @@
+package books
+
+import "time"
+
+// MovementCollection holds the stock movements of books, the history of
+// their stock.
+const MovementCollection = "stock_movements"
+
+// StockMovement is a single adjustment of the stock of a book: copies
+// received, sold, returned or written off.
+type StockMovement struct {
+	BookID string `bson:"book_id" json:"bookId"`
+	// Delta is added to the stock; negative deltas take copies out.
+	Delta  int    `bson:"delta" json:"delta" validate:"required"`
+	Reason string `bson:"reason" json:"reason" validate:"required,max=200"`
+	// Stock is the stock after the movement.
+	Stock int       `bson:"stock" json:"stock"`
+	Time  time.Time `bson:"time" json:"-"`
+}
+
+// API returns the movement in the form used by the /api/books/:id/stock
+// endpoints.
+func (m StockMovement) API() map[string]interface{} {
+	return map[string]interface{}{
+		"bookId": m.BookID,
+		"delta":  m.Delta,
+		"reason": m.Reason,
+		"stock":  m.Stock,
+		"time":   m.Time.UTC().Format(time.RFC3339),
+	}
+}
+
+// LowOnStock reports whether b has a reorder level and its stock is at or
+// below it.
+func (b Book) LowOnStock() bool {
+	return b.ReorderLevel > 0 && b.Stock <= b.ReorderLevel
+}
@@ -0,0 +1,52 @@
+package books
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+)
+
+// Genres is the controlled list of genres books are filed under. Unlike
+// tags, which are free-form, a book can only have genres of this list.
+var Genres = []string{
+	"biography",
+	"children",
+	"classics",
+	"crime",
+	"fantasy",
+	"fiction",
+	"history",
+	"horror",
+	"mystery",
+	"non-fiction",
+	"philosophy",
+	"poetry",
+	"romance",
+	"science",
+	"science-fiction",
+	"thriller",
+}
+
+func init() {
+	// Genres are checked before they are normalized.
+	validation.Register("genre", func(value, _ string) string {
+		if !slices.Contains(Genres, strings.ToLower(strings.TrimSpace(value))) {
+			return "must be one of " + strings.Join(Genres, ", ")
+		}
+		return ""
+	})
+}
+
+// NormalizeTags lowercases and trims tags and drops empty and repeated
+// ones, keeping the order of the rest. Genres are normalized the same way.
+func NormalizeTags(list []string) []string {
+	ret := make([]string, 0, len(list))
+	for _, tag := range list {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(ret, tag) {
+			ret = append(ret, tag)
+		}
+	}
+	return ret
+}
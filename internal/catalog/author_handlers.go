@@ -0,0 +1,125 @@
+package catalog
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/content"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/labstack/echo/v4"
+)
+
+// The REST handlers of /api/authors. Unlike /api/books they only speak
+// JSON, but accept the same JSON, MessagePack and form bodies.
+
+// RegisterAuthors adds the /api/authors endpoints to e. Only the monolith
+// and the root service serve them.
+func RegisterAuthors(e *echo.Echo, repo *Repository) {
+	e.GET("/api/authors", ListAuthorsHandler(repo))
+	e.GET("/api/authors/:id", GetAuthorHandler(repo))
+	e.POST("/api/authors", CreateAuthorHandler(repo))
+	e.PUT("/api/authors/:id", UpdateAuthorHandler(repo))
+	e.DELETE("/api/authors/:id", DeleteAuthorHandler(repo))
+}
+
+func authorsAPI(list []books.Author) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(list))
+	for _, a := range list {
+		ret = append(ret, a.API())
+	}
+	return ret
+}
+
+// ListAuthorsHandler serves GET /api/authors, ordered by sort name.
+func ListAuthorsHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := repo.ListAuthors(c.Request().Context())
+		if err != nil {
+			return problem.Internal("Failed to list authors", err)
+		}
+		return c.JSON(http.StatusOK, authorsAPI(list))
+	}
+}
+
+// GetAuthorHandler serves GET /api/authors/:id.
+func GetAuthorHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		author, err := repo.GetAuthor(c.Request().Context(), c.Param("id"))
+		if err == books.ErrAuthorNotFound {
+			return problem.NotFound("Author not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to get author", err)
+		}
+		return c.JSON(http.StatusOK, author.API())
+	}
+}
+
+// CreateAuthorHandler serves POST /api/authors. It answers 201 with the new
+// author, 400 with the fields in error for an invalid author and 409 if the
+// id is taken.
+func CreateAuthorHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var author books.Author
+		if err := content.Decode(c, &author); err != nil {
+			return invalidBody(err)
+		}
+
+		created, err := repo.CreateAuthor(c.Request().Context(), author)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == ErrAuthorConflict:
+			return problem.Conflict("An author with this ID already exists")
+		case err != nil:
+			return problem.Internal("Failed to create author", err)
+		}
+
+		return c.JSON(http.StatusCreated, created.API())
+	}
+}
+
+// UpdateAuthorHandler serves PUT /api/authors/:id. Only the fields present
+// and non-empty in the body are changed. A new name is copied into the books
+// of the author.
+func UpdateAuthorHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var patch books.Author
+		if err := content.Decode(c, &patch); err != nil {
+			return invalidBody(err)
+		}
+
+		author, err := repo.UpdateAuthor(c.Request().Context(), c.Param("id"), patch)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == books.ErrAuthorNotFound:
+			return problem.NotFound("Author not found")
+		case err != nil:
+			return problem.Internal("Failed to update author", err)
+		}
+
+		return c.JSON(http.StatusOK, author.API())
+	}
+}
+
+// DeleteAuthorHandler serves DELETE /api/authors/:id. It answers 409 while
+// books still refer to the author.
+func DeleteAuthorHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := repo.DeleteAuthor(c.Request().Context(), c.Param("id"))
+		switch {
+		case err == books.ErrAuthorNotFound:
+			return problem.NotFound("Author not found")
+		case err == ErrAuthorInUse:
+			return problem.Conflict("Books still refer to this author")
+		case err != nil:
+			return problem.Internal("Failed to delete author", err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
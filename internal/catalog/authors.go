@@ -0,0 +1,215 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Errors of the author methods.
+var (
+	ErrAuthorConflict = errors.New("an author with this ID already exists")
+	ErrAuthorInUse    = errors.New("the author is referred to by books")
+)
+
+// ValidateAuthor checks an author against the rules of its validate tags,
+// and that they did not die before they were born.
+func ValidateAuthor(a books.Author) error {
+	err := validation.Struct(a)
+	var fields validation.Errors
+	if err != nil && !errors.As(err, &fields) {
+		return err
+	}
+	birth, berr := strconv.Atoi(a.BirthYear)
+	death, derr := strconv.Atoi(a.DeathYear)
+	if berr == nil && derr == nil && death < birth {
+		fields = append(fields, validation.FieldError{Field: "deathYear", Message: "must not be before the birth year"})
+	}
+	if len(fields) > 0 {
+		return &ValidationError{"Invalid author", fields}
+	}
+	return nil
+}
+
+// ListAuthors returns every author, ordered by sort name.
+func (r *Repository) ListAuthors(ctx context.Context) ([]books.Author, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "sort_name", Value: 1}, {Key: "id", Value: 1}})
+	cursor, err := r.authors.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := []books.Author{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetAuthor returns the author with the given id.
+func (r *Repository) GetAuthor(ctx context.Context, id string) (*books.Author, error) {
+	var author books.Author
+	err := r.authors.FindOne(ctx, bson.M{"id": id}).Decode(&author)
+	if err == mongo.ErrNoDocuments {
+		return nil, books.ErrAuthorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// CreateAuthor validates and stores a new author, and returns it as stored.
+func (r *Repository) CreateAuthor(ctx context.Context, author books.Author) (*books.Author, error) {
+	if err := ValidateAuthor(author); err != nil {
+		return nil, err
+	}
+	if author.SortName == "" {
+		author.SortName = author.Name
+	}
+
+	count, err := r.authors.CountDocuments(ctx, bson.M{"id": author.ID})
+	if err != nil {
+		return nil, fmt.Errorf("check for existing author: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrAuthorConflict
+	}
+
+	err = r.box.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := r.authors.InsertOne(ctx, author); err != nil {
+			return err
+		}
+		return r.box.Add(ctx, events.AuthorCreated, author.API())
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrAuthorConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// UpdateAuthor applies the non-empty fields of patch to the author with the
+// given id. A new name is copied into every book referring to the author,
+// in the same transaction, and each of them gets a book.updated event.
+func (r *Repository) UpdateAuthor(ctx context.Context, id string, patch books.Author) (*books.Author, error) {
+	patch.ID = ""
+	author, err := r.GetAuthor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	oldName := author.Name
+	author.Merge(patch)
+	if err := ValidateAuthor(*author); err != nil {
+		return nil, err
+	}
+
+	err = r.box.Transaction(ctx, func(ctx context.Context) error {
+		result, err := r.authors.ReplaceOne(ctx, bson.M{"id": id}, author)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return books.ErrAuthorNotFound
+		}
+		if author.Name != oldName {
+			if err := r.renameAuthor(ctx, id, author.Name); err != nil {
+				return err
+			}
+		}
+		return r.box.Add(ctx, events.AuthorUpdated, author.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return author, nil
+}
+
+func (r *Repository) renameAuthor(ctx context.Context, id, name string) error {
+	cursor, err := r.coll.Find(ctx, bson.M{"authors.author_id": id})
+	if err != nil {
+		return err
+	}
+	var list []books.Book
+	if err := cursor.All(ctx, &list); err != nil {
+		return err
+	}
+
+	for _, book := range list {
+		for i := range book.Authors {
+			if book.Authors[i].AuthorID == id {
+				book.Authors[i].Name = name
+			}
+		}
+		book.NormalizeAuthors()
+		_, err := r.coll.UpdateOne(ctx,
+			bson.M{"id": book.ID},
+			bson.M{"$set": bson.M{"authors": book.Authors, "bookauthor": book.BookAuthor}},
+		)
+		if err != nil {
+			return err
+		}
+		if err := r.box.Add(ctx, events.BookUpdated, book.API()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteAuthor removes the author with the given id. Authors that books
+// still refer to are not removed, the error is then ErrAuthorInUse.
+func (r *Repository) DeleteAuthor(ctx context.Context, id string) error {
+	return r.box.Transaction(ctx, func(ctx context.Context) error {
+		count, err := r.coll.CountDocuments(ctx, bson.M{"authors.author_id": id})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAuthorInUse
+		}
+
+		result, err := r.authors.DeleteOne(ctx, bson.M{"id": id})
+		if err != nil {
+			return err
+		}
+		if result.DeletedCount == 0 {
+			return books.ErrAuthorNotFound
+		}
+		return r.box.Add(ctx, events.AuthorDeleted, map[string]interface{}{"id": id})
+	})
+}
+
+// resolveAuthors copies the names of the authors list refers to into it.
+func (r *Repository) resolveAuthors(ctx context.Context, list []books.Contributor) error {
+	var fields validation.Errors
+	for i := range list {
+		if list[i].AuthorID == "" {
+			continue
+		}
+		author, err := r.GetAuthor(ctx, list[i].AuthorID)
+		if err == books.ErrAuthorNotFound {
+			fields = append(fields, validation.FieldError{
+				Field:   fmt.Sprintf("authors[%d].authorId", i),
+				Message: "is not a known author",
+			})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		list[i].Name = author.Name
+	}
+	if len(fields) > 0 {
+		return &ValidationError{"Invalid book", fields}
+	}
+	return nil
+}
@@ -0,0 +1,352 @@
+// Package catalog reads and writes books. It is the code shared by every
+// API on top of the catalog, REST and gRPC alike: validation of new books,
+// duplicate checks, partial updates, and recording each change in the
+// outbox within the same transaction.
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"github.com/CAPS-Cloud/exercises/internal/isbn"
+	"github.com/CAPS-Cloud/exercises/internal/lending"
+	"github.com/CAPS-Cloud/exercises/internal/orders"
+	"github.com/CAPS-Cloud/exercises/internal/outbox"
+	"github.com/CAPS-Cloud/exercises/internal/pricing"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrConflict is returned when creating a book whose id is already taken.
+var ErrConflict = errors.New("a book with this ID already exists")
+
+// ValidationError describes a book that cannot be stored.
+type ValidationError struct {
+	Message string
+	// Fields tells what is wrong with each field, by its API name.
+	Fields validation.Errors
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message + ": " + e.Fields.Error()
+}
+
+// Validate checks a new book against the rules of books.Request.
+func Validate(b books.Book) error {
+	return invalid(books.NewRequest(b).Validate())
+}
+
+// ValidatePatch checks the fields set by an update.
+func ValidatePatch(patch books.Book) error {
+	return invalid(books.NewRequest(patch).ValidatePatch())
+}
+
+func invalid(err error) error {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return &ValidationError{"Invalid book", fields}
+	}
+	return err
+}
+
+// Repository gives access to the books of a database.
+type Repository struct {
+	coll    *mongo.Collection
+	authors *mongo.Collection
+	reviews *mongo.Collection
+	// movements records the stock adjustments of books.
+	movements *mongo.Collection
+	rules     *mongo.Collection
+	rates     pricing.Rates
+	carts     *mongo.Collection
+	orders    *mongo.Collection
+	// copies, loans and holds are the lending library.
+	copies *mongo.Collection
+	loans  *mongo.Collection
+	holds  *mongo.Collection
+	box    *outbox.Outbox
+}
+
+// New returns the repository of the catalog collection of db. Prices are
+// converted with the built-in rates table until SetRates replaces it.
+func New(db *mongo.Database) *Repository {
+	return &Repository{
+		coll:      db.Collection(books.Collection),
+		authors:   db.Collection(books.AuthorCollection),
+		reviews:   db.Collection(books.ReviewCollection),
+		movements: db.Collection(books.MovementCollection),
+		rules:     db.Collection(pricing.RuleCollection),
+		rates:     pricing.DefaultRates(),
+		carts:     db.Collection(orders.CartCollection),
+		orders:    db.Collection(orders.OrderCollection),
+		copies:    db.Collection(lending.CopyCollection),
+		loans:     db.Collection(lending.LoanCollection),
+		holds:     db.Collection(lending.HoldCollection),
+		box:       outbox.New(db),
+	}
+}
+
+// NewFromEnv returns the repository of db configured from the environment,
+// as every service uses it: prices are converted offline with the rates of
+// PRICING_RATES_FILE, or the built-in table. It panics if the rates cannot
+// be loaded.
+func NewFromEnv(db *mongo.Database) *Repository {
+	repo := New(db)
+	repo.SetRates(pricing.MustRatesFromEnv())
+	return repo
+}
+
+// Outbox returns the outbox the repository records its changes in.
+func (r *Repository) Outbox() *outbox.Outbox {
+	return r.box
+}
+
+// List returns every book.
+func (r *Repository) List(ctx context.Context) ([]books.Book, error) {
+	cursor, err := r.coll.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	ret := []books.Book{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Order is an order of the book list, as given in the sort query
+// parameter of GET /api/books.
+type Order string
+
+// Orders of the book list.
+const (
+	// OrderAdded lists the books in the order they were added.
+	OrderAdded Order = ""
+	// OrderRating lists the best rated books first. Of two books with the
+	// same rating the one with more reviews comes first.
+	OrderRating Order = "rating"
+)
+
+// ParseOrder reads the sort query parameter.
+func ParseOrder(s string) (Order, error) {
+	switch o := Order(s); o {
+	case OrderAdded, OrderRating:
+		return o, nil
+	}
+	return "", &ValidationError{"Invalid query parameter", validation.Errors{
+		{Field: "sort", Message: "must be rating"},
+	}}
+}
+
+func (o Order) sort() bson.D {
+	if o == OrderRating {
+		return bson.D{{Key: "rating", Value: -1}, {Key: "review_count", Value: -1}, {Key: "_id", Value: 1}}
+	}
+	return bson.D{{Key: "_id", Value: 1}}
+}
+
+// Sorted returns every book in the given order.
+func (r *Repository) Sorted(ctx context.Context, order Order) ([]books.Book, error) {
+	cursor, err := r.coll.Find(ctx, bson.D{}, options.Find().SetSort(order.sort()))
+	if err != nil {
+		return nil, err
+	}
+	ret := []books.Book{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Page returns up to limit books after skipping offset of them, in the given
+// order, and the number of books in total.
+func (r *Repository) Page(ctx context.Context, offset, limit int64, order Order) ([]books.Book, int64, error) {
+	total, err := r.coll.CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(order.sort()).SetSkip(offset).SetLimit(limit)
+	cursor, err := r.coll.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	ret := []books.Book{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, 0, err
+	}
+	return ret, total, nil
+}
+
+// FindByISBN returns the books with the given ISBN, in any of its forms.
+func (r *Repository) FindByISBN(ctx context.Context, s string) ([]books.Book, error) {
+	n, err := isbn.Parse(s)
+	if err != nil {
+		return nil, &ValidationError{"Invalid ISBN", validation.Errors{{Field: "isbn", Message: err.Error()}}}
+	}
+
+	cursor, err := r.coll.Find(ctx, bson.M{"isbn": n.String()})
+	if err != nil {
+		return nil, err
+	}
+	ret := []books.Book{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Get returns the book with the given id (not the MongoID).
+func (r *Repository) Get(ctx context.Context, id string) (*books.Book, error) {
+	var book books.Book
+	err := r.coll.FindOne(ctx, bson.M{"id": id}).Decode(&book)
+	if err == mongo.ErrNoDocuments {
+		return nil, books.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// Create validates and stores a new book, and returns it as stored: with
+// its ISBN, tags, genres and price normalized, its authors as a list, see
+// books.Book.NormalizeAuthors, and without reviews, stock or copies.
+func (r *Repository) Create(ctx context.Context, book books.Book) (*books.Book, error) {
+	if err := Validate(book); err != nil {
+		return nil, err
+	}
+	book.ISBN = isbn.Normalize(book.ISBN)
+	book.Tags = books.NormalizeTags(book.Tags)
+	book.Genres = books.NormalizeTags(book.Genres)
+	book.Rating, book.ReviewCount, book.Stock = 0, 0, 0
+	book.Copies, book.Available = 0, 0
+	book.EffectivePrice = nil
+	if err := r.normalizePrice(&book); err != nil {
+		return nil, err
+	}
+	if err := r.resolveAuthors(ctx, book.Authors); err != nil {
+		return nil, err
+	}
+	book.NormalizeAuthors()
+
+	count, err := r.coll.CountDocuments(ctx, bson.M{"id": book.ID})
+	if err != nil {
+		return nil, fmt.Errorf("check for existing book: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrConflict
+	}
+
+	err = r.box.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := r.coll.InsertOne(ctx, book); err != nil {
+			return err
+		}
+		return r.box.Add(ctx, events.BookCreated, book.API())
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// Update applies the non-empty fields of patch to the book with the given
+// id and returns the updated book. The id itself never changes, so the id of
+// patch is ignored.
+func (r *Repository) Update(ctx context.Context, id string, patch books.Book) (*books.Book, error) {
+	patch.ID = ""
+	if err := ValidatePatch(patch); err != nil {
+		return nil, err
+	}
+	patch.ISBN = isbn.Normalize(patch.ISBN)
+	patch.Tags = books.NormalizeTags(patch.Tags)
+	patch.Genres = books.NormalizeTags(patch.Genres)
+	if err := r.resolveAuthors(ctx, patch.Authors); err != nil {
+		return nil, err
+	}
+
+	book, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	book.Merge(patch)
+	if err := r.normalizePrice(book); err != nil {
+		return nil, err
+	}
+	fields, err := editable(*book)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.box.Transaction(ctx, func(ctx context.Context) error {
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err := r.coll.FindOneAndUpdate(ctx, bson.M{"id": id}, bson.M{"$set": fields}, opts).Decode(book)
+		if err == mongo.ErrNoDocuments {
+			return books.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return r.box.Add(ctx, events.BookUpdated, book.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// maintained are the fields of books the catalog keeps up to date itself,
+// with atomic updates of their own.
+var maintained = []string{"rating", "review_count", "stock", "copies", "available"}
+
+// editable returns the fields of b that requests change, for a $set that
+// leaves the maintained fields alone. Writing them back from a copy read
+// earlier would undo concurrent reviews, stock adjustments and loans.
+func editable(b books.Book) (bson.M, error) {
+	data, err := bson.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	var fields bson.M
+	if err := bson.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range maintained {
+		delete(fields, name)
+	}
+	return fields, nil
+}
+
+// Delete removes the book with the given id, its reviews, its stock
+// movements and its copies with their loans and holds.
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	return r.box.Transaction(ctx, func(ctx context.Context) error {
+		result, err := r.coll.DeleteOne(ctx, bson.M{"id": id})
+		if err != nil {
+			return err
+		}
+		if result.DeletedCount == 0 {
+			return books.ErrNotFound
+		}
+		if _, err := r.reviews.DeleteMany(ctx, bson.M{"book_id": id}); err != nil {
+			return err
+		}
+		if _, err := r.movements.DeleteMany(ctx, bson.M{"book_id": id}); err != nil {
+			return err
+		}
+		for _, coll := range []*mongo.Collection{r.copies, r.loans, r.holds} {
+			if _, err := coll.DeleteMany(ctx, bson.M{"book_id": id}); err != nil {
+				return err
+			}
+		}
+		return r.box.Add(ctx, events.BookDeleted, map[string]interface{}{"id": id})
+	})
+}
@@ -0,0 +1,67 @@
+package catalog
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Count is a value of a book field with the number of books having it.
+type Count struct {
+	Name  string `bson:"_id" json:"name"`
+	Books int    `bson:"books" json:"books"`
+}
+
+// Publishers returns every publisher with the number of their books,
+// ordered by name.
+func (r *Repository) Publishers(ctx context.Context) ([]Count, error) {
+	return r.count(ctx, "publisher")
+}
+
+// Series returns every series with the number of its books, ordered by
+// name.
+func (r *Repository) Series(ctx context.Context) ([]Count, error) {
+	return r.count(ctx, "series")
+}
+
+// count groups the books by the non-empty values of field.
+func (r *Repository) count(ctx context.Context, field string) ([]Count, error) {
+	cursor, err := r.coll.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{field: bson.M{"$nin": bson.A{"", nil}}}},
+		bson.M{"$group": bson.M{"_id": "$" + field, "books": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.M{"_id": 1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := []Count{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// PublishersHandler serves GET /api/publishers.
+func PublishersHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := repo.Publishers(c.Request().Context())
+		if err != nil {
+			return problem.Internal("Failed to list publishers", err)
+		}
+		return c.JSON(http.StatusOK, list)
+	}
+}
+
+// SeriesHandler serves GET /api/series.
+func SeriesHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := repo.Series(c.Request().Context())
+		if err != nil {
+			return problem.Internal("Failed to list series", err)
+		}
+		return c.JSON(http.StatusOK, list)
+	}
+}
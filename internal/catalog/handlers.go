@@ -0,0 +1,194 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/content"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+// The REST handlers of /api/books. The monolith registers all of them, each
+// split service only its own. Every handler negotiates the content type of
+// its request and response, see the content package, and returns its errors
+// as problem details, see the problem package. Books with a list price are
+// answered with their effective price, converted to the currency of the
+// currency query parameter if there is one.
+
+// ListHandler serves GET /api/books. Without query parameters it returns
+// every book. With limit, and optionally offset, it returns one page of them
+// and the total number of books in the X-Total-Count header. sort=rating
+// orders either by rating, best first. With isbn it returns the books with
+// that ISBN, given as ISBN-10 or ISBN-13.
+func ListHandler(repo *Repository) echo.HandlerFunc {
+	return content.Negotiate(func(c echo.Context) error {
+		if q := c.QueryParam("isbn"); q != "" {
+			list, err := repo.FindByISBN(c.Request().Context(), q)
+			var invalid *ValidationError
+			switch {
+			case errors.As(err, &invalid):
+				return problem.Validation(invalid.Message, invalid.Fields...)
+			case err != nil:
+				return problem.Internal("Failed to find books", err)
+			}
+			return writeBooks(c, repo, http.StatusOK, list)
+		}
+
+		order, err := ParseOrder(c.QueryParam("sort"))
+		var invalid *ValidationError
+		if errors.As(err, &invalid) {
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		}
+
+		if c.QueryParam("limit") == "" && c.QueryParam("offset") == "" {
+			list, err := repo.Sorted(c.Request().Context(), order)
+			if err != nil {
+				return problem.Internal("Failed to list books", err)
+			}
+			return writeBooks(c, repo, http.StatusOK, list)
+		}
+
+		offset, err := queryInt(c, "offset", 0)
+		if err != nil {
+			return problem.Validation("Invalid query parameter",
+				problem.FieldError{Field: "offset", Message: "must be a non-negative number"})
+		}
+		limit, err := queryInt(c, "limit", DefaultPageSize)
+		if err != nil || limit == 0 {
+			return problem.Validation("Invalid query parameter",
+				problem.FieldError{Field: "limit", Message: "must be a positive number"})
+		}
+		if limit > MaxPageSize {
+			limit = MaxPageSize
+		}
+
+		list, total, err := repo.Page(c.Request().Context(), offset, limit, order)
+		if err != nil {
+			return problem.Internal("Failed to list books", err)
+		}
+		c.Response().Header().Set(TotalCountHeader, strconv.FormatInt(total, 10))
+		return writeBooks(c, repo, http.StatusOK, list)
+	})
+}
+
+// Page sizes of GET /api/books when paginated.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// TotalCountHeader carries the number of books of a paginated list.
+const TotalCountHeader = "X-Total-Count"
+
+// queryInt reads a non-negative number from the query string.
+func queryInt(c echo.Context, name string, def int64) (int64, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	return n, nil
+}
+
+// GetHandler serves GET /api/books/:id.
+func GetHandler(repo *Repository) echo.HandlerFunc {
+	return content.Negotiate(func(c echo.Context) error {
+		book, err := repo.Get(c.Request().Context(), c.Param("id"))
+		if err == books.ErrNotFound {
+			return problem.NotFound("Book not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to get book", err)
+		}
+		return writeBook(c, repo, http.StatusOK, *book)
+	})
+}
+
+// CreateHandler serves POST /api/books. It answers 201 with the new book,
+// 400 with the fields in error for an invalid book and 409 if the id is
+// taken.
+func CreateHandler(repo *Repository) echo.HandlerFunc {
+	return content.Negotiate(func(c echo.Context) error {
+		book, err := content.DecodeBook(c)
+		if err != nil {
+			return invalidBody(err)
+		}
+		if err := checkCurrency(c, repo); err != nil {
+			return err
+		}
+
+		created, err := repo.Create(c.Request().Context(), book)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == ErrConflict:
+			return problem.Conflict("A book with this ID already exists")
+		case err != nil:
+			return problem.Internal("Failed to create book", err)
+		}
+
+		return writeBook(c, repo, http.StatusCreated, *created)
+	})
+}
+
+// UpdateHandler serves PUT /api/books/:id. Only the fields present and
+// non-empty in the body are changed, and only those are validated.
+func UpdateHandler(repo *Repository) echo.HandlerFunc {
+	return content.Negotiate(func(c echo.Context) error {
+		patch, err := content.DecodeBook(c)
+		if err != nil {
+			return invalidBody(err)
+		}
+
+		_, err = repo.Update(c.Request().Context(), c.Param("id"), patch)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == books.ErrNotFound:
+			return problem.NotFound("Book not found")
+		case err != nil:
+			return problem.Internal("Failed to update book", err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	})
+}
+
+// invalidBody is the problem of a body DecodeBook cannot read, listing the
+// unknown fields and those of the wrong type.
+func invalidBody(err error) error {
+	var unsupported *content.UnsupportedError
+	if errors.As(err, &unsupported) {
+		return problem.New(http.StatusUnsupportedMediaType,
+			"Supported types: "+strings.Join(unsupported.Supported, ", "))
+	}
+	var fields validation.Errors
+	errors.As(err, &fields)
+	return problem.Validation("Invalid request body", fields...)
+}
+
+// DeleteHandler serves DELETE /api/books/:id.
+func DeleteHandler(repo *Repository) echo.HandlerFunc {
+	return content.Negotiate(func(c echo.Context) error {
+		err := repo.Delete(c.Request().Context(), c.Param("id"))
+		if err == books.ErrNotFound {
+			return problem.NotFound("Book not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to delete book", err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	})
+}
@@ -0,0 +1,470 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"github.com/CAPS-Cloud/exercises/internal/lending"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// normalizeBarcode writes barcodes in upper case without surrounding space,
+// so a scanned and a typed barcode are the same.
+func normalizeBarcode(barcode string) string {
+	return strings.ToUpper(strings.TrimSpace(barcode))
+}
+
+func invalidLending(message string, err error) error {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return &ValidationError{message, fields}
+	}
+	return err
+}
+
+// countCopies adds to the number of copies of a book and to the number of
+// those available, and records the change of the book. Call it inside a
+// transaction with the context passed to the transaction function.
+func (r *Repository) countCopies(ctx context.Context, bookID string, copies, available int) error {
+	var book books.Book
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	update := bson.M{"$inc": bson.M{"copies": copies, "available": available}}
+	err := r.coll.FindOneAndUpdate(ctx, bson.M{"id": bookID}, update, opts).Decode(&book)
+	if err == mongo.ErrNoDocuments {
+		return books.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return r.box.Add(ctx, events.BookUpdated, book.API())
+}
+
+// shelve finds the place of a copy coming back to the library: set aside
+// for the oldest waiting hold of its book, or available if nobody waits for
+// it. It returns the copy as shelved. Call it inside a transaction.
+func (r *Repository) shelve(ctx context.Context, c lending.Copy) (*lending.Copy, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	var hold lending.Hold
+	err := r.holds.FindOneAndUpdate(ctx,
+		bson.M{"book_id": c.BookID, "status": lending.HoldWaiting},
+		bson.M{"$set": bson.M{"status": lending.HoldReady, "barcode": c.Barcode, "updated": now}},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "placed", Value: 1}, {Key: "id", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&hold)
+	switch {
+	case err == mongo.ErrNoDocuments:
+		c.Status, c.Borrower = lending.CopyAvailable, ""
+	case err != nil:
+		return nil, err
+	default:
+		c.Status, c.Borrower = lending.CopyOnHold, hold.Borrower
+	}
+
+	update := bson.M{"$set": bson.M{"status": c.Status, "borrower": c.Borrower}}
+	if _, err := r.copies.UpdateOne(ctx, bson.M{"barcode": c.Barcode}, update); err != nil {
+		return nil, err
+	}
+	if c.Status == lending.CopyAvailable {
+		return &c, r.countCopies(ctx, c.BookID, 0, 1)
+	}
+	return &c, r.box.Add(ctx, events.HoldReady, hold.API())
+}
+
+// Copies returns the copies of the book with the given id, ordered by
+// barcode.
+func (r *Repository) Copies(ctx context.Context, bookID string) ([]lending.Copy, error) {
+	if _, err := r.Get(ctx, bookID); err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "barcode", Value: 1}})
+	cursor, err := r.copies.Find(ctx, bson.M{"book_id": bookID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := []lending.Copy{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetCopy returns the copy with the given barcode.
+func (r *Repository) GetCopy(ctx context.Context, barcode string) (*lending.Copy, error) {
+	var c lending.Copy
+	err := r.copies.FindOne(ctx, bson.M{"barcode": normalizeBarcode(barcode)}).Decode(&c)
+	if err == mongo.ErrNoDocuments {
+		return nil, lending.ErrCopyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// AddCopy adds a copy of the book with the given id to the library and
+// shelves it: the oldest waiting hold of the book gets it, or it becomes
+// available.
+func (r *Repository) AddCopy(ctx context.Context, bookID, barcode string) (*lending.Copy, error) {
+	if err := invalidLending("Invalid copy", validation.Struct(lending.CopyRequest{Barcode: barcode})); err != nil {
+		return nil, err
+	}
+	c := lending.Copy{
+		Barcode: normalizeBarcode(barcode),
+		BookID:  bookID,
+		Added:   time.Now().UTC().Truncate(time.Millisecond),
+	}
+	count, err := r.copies.CountDocuments(ctx, bson.M{"barcode": c.Barcode})
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, lending.ErrBarcodeTaken
+	}
+
+	var shelved *lending.Copy
+	err = r.box.Transaction(ctx, func(ctx context.Context) error {
+		// Counting first fails for unknown books before anything is written.
+		if err := r.countCopies(ctx, bookID, 1, 0); err != nil {
+			return err
+		}
+		if _, err := r.copies.InsertOne(ctx, c); err != nil {
+			return err
+		}
+		if err := r.box.Add(ctx, events.CopyAdded, c.API()); err != nil {
+			return err
+		}
+		var err error
+		shelved, err = r.shelve(ctx, c)
+		return err
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, lending.ErrBarcodeTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return shelved, nil
+}
+
+// RemoveCopy takes a copy out of the library, e.g. when it is lost. Copies
+// on loan have to be returned first; the hold a copy was set aside for goes
+// back to waiting, keeping its place in the queue.
+func (r *Repository) RemoveCopy(ctx context.Context, barcode string) error {
+	barcode = normalizeBarcode(barcode)
+	return r.box.Transaction(ctx, func(ctx context.Context) error {
+		var c lending.Copy
+		filter := bson.M{"barcode": barcode, "status": bson.M{"$ne": lending.CopyOnLoan}}
+		err := r.copies.FindOneAndDelete(ctx, filter).Decode(&c)
+		if err == mongo.ErrNoDocuments {
+			if _, err := r.GetCopy(ctx, barcode); err != nil {
+				return err
+			}
+			return lending.ErrOnLoan
+		}
+		if err != nil {
+			return err
+		}
+
+		available := 0
+		switch c.Status {
+		case lending.CopyAvailable:
+			available = -1
+		case lending.CopyOnHold:
+			_, err := r.holds.UpdateOne(ctx,
+				bson.M{"book_id": c.BookID, "status": lending.HoldReady, "barcode": c.Barcode},
+				bson.M{"$set": bson.M{"status": lending.HoldWaiting, "barcode": "", "updated": time.Now().UTC().Truncate(time.Millisecond)}},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		if err := r.countCopies(ctx, c.BookID, -1, available); err != nil && err != books.ErrNotFound {
+			return err
+		}
+		return r.box.Add(ctx, events.CopyRemoved, c.API())
+	})
+}
+
+// Lend lends the copy with the given barcode to a borrower for the days
+// of req, or lending.LoanPeriod. The copy has to be available or set aside
+// for that borrower, whose hold is then fulfilled; otherwise Lend fails
+// with lending.ErrUnavailable. The check and the change are a single atomic
+// update, so a copy is never lent twice.
+func (r *Repository) Lend(ctx context.Context, barcode string, req lending.CheckoutRequest) (*lending.Loan, error) {
+	req.Borrower = strings.TrimSpace(req.Borrower)
+	if err := invalidLending("Invalid checkout", validation.Struct(req)); err != nil {
+		return nil, err
+	}
+	barcode = normalizeBarcode(barcode)
+	period := lending.LoanPeriod
+	if req.Days != 0 {
+		period = time.Duration(req.Days) * 24 * time.Hour
+	}
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	loan := lending.Loan{
+		ID:       primitive.NewObjectID().Hex(),
+		Barcode:  barcode,
+		Borrower: req.Borrower,
+		Borrowed: now,
+		Due:      now.Add(period),
+	}
+
+	err := r.box.Transaction(ctx, func(ctx context.Context) error {
+		var c lending.Copy
+		filter := bson.M{"barcode": barcode, "$or": bson.A{
+			bson.M{"status": lending.CopyAvailable},
+			bson.M{"status": lending.CopyOnHold, "borrower": req.Borrower},
+		}}
+		update := bson.M{"$set": bson.M{"status": lending.CopyOnLoan, "borrower": req.Borrower}}
+		err := r.copies.FindOneAndUpdate(ctx, filter, update).Decode(&c)
+		if err == mongo.ErrNoDocuments {
+			if _, err := r.GetCopy(ctx, barcode); err != nil {
+				return err
+			}
+			return lending.ErrUnavailable
+		}
+		if err != nil {
+			return err
+		}
+		loan.BookID = c.BookID
+
+		if c.Status == lending.CopyAvailable {
+			if err := r.countCopies(ctx, c.BookID, 0, -1); err != nil {
+				return err
+			}
+		} else {
+			_, err := r.holds.UpdateOne(ctx,
+				bson.M{"book_id": c.BookID, "status": lending.HoldReady, "barcode": barcode},
+				bson.M{"$set": bson.M{"status": lending.HoldFulfilled, "updated": now}},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := r.loans.InsertOne(ctx, loan); err != nil {
+			return err
+		}
+		return r.box.Add(ctx, events.LoanCreated, loan.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// Return closes the open loan of the copy with the given barcode and
+// shelves the copy, see AddCopy. It returns the loan and the copy as
+// shelved, and lending.ErrNotOnLoan for copies that are not lent out.
+func (r *Repository) Return(ctx context.Context, barcode string) (*lending.Loan, *lending.Copy, error) {
+	barcode = normalizeBarcode(barcode)
+	var loan lending.Loan
+	var shelved *lending.Copy
+	err := r.box.Transaction(ctx, func(ctx context.Context) error {
+		c, err := r.GetCopy(ctx, barcode)
+		if err != nil {
+			return err
+		}
+		if c.Status != lending.CopyOnLoan {
+			return lending.ErrNotOnLoan
+		}
+
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = r.loans.FindOneAndUpdate(ctx,
+			bson.M{"barcode": barcode, "returned": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"returned": now}},
+			opts,
+		).Decode(&loan)
+		if err == mongo.ErrNoDocuments {
+			return lending.ErrNotOnLoan
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.box.Add(ctx, events.LoanReturned, loan.API()); err != nil {
+			return err
+		}
+		shelved, err = r.shelve(ctx, *c)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &loan, shelved, nil
+}
+
+// Loans returns the open loans, only those of borrower if it is not empty,
+// the earliest due first.
+func (r *Repository) Loans(ctx context.Context, borrower string) ([]lending.Loan, error) {
+	filter := bson.M{"returned": bson.M{"$exists": false}}
+	if borrower != "" {
+		filter["borrower"] = strings.TrimSpace(borrower)
+	}
+	return r.findLoans(ctx, filter)
+}
+
+// Overdue returns the open loans due before now, the longest overdue first.
+func (r *Repository) Overdue(ctx context.Context, now time.Time) ([]lending.Loan, error) {
+	return r.findLoans(ctx, bson.M{"returned": bson.M{"$exists": false}, "due": bson.M{"$lt": now}})
+}
+
+func (r *Repository) findLoans(ctx context.Context, filter bson.M) ([]lending.Loan, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "due", Value: 1}, {Key: "id", Value: 1}})
+	cursor, err := r.loans.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := []lending.Loan{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Holds returns the open holds of the book with the given id in the order
+// of the queue: ready holds, then the waiting ones, each oldest first.
+func (r *Repository) Holds(ctx context.Context, bookID string) ([]lending.Hold, error) {
+	if _, err := r.Get(ctx, bookID); err != nil {
+		return nil, err
+	}
+	filter := bson.M{"book_id": bookID, "status": bson.M{"$in": bson.A{lending.HoldReady, lending.HoldWaiting}}}
+	// "ready" sorts before "waiting".
+	opts := options.Find().SetSort(bson.D{{Key: "status", Value: 1}, {Key: "placed", Value: 1}, {Key: "id", Value: 1}})
+	cursor, err := r.holds.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := []lending.Hold{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetHold returns the hold with the given id.
+func (r *Repository) GetHold(ctx context.Context, id string) (*lending.Hold, error) {
+	var hold lending.Hold
+	err := r.holds.FindOne(ctx, bson.M{"id": id}).Decode(&hold)
+	if err == mongo.ErrNoDocuments {
+		return nil, lending.ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// PlaceHold queues a borrower for the next copy of the book with the given
+// id. If a copy is available it is set aside for them right away and the
+// hold is ready. A borrower can hold a book only once at a time.
+func (r *Repository) PlaceHold(ctx context.Context, bookID string, req lending.HoldRequest) (*lending.Hold, error) {
+	req.Borrower = strings.TrimSpace(req.Borrower)
+	if err := invalidLending("Invalid hold", validation.Struct(req)); err != nil {
+		return nil, err
+	}
+	if _, err := r.Get(ctx, bookID); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	hold := lending.Hold{
+		ID:       primitive.NewObjectID().Hex(),
+		BookID:   bookID,
+		Borrower: req.Borrower,
+		Status:   lending.HoldWaiting,
+		Placed:   now,
+		Updated:  now,
+	}
+
+	err := r.box.Transaction(ctx, func(ctx context.Context) error {
+		count, err := r.holds.CountDocuments(ctx, bson.M{
+			"book_id":  bookID,
+			"borrower": req.Borrower,
+			"status":   bson.M{"$in": bson.A{lending.HoldWaiting, lending.HoldReady}},
+		})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return lending.ErrDuplicateHold
+		}
+
+		var c lending.Copy
+		err = r.copies.FindOneAndUpdate(ctx,
+			bson.M{"book_id": bookID, "status": lending.CopyAvailable},
+			bson.M{"$set": bson.M{"status": lending.CopyOnHold, "borrower": req.Borrower}},
+			options.FindOneAndUpdate().SetSort(bson.D{{Key: "barcode", Value: 1}}),
+		).Decode(&c)
+		switch {
+		case err == mongo.ErrNoDocuments:
+		case err != nil:
+			return err
+		default:
+			hold.Status, hold.Barcode = lending.HoldReady, c.Barcode
+			if err := r.countCopies(ctx, bookID, 0, -1); err != nil {
+				return err
+			}
+		}
+
+		if _, err := r.holds.InsertOne(ctx, hold); err != nil {
+			return err
+		}
+		if hold.Status == lending.HoldReady {
+			return r.box.Add(ctx, events.HoldReady, hold.API())
+		}
+		return r.box.Add(ctx, events.HoldPlaced, hold.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// CancelHold cancels the hold with the given id. The copy set aside for a
+// ready hold is shelved again, see AddCopy; holds fulfilled or cancelled
+// before fail with lending.ErrHoldClosed.
+func (r *Repository) CancelHold(ctx context.Context, id string) (*lending.Hold, error) {
+	var hold lending.Hold
+	err := r.box.Transaction(ctx, func(ctx context.Context) error {
+		// The hold as it was, to know whether it had a copy.
+		err := r.holds.FindOneAndUpdate(ctx,
+			bson.M{"id": id, "status": bson.M{"$in": bson.A{lending.HoldWaiting, lending.HoldReady}}},
+			bson.M{"$set": bson.M{"status": lending.HoldCancelled, "updated": time.Now().UTC().Truncate(time.Millisecond)}},
+		).Decode(&hold)
+		if err == mongo.ErrNoDocuments {
+			if _, err := r.GetHold(ctx, id); err != nil {
+				return err
+			}
+			return lending.ErrHoldClosed
+		}
+		if err != nil {
+			return err
+		}
+
+		if hold.Status == lending.HoldReady {
+			c, err := r.GetCopy(ctx, hold.Barcode)
+			if err != nil && err != lending.ErrCopyNotFound {
+				return err
+			}
+			if c != nil {
+				if _, err := r.shelve(ctx, *c); err != nil {
+					return err
+				}
+			}
+		}
+		hold.Status = lending.HoldCancelled
+		return r.box.Add(ctx, events.HoldCancelled, hold.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
@@ -0,0 +1,261 @@
+package catalog
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/content"
+	"github.com/CAPS-Cloud/exercises/internal/lending"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/labstack/echo/v4"
+)
+
+// The REST handlers of the lending library: the copies and holds of books,
+// /api/copies and /api/loans. Like /api/orders they only speak JSON, but
+// accept JSON, MessagePack and form bodies.
+
+// RegisterLending adds the lending endpoints to e. Only the monolith and the
+// root service serve them.
+func RegisterLending(e *echo.Echo, repo *Repository) {
+	e.GET("/api/books/:id/copies", ListCopiesHandler(repo))
+	e.POST("/api/books/:id/copies", AddCopyHandler(repo))
+	e.GET("/api/books/:id/holds", ListHoldsHandler(repo))
+	e.POST("/api/books/:id/holds", PlaceHoldHandler(repo))
+	e.DELETE("/api/holds/:id", CancelHoldHandler(repo))
+	e.GET("/api/copies/:barcode", GetCopyHandler(repo))
+	e.DELETE("/api/copies/:barcode", RemoveCopyHandler(repo))
+	e.POST("/api/copies/:barcode/checkout", LendHandler(repo))
+	e.POST("/api/copies/:barcode/return", ReturnHandler(repo))
+	e.GET("/api/loans", ListLoansHandler(repo))
+	e.GET("/api/loans/overdue", OverdueHandler(repo))
+}
+
+func copiesAPI(list []lending.Copy) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(list))
+	for _, c := range list {
+		ret = append(ret, c.API())
+	}
+	return ret
+}
+
+func loansAPI(list []lending.Loan) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(list))
+	for _, l := range list {
+		ret = append(ret, l.API())
+	}
+	return ret
+}
+
+func holdsAPI(list []lending.Hold) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(list))
+	for _, h := range list {
+		ret = append(ret, h.API())
+	}
+	return ret
+}
+
+// ListCopiesHandler serves GET /api/books/:id/copies, ordered by barcode.
+func ListCopiesHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := repo.Copies(c.Request().Context(), c.Param("id"))
+		if err == books.ErrNotFound {
+			return problem.NotFound("Book not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to list copies", err)
+		}
+		return c.JSON(http.StatusOK, copiesAPI(list))
+	}
+}
+
+// AddCopyHandler serves POST /api/books/:id/copies. It answers 201 with the
+// copy, available or set aside for the next hold, and 409 if the barcode is
+// taken.
+func AddCopyHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req lending.CopyRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		copy, err := repo.AddCopy(c.Request().Context(), c.Param("id"), req.Barcode)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == books.ErrNotFound:
+			return problem.NotFound("Book not found")
+		case err == lending.ErrBarcodeTaken:
+			return problem.Conflict("A copy with this barcode already exists")
+		case err != nil:
+			return problem.Internal("Failed to add copy", err)
+		}
+
+		return c.JSON(http.StatusCreated, copy.API())
+	}
+}
+
+// GetCopyHandler serves GET /api/copies/:barcode.
+func GetCopyHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		copy, err := repo.GetCopy(c.Request().Context(), c.Param("barcode"))
+		if err == lending.ErrCopyNotFound {
+			return problem.NotFound("Copy not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to get copy", err)
+		}
+		return c.JSON(http.StatusOK, copy.API())
+	}
+}
+
+// RemoveCopyHandler serves DELETE /api/copies/:barcode. It answers 409 for
+// copies on loan.
+func RemoveCopyHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := repo.RemoveCopy(c.Request().Context(), c.Param("barcode"))
+		switch {
+		case err == lending.ErrCopyNotFound:
+			return problem.NotFound("Copy not found")
+		case err == lending.ErrOnLoan:
+			return problem.Conflict("The copy is on loan, return it first")
+		case err != nil:
+			return problem.Internal("Failed to remove copy", err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// LendHandler serves POST /api/copies/:barcode/checkout. It answers 201
+// with the loan, and 409 if the copy is on loan or set aside for somebody
+// else.
+func LendHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req lending.CheckoutRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		loan, err := repo.Lend(c.Request().Context(), c.Param("barcode"), req)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == lending.ErrCopyNotFound:
+			return problem.NotFound("Copy not found")
+		case err == lending.ErrUnavailable:
+			return problem.Conflict("The copy is on loan or set aside for a hold")
+		case err != nil:
+			return problem.Internal("Failed to check out copy", err)
+		}
+
+		return c.JSON(http.StatusCreated, loan.API())
+	}
+}
+
+// ReturnHandler serves POST /api/copies/:barcode/return. It answers with the
+// closed loan and the copy, which is set aside for the next hold if there
+// is one, and 409 if the copy is not on loan.
+func ReturnHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		loan, copy, err := repo.Return(c.Request().Context(), c.Param("barcode"))
+		switch {
+		case err == lending.ErrCopyNotFound:
+			return problem.NotFound("Copy not found")
+		case err == lending.ErrNotOnLoan:
+			return problem.Conflict("The copy is not on loan")
+		case err != nil:
+			return problem.Internal("Failed to return copy", err)
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"loan": loan.API(),
+			"copy": copy.API(),
+		})
+	}
+}
+
+// ListLoansHandler serves GET /api/loans, the open loans, the earliest due
+// first. The borrower query parameter selects the loans of a borrower.
+func ListLoansHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := repo.Loans(c.Request().Context(), c.QueryParam("borrower"))
+		if err != nil {
+			return problem.Internal("Failed to list loans", err)
+		}
+		return c.JSON(http.StatusOK, loansAPI(list))
+	}
+}
+
+// OverdueHandler serves GET /api/loans/overdue, the longest overdue first.
+func OverdueHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := repo.Overdue(c.Request().Context(), time.Now())
+		if err != nil {
+			return problem.Internal("Failed to list overdue loans", err)
+		}
+		return c.JSON(http.StatusOK, loansAPI(list))
+	}
+}
+
+// ListHoldsHandler serves GET /api/books/:id/holds, the queue of the book.
+func ListHoldsHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := repo.Holds(c.Request().Context(), c.Param("id"))
+		if err == books.ErrNotFound {
+			return problem.NotFound("Book not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to list holds", err)
+		}
+		return c.JSON(http.StatusOK, holdsAPI(list))
+	}
+}
+
+// PlaceHoldHandler serves POST /api/books/:id/holds. It answers 201 with the
+// hold, ready if a copy was available, and 409 if the borrower already
+// holds the book.
+func PlaceHoldHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req lending.HoldRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		hold, err := repo.PlaceHold(c.Request().Context(), c.Param("id"), req)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == books.ErrNotFound:
+			return problem.NotFound("Book not found")
+		case err == lending.ErrDuplicateHold:
+			return problem.Conflict("The borrower already holds this book")
+		case err != nil:
+			return problem.Internal("Failed to place hold", err)
+		}
+
+		return c.JSON(http.StatusCreated, hold.API())
+	}
+}
+
+// CancelHoldHandler serves DELETE /api/holds/:id. It answers with the
+// cancelled hold, and 409 for holds fulfilled or cancelled before.
+func CancelHoldHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		hold, err := repo.CancelHold(c.Request().Context(), c.Param("id"))
+		switch {
+		case err == lending.ErrHoldNotFound:
+			return problem.NotFound("Hold not found")
+		case err == lending.ErrHoldClosed:
+			return problem.Conflict("The hold was fulfilled or cancelled before")
+		case err != nil:
+			return problem.Internal("Failed to cancel hold", err)
+		}
+
+		return c.JSON(http.StatusOK, hold.API())
+	}
+}
@@ -0,0 +1,408 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"github.com/CAPS-Cloud/exercises/internal/orders"
+	"github.com/CAPS-Cloud/exercises/internal/pricing"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OutOfStockError is returned by Checkout when a book of the cart has
+// fewer copies in stock than the cart asks for. It matches ErrOutOfStock.
+type OutOfStockError struct {
+	BookID string
+}
+
+func (e *OutOfStockError) Error() string {
+	return fmt.Sprintf("not enough copies of book %s in stock", e.BookID)
+}
+
+func (e *OutOfStockError) Is(target error) bool {
+	return target == ErrOutOfStock
+}
+
+func invalidCart(err error) error {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return &ValidationError{"Invalid cart", fields}
+	}
+	return err
+}
+
+// checkItems checks the items of a cart against their validate tags and
+// that their books exist.
+func (r *Repository) checkItems(ctx context.Context, items []orders.Item) error {
+	if err := invalidCart(validation.Struct(orders.Cart{Items: items})); err != nil {
+		return err
+	}
+	found, err := r.booksByID(ctx, items)
+	if err != nil {
+		return err
+	}
+	var fields validation.Errors
+	for i, item := range items {
+		if _, ok := found[item.BookID]; !ok {
+			fields = append(fields, validation.FieldError{Field: fmt.Sprintf("items[%d].bookId", i), Message: "is not a book"})
+		}
+	}
+	if len(fields) > 0 {
+		return invalidCart(fields)
+	}
+	return nil
+}
+
+// booksByID returns the books of items by their id. Books that do not exist
+// are missing from it.
+func (r *Repository) booksByID(ctx context.Context, items []orders.Item) (map[string]books.Book, error) {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.BookID)
+	}
+	cursor, err := r.coll.Find(ctx, bson.M{"id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var list []books.Book
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	ret := make(map[string]books.Book, len(list))
+	for _, b := range list {
+		ret[b.ID] = b
+	}
+	return ret, nil
+}
+
+// CreateCart stores a new cart with the given items and returns it. Items of
+// the same book are added up.
+func (r *Repository) CreateCart(ctx context.Context, items []orders.Item) (*orders.Cart, error) {
+	if err := r.checkItems(ctx, items); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	cart := orders.Cart{ID: primitive.NewObjectID().Hex(), Items: []orders.Item{}, Created: now, Updated: now}
+	for _, item := range items {
+		cart.Add(item)
+	}
+	if _, err := r.carts.InsertOne(ctx, cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// GetCart returns the cart with the given id.
+func (r *Repository) GetCart(ctx context.Context, id string) (*orders.Cart, error) {
+	var cart orders.Cart
+	err := r.carts.FindOne(ctx, bson.M{"id": id}).Decode(&cart)
+	if err == mongo.ErrNoDocuments {
+		return nil, orders.ErrCartNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// SetCartItem sets the number of copies of a book in a cart, adding the book
+// if it is not in the cart yet. It returns books.ErrNotFound for unknown
+// books.
+func (r *Repository) SetCartItem(ctx context.Context, cartID string, item orders.Item) (*orders.Cart, error) {
+	if err := invalidCart(validation.Struct(item)); err != nil {
+		return nil, err
+	}
+	if _, err := r.Get(ctx, item.BookID); err != nil {
+		return nil, err
+	}
+	cart, err := r.GetCart(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	cart.Set(item)
+	cart.Updated = time.Now().UTC().Truncate(time.Millisecond)
+
+	result, err := r.carts.ReplaceOne(ctx, bson.M{"id": cartID}, cart)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, orders.ErrCartNotFound
+	}
+	return cart, nil
+}
+
+// RemoveCartItem takes a book out of a cart. Removing a book the cart does
+// not hold is no error.
+func (r *Repository) RemoveCartItem(ctx context.Context, cartID, bookID string) (*orders.Cart, error) {
+	var cart orders.Cart
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.carts.FindOneAndUpdate(ctx, bson.M{"id": cartID}, bson.M{
+		"$pull": bson.M{"items": bson.M{"book_id": bookID}},
+		"$set":  bson.M{"updated": time.Now().UTC().Truncate(time.Millisecond)},
+	}, opts).Decode(&cart)
+	if err == mongo.ErrNoDocuments {
+		return nil, orders.ErrCartNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// DeleteCart removes the cart with the given id.
+func (r *Repository) DeleteCart(ctx context.Context, id string) error {
+	result, err := r.carts.DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return orders.ErrCartNotFound
+	}
+	return nil
+}
+
+// Checkout turns a cart into a pending order priced in currency, the base
+// currency of the rates if it is empty, and removes the cart. Every book is
+// priced with the price rules of the moment; books without a price cannot
+// be ordered.
+//
+// The copies of the order are taken out of stock in the same transaction
+// that stores the order: either all of them are reserved or, with an
+// OutOfStockError, none. Without transactions, on a standalone server,
+// reservations made before a book runs out are given back. A cart is
+// ordered only once: concurrent checkouts of it fail with
+// orders.ErrCartNotFound. The cart is priced before the transaction; if its
+// items change in the meantime the checkout fails with
+// orders.ErrCartChanged instead of ordering the old ones.
+func (r *Repository) Checkout(ctx context.Context, cartID, currency string) (*orders.Order, error) {
+	if currency == "" {
+		currency = r.rates.Base
+	}
+	code, err := r.currency(currency)
+	if err != nil {
+		return nil, &ValidationError{"Invalid checkout", validation.Errors{{Field: "currency", Message: err.Error()}}}
+	}
+
+	cart, err := r.GetCart(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, orders.ErrEmptyCart
+	}
+	found, err := r.booksByID(ctx, cart.Items)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := r.PriceRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	order := orders.Order{
+		ID:       primitive.NewObjectID().Hex(),
+		CartID:   cart.ID,
+		Currency: code,
+		Status:   orders.StatusPending,
+		Created:  now.UTC().Truncate(time.Millisecond),
+	}
+	order.Updated = order.Created
+	var fields validation.Errors
+	for i, item := range cart.Items {
+		field := fmt.Sprintf("items[%d].bookId", i)
+		b, ok := found[item.BookID]
+		if !ok {
+			fields = append(fields, validation.FieldError{Field: field, Message: "is not a book any more"})
+			continue
+		}
+		price, err := pricing.Quote(priceItem(b), rules, r.rates, code, now)
+		if errors.Is(err, pricing.ErrUnknownCurrency) || err == nil && price == nil {
+			fields = append(fields, validation.FieldError{Field: field, Message: "has no price in " + code})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := order.AddLine(b.ID, b.BookName, item.Quantity, *price); err != nil {
+			return nil, err
+		}
+	}
+	if len(fields) > 0 {
+		return nil, &ValidationError{"The cart cannot be ordered", fields}
+	}
+
+	err = r.box.Transaction(ctx, func(ctx context.Context) error {
+		// Deleting the cart first lets only one of concurrent checkouts of
+		// the same cart, or retries of this transaction, go on. Matching
+		// updated and the items deletes only the cart as it was priced;
+		// updated alone misses changes made within the same millisecond.
+		result, err := r.carts.DeleteOne(ctx, bson.M{"id": cart.ID, "updated": cart.Updated, "items": cart.Items})
+		if err != nil {
+			return err
+		}
+		if result.DeletedCount == 0 {
+			count, err := r.carts.CountDocuments(ctx, bson.M{"id": cart.ID})
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return orders.ErrCartChanged
+			}
+			return orders.ErrCartNotFound
+		}
+		if err := r.reserve(ctx, order); err != nil {
+			// A failed transaction keeps the cart anyway; without one it
+			// has to be put back.
+			var outOfStock *OutOfStockError
+			if errors.As(err, &outOfStock) || err == books.ErrNotFound {
+				if _, err := r.carts.InsertOne(ctx, cart); err != nil {
+					return err
+				}
+			}
+			return err
+		}
+		if _, err := r.orders.InsertOne(ctx, order); err != nil {
+			return err
+		}
+		return r.box.Add(ctx, events.OrderCreated, order.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// reserve takes the copies of the lines of order out of stock. If a book
+// runs out, or was deleted since the order was priced, it gives back what it
+// took so far and returns an OutOfStockError or books.ErrNotFound.
+func (r *Repository) reserve(ctx context.Context, order orders.Order) error {
+	for i, line := range order.Lines {
+		movement := books.StockMovement{BookID: line.BookID, Delta: -line.Quantity, Reason: "order " + order.ID}
+		err := r.adjustStock(ctx, &movement)
+		switch {
+		case err == ErrOutOfStock:
+			err = &OutOfStockError{BookID: line.BookID}
+		case err != books.ErrNotFound:
+			// Other errors abort the transaction, which may be retried.
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if releaseErr := r.release(ctx, order.Lines[:i], "order "+order.ID+" failed"); releaseErr != nil {
+			return releaseErr
+		}
+		return err
+	}
+	return nil
+}
+
+// release puts the copies of lines back into stock. Books deleted since are
+// skipped.
+func (r *Repository) release(ctx context.Context, lines []orders.Line, reason string) error {
+	for _, line := range lines {
+		movement := books.StockMovement{BookID: line.BookID, Delta: line.Quantity, Reason: reason}
+		if err := r.adjustStock(ctx, &movement); err != nil && err != books.ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// Orders returns the orders with the given status, or every order if it is
+// empty, newest first.
+func (r *Repository) Orders(ctx context.Context, status string) ([]orders.Order, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created", Value: -1}, {Key: "id", Value: -1}})
+	cursor, err := r.orders.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := []orders.Order{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetOrder returns the order with the given id.
+func (r *Repository) GetOrder(ctx context.Context, id string) (*orders.Order, error) {
+	var order orders.Order
+	err := r.orders.FindOne(ctx, bson.M{"id": id}).Decode(&order)
+	if err == mongo.ErrNoDocuments {
+		return nil, orders.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// SetOrderStatus moves the order with the given id to status, and returns
+// an orders.TransitionError if orders.CanMove does not allow it. Cancelling
+// an order puts its copies back into stock.
+//
+// The check of the current status and the change are a single atomic
+// update, so an order can never be cancelled, and its stock given back,
+// twice.
+func (r *Repository) SetOrderStatus(ctx context.Context, id, status string) (*orders.Order, error) {
+	if err := validation.Struct(orders.StatusChange{Status: status}); err != nil {
+		var fields validation.Errors
+		if errors.As(err, &fields) {
+			return nil, &ValidationError{"Invalid status change", fields}
+		}
+		return nil, err
+	}
+
+	// No order can move to pending, so there is nothing to look for.
+	sources := orders.Sources(status)
+	if len(sources) == 0 {
+		current, err := r.GetOrder(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &orders.TransitionError{From: current.Status, To: status}
+	}
+
+	var order orders.Order
+	err := r.box.Transaction(ctx, func(ctx context.Context) error {
+		filter := bson.M{"id": id, "status": bson.M{"$in": sources}}
+		update := bson.M{"$set": bson.M{"status": status, "updated": time.Now().UTC().Truncate(time.Millisecond)}}
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err := r.orders.FindOneAndUpdate(ctx, filter, update, opts).Decode(&order)
+		if err == mongo.ErrNoDocuments {
+			current, err := r.GetOrder(ctx, id)
+			if err != nil {
+				return err
+			}
+			return &orders.TransitionError{From: current.Status, To: status}
+		}
+		if err != nil {
+			return err
+		}
+
+		if status == orders.StatusCancelled {
+			if err := r.release(ctx, order.Lines, "order "+order.ID+" cancelled"); err != nil {
+				return err
+			}
+		}
+		return r.box.Add(ctx, events.OrderUpdated, order.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
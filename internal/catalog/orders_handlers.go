@@ -0,0 +1,235 @@
+package catalog
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/content"
+	"github.com/CAPS-Cloud/exercises/internal/orders"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/labstack/echo/v4"
+)
+
+// The REST handlers of /api/carts and /api/orders. Like /api/pricing they
+// only speak JSON, but accept JSON, MessagePack and form bodies.
+
+// RegisterOrders adds the /api/carts and /api/orders endpoints to e. Only
+// the monolith and the root service serve them.
+func RegisterOrders(e *echo.Echo, repo *Repository) {
+	e.POST("/api/carts", CreateCartHandler(repo))
+	e.GET("/api/carts/:id", GetCartHandler(repo))
+	e.DELETE("/api/carts/:id", DeleteCartHandler(repo))
+	e.PUT("/api/carts/:id/items/:bookId", SetCartItemHandler(repo))
+	e.DELETE("/api/carts/:id/items/:bookId", RemoveCartItemHandler(repo))
+	e.POST("/api/carts/:id/checkout", CheckoutHandler(repo))
+	e.GET("/api/orders", ListOrdersHandler(repo))
+	e.GET("/api/orders/:id", GetOrderHandler(repo))
+	e.PUT("/api/orders/:id/status", SetOrderStatusHandler(repo))
+}
+
+// cartRequest is the body of POST /api/carts. It may be empty.
+type cartRequest struct {
+	Items []orders.Item `json:"items" msgpack:"items"`
+}
+
+// quantityRequest is the body of PUT /api/carts/:id/items/:bookId.
+type quantityRequest struct {
+	Quantity int `json:"quantity" msgpack:"quantity"`
+}
+
+// checkoutRequest is the body of POST /api/carts/:id/checkout. It may be
+// empty.
+type checkoutRequest struct {
+	Currency string `json:"currency" msgpack:"currency"`
+}
+
+// CreateCartHandler serves POST /api/carts. It answers 201 with the new
+// cart and 400 with the fields in error for unknown books or quantities out
+// of range.
+func CreateCartHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req cartRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		cart, err := repo.CreateCart(c.Request().Context(), req.Items)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err != nil:
+			return problem.Internal("Failed to create cart", err)
+		}
+
+		return c.JSON(http.StatusCreated, cart.API())
+	}
+}
+
+// GetCartHandler serves GET /api/carts/:id.
+func GetCartHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		cart, err := repo.GetCart(c.Request().Context(), c.Param("id"))
+		if err == orders.ErrCartNotFound {
+			return problem.NotFound("Cart not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to get cart", err)
+		}
+		return c.JSON(http.StatusOK, cart.API())
+	}
+}
+
+// DeleteCartHandler serves DELETE /api/carts/:id.
+func DeleteCartHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := repo.DeleteCart(c.Request().Context(), c.Param("id"))
+		switch {
+		case err == orders.ErrCartNotFound:
+			return problem.NotFound("Cart not found")
+		case err != nil:
+			return problem.Internal("Failed to delete cart", err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// SetCartItemHandler serves PUT /api/carts/:id/items/:bookId. It sets the
+// number of copies of the book in the cart and answers with the cart.
+func SetCartItemHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req quantityRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		item := orders.Item{BookID: c.Param("bookId"), Quantity: req.Quantity}
+		cart, err := repo.SetCartItem(c.Request().Context(), c.Param("id"), item)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == orders.ErrCartNotFound:
+			return problem.NotFound("Cart not found")
+		case err == books.ErrNotFound:
+			return problem.NotFound("Book not found")
+		case err != nil:
+			return problem.Internal("Failed to update cart", err)
+		}
+
+		return c.JSON(http.StatusOK, cart.API())
+	}
+}
+
+// RemoveCartItemHandler serves DELETE /api/carts/:id/items/:bookId and
+// answers with the cart.
+func RemoveCartItemHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		cart, err := repo.RemoveCartItem(c.Request().Context(), c.Param("id"), c.Param("bookId"))
+		switch {
+		case err == orders.ErrCartNotFound:
+			return problem.NotFound("Cart not found")
+		case err != nil:
+			return problem.Internal("Failed to update cart", err)
+		}
+
+		return c.JSON(http.StatusOK, cart.API())
+	}
+}
+
+// CheckoutHandler serves POST /api/carts/:id/checkout. It answers 201 with
+// the pending order, 400 for books that cannot be ordered and 409 for an
+// empty cart, a cart changed during checkout or books out of stock.
+func CheckoutHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req checkoutRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		order, err := repo.Checkout(c.Request().Context(), c.Param("id"), req.Currency)
+		var invalid *ValidationError
+		var outOfStock *OutOfStockError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == orders.ErrCartNotFound:
+			return problem.NotFound("Cart not found")
+		case err == orders.ErrEmptyCart:
+			return problem.Conflict("The cart is empty")
+		case err == orders.ErrCartChanged:
+			return problem.Conflict("The cart changed during checkout, please check it and try again")
+		case errors.As(err, &outOfStock):
+			return problem.Conflict("Not enough copies of book " + outOfStock.BookID + " in stock")
+		case err == books.ErrNotFound:
+			return problem.Conflict("A book of the cart was deleted during checkout")
+		case err != nil:
+			return problem.Internal("Failed to check out cart", err)
+		}
+
+		return c.JSON(http.StatusCreated, order.API())
+	}
+}
+
+func ordersAPI(list []orders.Order) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(list))
+	for _, o := range list {
+		ret = append(ret, o.API())
+	}
+	return ret
+}
+
+// ListOrdersHandler serves GET /api/orders, newest first. The status query
+// parameter selects the orders with that status.
+func ListOrdersHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := repo.Orders(c.Request().Context(), c.QueryParam("status"))
+		if err != nil {
+			return problem.Internal("Failed to list orders", err)
+		}
+		return c.JSON(http.StatusOK, ordersAPI(list))
+	}
+}
+
+// GetOrderHandler serves GET /api/orders/:id.
+func GetOrderHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		order, err := repo.GetOrder(c.Request().Context(), c.Param("id"))
+		if err == orders.ErrOrderNotFound {
+			return problem.NotFound("Order not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to get order", err)
+		}
+		return c.JSON(http.StatusOK, order.API())
+	}
+}
+
+// SetOrderStatusHandler serves PUT /api/orders/:id/status. It answers with
+// the order, and 409 for status changes the order does not allow.
+func SetOrderStatusHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req orders.StatusChange
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		order, err := repo.SetOrderStatus(c.Request().Context(), c.Param("id"), req.Status)
+		var invalid *ValidationError
+		var transition *orders.TransitionError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == orders.ErrOrderNotFound:
+			return problem.NotFound("Order not found")
+		case errors.As(err, &transition):
+			return problem.Conflict("The order cannot be " + transition.To + ", it is " + transition.From)
+		case err != nil:
+			return problem.Internal("Failed to change order status", err)
+		}
+
+		return c.JSON(http.StatusOK, order.API())
+	}
+}
@@ -0,0 +1,268 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"github.com/CAPS-Cloud/exercises/internal/pricing"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SetRates replaces the table prices are converted with, see
+// pricing.RatesFromEnv.
+func (r *Repository) SetRates(rates pricing.Rates) {
+	r.rates = rates
+}
+
+// Rates returns the table prices are converted with.
+func (r *Repository) Rates() pricing.Rates {
+	return r.rates
+}
+
+// currency reads a currency code and checks that the rates table has it.
+func (r *Repository) currency(s string) (string, error) {
+	code, err := pricing.ParseCurrency(s)
+	if err != nil {
+		return "", err
+	}
+	if !r.rates.Has(code) {
+		return "", pricing.ErrUnknownCurrency
+	}
+	return code, nil
+}
+
+// queryCurrency is currency for the currency query parameter: the error is
+// a ValidationError.
+func (r *Repository) queryCurrency(s string) (string, error) {
+	code, err := r.currency(s)
+	if err != nil {
+		return "", &ValidationError{"Invalid query parameter", validation.Errors{{Field: "currency", Message: err.Error()}}}
+	}
+	return code, nil
+}
+
+// normalizePrice writes the list price of b with two decimals and its
+// currency in upper case. A list price without a currency is in the base
+// currency of the rates table.
+func (r *Repository) normalizePrice(b *books.Book) error {
+	if b.Currency != "" {
+		code, err := r.currency(b.Currency)
+		if err != nil {
+			return &ValidationError{"Invalid book", validation.Errors{{Field: "currency", Message: err.Error()}}}
+		}
+		b.Currency = code
+	}
+	if b.ListPrice == "" {
+		return nil
+	}
+	cents, err := pricing.ParseAmount(b.ListPrice)
+	if err != nil {
+		return &ValidationError{"Invalid book", validation.Errors{{Field: "listPrice", Message: err.Error()}}}
+	}
+	b.ListPrice = pricing.FormatAmount(cents)
+	if b.Currency == "" {
+		b.Currency = r.rates.Base
+	}
+	return nil
+}
+
+// Price sets the effective price of the books of list with a list price,
+// converted to currency, or left in the currency of each book if it is
+// empty. Books whose currency has since left the rates table get none.
+func (r *Repository) Price(ctx context.Context, currency string, list []books.Book) error {
+	if currency != "" {
+		code, err := r.queryCurrency(currency)
+		if err != nil {
+			return err
+		}
+		currency = code
+	}
+	if !slices.ContainsFunc(list, func(b books.Book) bool { return b.ListPrice != "" }) {
+		return nil
+	}
+
+	rules, err := r.PriceRules(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i, b := range list {
+		price, err := pricing.Quote(priceItem(b), rules, r.rates, currency, now)
+		if errors.Is(err, pricing.ErrUnknownCurrency) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		list[i].EffectivePrice = price
+	}
+	return nil
+}
+
+// priceItem is what the price rules look at in b.
+func priceItem(b books.Book) pricing.Item {
+	item := pricing.Item{ListPrice: b.ListPrice, Currency: b.Currency, Tags: b.Tags}
+	for _, c := range b.Contributors() {
+		if c.Role == "" || c.Role == books.RoleAuthor {
+			item.Authors = append(item.Authors, c.Name)
+		}
+	}
+	return item
+}
+
+// ValidatePriceRule checks a new price rule against the rules of its
+// validate tags and the fields against each other. It normalizes the rule
+// on the way, see checkRule.
+func (r *Repository) ValidatePriceRule(rule *pricing.Rule) error {
+	if err := invalidRule(validation.Struct(*rule)); err != nil {
+		return err
+	}
+	return r.checkRule(rule)
+}
+
+func invalidRule(err error) error {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return &ValidationError{"Invalid price rule", fields}
+	}
+	return err
+}
+
+// checkRule checks what the validate tags of a rule cannot: that
+// percentages are at most 100, that the currency of fixed discounts, the
+// base currency by default, is in the rates table, and that the window does
+// not end before it starts. It writes the value with two decimals, the tag
+// in lower case, like the tags of books, and the currency in upper case.
+func (r *Repository) checkRule(rule *pricing.Rule) error {
+	var fields validation.Errors
+	value, err := pricing.ParseAmount(rule.Value)
+	if err != nil {
+		return invalidRule(validation.Errors{{Field: "value", Message: err.Error()}})
+	}
+	rule.Value = pricing.FormatAmount(value)
+	rule.Tag = strings.ToLower(strings.TrimSpace(rule.Tag))
+	rule.Author = strings.TrimSpace(rule.Author)
+
+	switch rule.Kind {
+	case pricing.KindPercent:
+		if value > 100_00 {
+			fields = append(fields, validation.FieldError{Field: "value", Message: "must be a percentage from 0 to 100"})
+		}
+		rule.Currency = ""
+	case pricing.KindFixed:
+		if rule.Currency == "" {
+			rule.Currency = r.rates.Base
+		}
+		if rule.Currency, err = r.currency(rule.Currency); err != nil {
+			fields = append(fields, validation.FieldError{Field: "currency", Message: err.Error()})
+		}
+	}
+	if rule.From != "" && rule.Until != "" && rule.Until < rule.From {
+		fields = append(fields, validation.FieldError{Field: "until", Message: "must not be before from"})
+	}
+	if len(fields) > 0 {
+		return invalidRule(fields)
+	}
+	return nil
+}
+
+// PriceRules returns every price rule, ordered by name.
+func (r *Repository) PriceRules(ctx context.Context) ([]pricing.Rule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "id", Value: 1}})
+	cursor, err := r.rules.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := []pricing.Rule{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetPriceRule returns the price rule with the given id.
+func (r *Repository) GetPriceRule(ctx context.Context, id string) (*pricing.Rule, error) {
+	var rule pricing.Rule
+	err := r.rules.FindOne(ctx, bson.M{"id": id}).Decode(&rule)
+	if err == mongo.ErrNoDocuments {
+		return nil, pricing.ErrRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreatePriceRule validates and stores a new price rule, and returns it as
+// stored. The id of rule is ignored.
+func (r *Repository) CreatePriceRule(ctx context.Context, rule pricing.Rule) (*pricing.Rule, error) {
+	if err := r.ValidatePriceRule(&rule); err != nil {
+		return nil, err
+	}
+	rule.ID = primitive.NewObjectID().Hex()
+
+	err := r.box.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := r.rules.InsertOne(ctx, rule); err != nil {
+			return err
+		}
+		return r.box.Add(ctx, events.PriceRuleCreated, rule.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdatePriceRule applies the non-empty fields of patch to the price rule
+// with the given id, see pricing.Rule.Merge.
+func (r *Repository) UpdatePriceRule(ctx context.Context, id string, patch pricing.Rule) (*pricing.Rule, error) {
+	if err := invalidRule(validation.Partial(patch)); err != nil {
+		return nil, err
+	}
+	rule, err := r.GetPriceRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rule.Merge(patch)
+	if err := r.checkRule(rule); err != nil {
+		return nil, err
+	}
+
+	err = r.box.Transaction(ctx, func(ctx context.Context) error {
+		result, err := r.rules.ReplaceOne(ctx, bson.M{"id": id}, rule)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return pricing.ErrRuleNotFound
+		}
+		return r.box.Add(ctx, events.PriceRuleUpdated, rule.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeletePriceRule removes the price rule with the given id.
+func (r *Repository) DeletePriceRule(ctx context.Context, id string) error {
+	return r.box.Transaction(ctx, func(ctx context.Context) error {
+		result, err := r.rules.DeleteOne(ctx, bson.M{"id": id})
+		if err != nil {
+			return err
+		}
+		if result.DeletedCount == 0 {
+			return pricing.ErrRuleNotFound
+		}
+		return r.box.Add(ctx, events.PriceRuleDeleted, map[string]interface{}{"id": id})
+	})
+}
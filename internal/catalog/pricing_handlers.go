@@ -0,0 +1,202 @@
+package catalog
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/content"
+	"github.com/CAPS-Cloud/exercises/internal/pricing"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/labstack/echo/v4"
+)
+
+// The REST handlers of /api/pricing. Like /api/authors they only speak
+// JSON, but accept JSON, MessagePack and form bodies.
+
+// RegisterPricing adds the /api/pricing endpoints to e: the price rules and
+// the rates table. Only the monolith and the root service serve them.
+func RegisterPricing(e *echo.Echo, repo *Repository) {
+	e.GET("/api/pricing/rates", RatesHandler(repo))
+	e.GET("/api/pricing/rules", ListPriceRulesHandler(repo))
+	e.GET("/api/pricing/rules/:id", GetPriceRuleHandler(repo))
+	e.POST("/api/pricing/rules", CreatePriceRuleHandler(repo))
+	e.PUT("/api/pricing/rules/:id", UpdatePriceRuleHandler(repo))
+	e.DELETE("/api/pricing/rules/:id", DeletePriceRuleHandler(repo))
+}
+
+// priceRuleRequest is the body of POST and PUT: the rule without its id.
+type priceRuleRequest struct {
+	Name     string `json:"name" msgpack:"name"`
+	Kind     string `json:"kind" msgpack:"kind"`
+	Value    string `json:"value" msgpack:"value"`
+	Currency string `json:"currency" msgpack:"currency"`
+	Tag      string `json:"tag" msgpack:"tag"`
+	Author   string `json:"author" msgpack:"author"`
+	From     string `json:"from" msgpack:"from"`
+	Until    string `json:"until" msgpack:"until"`
+}
+
+func (req priceRuleRequest) rule() pricing.Rule {
+	return pricing.Rule{
+		Name:     req.Name,
+		Kind:     req.Kind,
+		Value:    req.Value,
+		Currency: req.Currency,
+		Tag:      req.Tag,
+		Author:   req.Author,
+		From:     req.From,
+		Until:    req.Until,
+	}
+}
+
+// priced sets the effective prices of list, in the currency of the currency
+// query parameter if there is one. The error is a problem.
+func priced(c echo.Context, repo *Repository, list []books.Book) error {
+	err := repo.Price(c.Request().Context(), c.QueryParam("currency"), list)
+	var invalid *ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		return problem.Validation(invalid.Message, invalid.Fields...)
+	case err != nil:
+		return problem.Internal("Failed to price books", err)
+	}
+	return nil
+}
+
+// checkCurrency validates the currency query parameter of a handler that
+// changes a book and answers with it. It has to run before the change, so
+// that an invalid currency never answers 400 for a change that was made.
+// The error is a problem.
+func checkCurrency(c echo.Context, repo *Repository) error {
+	currency := c.QueryParam("currency")
+	if currency == "" {
+		return nil
+	}
+	_, err := repo.queryCurrency(currency)
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		return problem.Validation(invalid.Message, invalid.Fields...)
+	}
+	return err
+}
+
+// writeBook writes book with its effective price, see priced.
+func writeBook(c echo.Context, repo *Repository, status int, book books.Book) error {
+	list := []books.Book{book}
+	if err := priced(c, repo, list); err != nil {
+		return err
+	}
+	return content.Book(c, status, list[0])
+}
+
+// writeBooks writes list with the effective prices, see priced.
+func writeBooks(c echo.Context, repo *Repository, status int, list []books.Book) error {
+	if err := priced(c, repo, list); err != nil {
+		return err
+	}
+	return content.Books(c, status, list)
+}
+
+// RatesHandler serves GET /api/pricing/rates, the table prices are
+// converted with.
+func RatesHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, repo.Rates())
+	}
+}
+
+func priceRulesAPI(list []pricing.Rule) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(list))
+	for _, r := range list {
+		ret = append(ret, r.API())
+	}
+	return ret
+}
+
+// ListPriceRulesHandler serves GET /api/pricing/rules, ordered by name.
+func ListPriceRulesHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := repo.PriceRules(c.Request().Context())
+		if err != nil {
+			return problem.Internal("Failed to list price rules", err)
+		}
+		return c.JSON(http.StatusOK, priceRulesAPI(list))
+	}
+}
+
+// GetPriceRuleHandler serves GET /api/pricing/rules/:id.
+func GetPriceRuleHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		rule, err := repo.GetPriceRule(c.Request().Context(), c.Param("id"))
+		if err == pricing.ErrRuleNotFound {
+			return problem.NotFound("Price rule not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to get price rule", err)
+		}
+		return c.JSON(http.StatusOK, rule.API())
+	}
+}
+
+// CreatePriceRuleHandler serves POST /api/pricing/rules. It answers 201 with
+// the new rule and 400 with the fields in error for an invalid rule.
+func CreatePriceRuleHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req priceRuleRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		rule, err := repo.CreatePriceRule(c.Request().Context(), req.rule())
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err != nil:
+			return problem.Internal("Failed to create price rule", err)
+		}
+
+		return c.JSON(http.StatusCreated, rule.API())
+	}
+}
+
+// UpdatePriceRuleHandler serves PUT /api/pricing/rules/:id. Only the fields
+// present and non-empty in the body are changed; conditions cannot be
+// cleared, see pricing.Rule.Merge.
+func UpdatePriceRuleHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req priceRuleRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		rule, err := repo.UpdatePriceRule(c.Request().Context(), c.Param("id"), req.rule())
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == pricing.ErrRuleNotFound:
+			return problem.NotFound("Price rule not found")
+		case err != nil:
+			return problem.Internal("Failed to update price rule", err)
+		}
+
+		return c.JSON(http.StatusOK, rule.API())
+	}
+}
+
+// DeletePriceRuleHandler serves DELETE /api/pricing/rules/:id.
+func DeletePriceRuleHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := repo.DeletePriceRule(c.Request().Context(), c.Param("id"))
+		switch {
+		case err == pricing.ErrRuleNotFound:
+			return problem.NotFound("Price rule not found")
+		case err != nil:
+			return problem.Internal("Failed to delete price rule", err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
@@ -0,0 +1,135 @@
+package catalog
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/content"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/labstack/echo/v4"
+)
+
+// The REST handlers of /api/books/:id/reviews. Like /api/authors they only
+// speak JSON, but accept JSON, MessagePack and form bodies.
+
+// RegisterReviews adds the /api/books/:id/reviews endpoints to e. Only the
+// monolith and the root service serve them.
+func RegisterReviews(e *echo.Echo, repo *Repository) {
+	e.GET("/api/books/:id/reviews", ListReviewsHandler(repo))
+	e.GET("/api/books/:id/reviews/:review", GetReviewHandler(repo))
+	e.POST("/api/books/:id/reviews", CreateReviewHandler(repo))
+	e.PUT("/api/books/:id/reviews/:review", UpdateReviewHandler(repo))
+	e.DELETE("/api/books/:id/reviews/:review", DeleteReviewHandler(repo))
+}
+
+func reviewsAPI(list []books.Review) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(list))
+	for _, r := range list {
+		ret = append(ret, r.API())
+	}
+	return ret
+}
+
+// reviewRequest is the body of POST and PUT: the fields a customer sets.
+type reviewRequest struct {
+	Reviewer string `json:"reviewer" msgpack:"reviewer"`
+	Rating   int    `json:"rating" msgpack:"rating"`
+	Text     string `json:"text" msgpack:"text"`
+}
+
+func (req reviewRequest) review() books.Review {
+	return books.Review{Reviewer: req.Reviewer, Rating: req.Rating, Text: req.Text}
+}
+
+// ListReviewsHandler serves GET /api/books/:id/reviews, newest first.
+func ListReviewsHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := repo.ListReviews(c.Request().Context(), c.Param("id"))
+		if err == books.ErrNotFound {
+			return problem.NotFound("Book not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to list reviews", err)
+		}
+		return c.JSON(http.StatusOK, reviewsAPI(list))
+	}
+}
+
+// GetReviewHandler serves GET /api/books/:id/reviews/:review.
+func GetReviewHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		review, err := repo.GetReview(c.Request().Context(), c.Param("id"), c.Param("review"))
+		if err == books.ErrReviewNotFound {
+			return problem.NotFound("Review not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to get review", err)
+		}
+		return c.JSON(http.StatusOK, review.API())
+	}
+}
+
+// CreateReviewHandler serves POST /api/books/:id/reviews. It answers 201
+// with the new review, 400 with the fields in error for an invalid review
+// and 404 for an unknown book.
+func CreateReviewHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req reviewRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		review, err := repo.CreateReview(c.Request().Context(), c.Param("id"), req.review())
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == books.ErrNotFound:
+			return problem.NotFound("Book not found")
+		case err != nil:
+			return problem.Internal("Failed to create review", err)
+		}
+
+		return c.JSON(http.StatusCreated, review.API())
+	}
+}
+
+// UpdateReviewHandler serves PUT /api/books/:id/reviews/:review. Only the
+// fields present and non-empty in the body are changed.
+func UpdateReviewHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req reviewRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		review, err := repo.UpdateReview(c.Request().Context(), c.Param("id"), c.Param("review"), req.review())
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == books.ErrReviewNotFound:
+			return problem.NotFound("Review not found")
+		case err != nil:
+			return problem.Internal("Failed to update review", err)
+		}
+
+		return c.JSON(http.StatusOK, review.API())
+	}
+}
+
+// DeleteReviewHandler serves DELETE /api/books/:id/reviews/:review.
+func DeleteReviewHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := repo.DeleteReview(c.Request().Context(), c.Param("id"), c.Param("review"))
+		switch {
+		case err == books.ErrReviewNotFound:
+			return problem.NotFound("Review not found")
+		case err != nil:
+			return problem.Internal("Failed to delete review", err)
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
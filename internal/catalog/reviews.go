@@ -0,0 +1,187 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ValidateReview checks a new review against the rules of its validate
+// tags.
+func ValidateReview(r books.Review) error {
+	return invalidReview(validation.Struct(r))
+}
+
+func invalidReview(err error) error {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return &ValidationError{"Invalid review", fields}
+	}
+	return err
+}
+
+// ListReviews returns the reviews of the book with the given id, newest
+// first.
+func (r *Repository) ListReviews(ctx context.Context, bookID string) ([]books.Review, error) {
+	if _, err := r.Get(ctx, bookID); err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created", Value: -1}, {Key: "id", Value: -1}})
+	cursor, err := r.reviews.Find(ctx, bson.M{"book_id": bookID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := []books.Review{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetReview returns a review of the book with the given id.
+func (r *Repository) GetReview(ctx context.Context, bookID, id string) (*books.Review, error) {
+	var review books.Review
+	err := r.reviews.FindOne(ctx, bson.M{"book_id": bookID, "id": id}).Decode(&review)
+	if err == mongo.ErrNoDocuments {
+		return nil, books.ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// CreateReview validates and stores a new review of the book with the given
+// id, and returns it as stored. The id and times of review are ignored.
+func (r *Repository) CreateReview(ctx context.Context, bookID string, review books.Review) (*books.Review, error) {
+	if err := ValidateReview(review); err != nil {
+		return nil, err
+	}
+	if _, err := r.Get(ctx, bookID); err != nil {
+		return nil, err
+	}
+
+	review.ID = primitive.NewObjectID().Hex()
+	review.BookID = bookID
+	review.Created = time.Now().UTC().Truncate(time.Millisecond)
+	review.Updated = review.Created
+
+	err := r.box.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := r.reviews.InsertOne(ctx, review); err != nil {
+			return err
+		}
+		if err := r.updateRating(ctx, bookID); err != nil {
+			return err
+		}
+		return r.box.Add(ctx, events.ReviewCreated, review.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// UpdateReview applies the non-empty fields of patch to a review of the
+// book with the given id.
+func (r *Repository) UpdateReview(ctx context.Context, bookID, id string, patch books.Review) (*books.Review, error) {
+	if err := invalidReview(validation.Partial(patch)); err != nil {
+		return nil, err
+	}
+	review, err := r.GetReview(ctx, bookID, id)
+	if err != nil {
+		return nil, err
+	}
+	review.Merge(patch)
+	review.Updated = time.Now().UTC().Truncate(time.Millisecond)
+
+	err = r.box.Transaction(ctx, func(ctx context.Context) error {
+		result, err := r.reviews.ReplaceOne(ctx, bson.M{"book_id": bookID, "id": id}, review)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return books.ErrReviewNotFound
+		}
+		if err := r.updateRating(ctx, bookID); err != nil {
+			return err
+		}
+		return r.box.Add(ctx, events.ReviewUpdated, review.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// DeleteReview removes a review of the book with the given id.
+func (r *Repository) DeleteReview(ctx context.Context, bookID, id string) error {
+	return r.box.Transaction(ctx, func(ctx context.Context) error {
+		result, err := r.reviews.DeleteOne(ctx, bson.M{"book_id": bookID, "id": id})
+		if err != nil {
+			return err
+		}
+		if result.DeletedCount == 0 {
+			return books.ErrReviewNotFound
+		}
+		if err := r.updateRating(ctx, bookID); err != nil {
+			return err
+		}
+		return r.box.Add(ctx, events.ReviewDeleted, map[string]interface{}{"id": id, "bookId": bookID})
+	})
+}
+
+// updateRating stores the average rating and the number of the reviews of
+// a book in the book, and records the change of the book. It runs in the
+// transaction of the review that changed, so that concurrent reviews of the
+// same book conflict and are retried instead of overwriting each other's
+// average.
+func (r *Repository) updateRating(ctx context.Context, bookID string) error {
+	cursor, err := r.reviews.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"book_id": bookID}},
+		bson.M{"$group": bson.M{
+			"_id":    nil,
+			"rating": bson.M{"$avg": "$rating"},
+			"count":  bson.M{"$sum": 1},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	var result []struct {
+		Rating float64 `bson:"rating"`
+		Count  int     `bson:"count"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return err
+	}
+
+	var rating float64
+	var count int
+	if len(result) > 0 {
+		rating = math.Round(result[0].Rating*100) / 100
+		count = result[0].Count
+	}
+
+	var book books.Book
+	err = r.coll.FindOneAndUpdate(ctx,
+		bson.M{"id": bookID},
+		bson.M{"$set": bson.M{"rating": rating, "review_count": count}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&book)
+	if err == mongo.ErrNoDocuments {
+		return books.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return r.box.Add(ctx, events.BookUpdated, book.API())
+}
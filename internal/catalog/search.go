@@ -0,0 +1,213 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Query selects books by text and by facets. Empty fields match every
+// book.
+type Query struct {
+	// Text matches titles and author strings, ignoring case.
+	Text  string
+	Genre string
+	// Author matches any contributor by name.
+	Author string
+	// Decade is the first year of a decade, like "1810".
+	Decade string
+	Tag    string
+}
+
+// QueryFromParams reads a query from the parameters q, genre, author,
+// decade and tag.
+func QueryFromParams(params url.Values) (Query, error) {
+	q := Query{
+		Text:   strings.TrimSpace(params.Get("q")),
+		Genre:  strings.ToLower(strings.TrimSpace(params.Get("genre"))),
+		Author: strings.TrimSpace(params.Get("author")),
+		Decade: strings.TrimSpace(params.Get("decade")),
+		Tag:    strings.ToLower(strings.TrimSpace(params.Get("tag"))),
+	}
+	if q.Decade != "" {
+		if n, err := strconv.Atoi(q.Decade); err != nil || n < 0 || n%10 != 0 {
+			return q, &ValidationError{"Invalid query", validation.Errors{
+				{Field: "decade", Message: "must be a year ending in 0, like 1810"},
+			}}
+		}
+	}
+	return q, nil
+}
+
+// Params is the inverse of QueryFromParams.
+func (q Query) Params() url.Values {
+	params := url.Values{}
+	for name, value := range map[string]string{
+		"q": q.Text, "genre": q.Genre, "author": q.Author, "decade": q.Decade, "tag": q.Tag,
+	} {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
+	return params
+}
+
+// Filter is the MongoDB filter selecting the books of the query.
+func (q Query) Filter() bson.M {
+	filter := bson.M{}
+	if q.Text != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(q.Text), Options: "i"}
+		filter["$or"] = bson.A{bson.M{"bookname": pattern}, bson.M{"bookauthor": pattern}}
+	}
+	if q.Genre != "" {
+		filter["genres"] = q.Genre
+	}
+	if q.Author != "" {
+		filter["authors.name"] = q.Author
+	}
+	if q.Decade != "" {
+		// Years are stored as strings: the decade 1810 are the years
+		// matching ^181[0-9]$.
+		filter["bookyear"] = bson.M{"$regex": "^" + strings.TrimSuffix(q.Decade, "0") + "[0-9]$"}
+	}
+	if q.Tag != "" {
+		filter["tags"] = q.Tag
+	}
+	return filter
+}
+
+// Facets counts the books matching a query by genre, author and decade.
+// Each list is ordered by name.
+type Facets struct {
+	Genre  []Count `bson:"genre" json:"genre"`
+	Author []Count `bson:"author" json:"author"`
+	Decade []Count `bson:"decade" json:"decade"`
+}
+
+// Search returns the books matching q, ordered by title, and the facets of
+// them.
+func (r *Repository) Search(ctx context.Context, q Query) ([]books.Book, *Facets, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "bookname", Value: 1}, {Key: "id", Value: 1}})
+	cursor, err := r.coll.Find(ctx, q.Filter(), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	list := []books.Book{}
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, nil, err
+	}
+
+	facets, err := r.Facets(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return list, facets, nil
+}
+
+// Facets counts the books matching q by genre, author and decade, in a
+// single aggregation.
+func (r *Repository) Facets(ctx context.Context, q Query) (*Facets, error) {
+	cursor, err := r.coll.Aggregate(ctx, bson.A{
+		bson.M{"$match": q.Filter()},
+		bson.M{"$facet": bson.M{
+			"genre": facet(
+				bson.M{"$unwind": "$genres"},
+				bson.M{"$group": bson.M{"_id": "$genres", "books": bson.M{"$sum": 1}}},
+			),
+			// A book counts once per author, even if they have several
+			// roles in it.
+			"author": facet(
+				bson.M{"$unwind": "$authors"},
+				bson.M{"$group": bson.M{"_id": "$authors.name", "books": bson.M{"$addToSet": "$id"}}},
+				bson.M{"$set": bson.M{"books": bson.M{"$size": "$books"}}},
+			),
+			// Years are strings: the decade of "1813" is "1810".
+			"decade": facet(
+				bson.M{"$match": bson.M{"bookyear": bson.M{"$regex": "^[0-9]+$"}}},
+				bson.M{"$group": bson.M{
+					"_id": bson.M{"$concat": bson.A{
+						bson.M{"$substrCP": bson.A{"$bookyear", 0, bson.M{"$subtract": bson.A{bson.M{"$strLenCP": "$bookyear"}, 1}}}},
+						"0",
+					}},
+					"books": bson.M{"$sum": 1},
+				}},
+			),
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// $facet always answers with a single document.
+	var result []Facets
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+	facets := Facets{[]Count{}, []Count{}, []Count{}}
+	if len(result) > 0 {
+		facets = result[0]
+	}
+	return &facets, nil
+}
+
+// facet is the pipeline of a facet: stages grouping the books into counts,
+// followed by the sort by name.
+func facet(stages ...bson.M) bson.A {
+	ret := bson.A{}
+	for _, s := range stages {
+		ret = append(ret, s)
+	}
+	return append(ret, bson.M{"$sort": bson.M{"_id": 1}})
+}
+
+// SearchHandler serves GET /api/search. It answers with the matching books
+// and their facets, for the query parameters of QueryFromParams.
+func SearchHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		q, err := QueryFromParams(c.QueryParams())
+		var invalid *ValidationError
+		if errors.As(err, &invalid) {
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		}
+
+		list, facets, err := repo.Search(c.Request().Context(), q)
+		if err != nil {
+			return problem.Internal("Failed to search books", err)
+		}
+		if err := priced(c, repo, list); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"books":  booksAPI(list),
+			"facets": facets,
+		})
+	}
+}
+
+func booksAPI(list []books.Book) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(list))
+	for _, b := range list {
+		ret = append(ret, b.API())
+	}
+	return ret
+}
+
+// GenresHandler serves GET /api/genres, the genres books can be filed
+// under.
+func GenresHandler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, books.Genres)
+	}
+}
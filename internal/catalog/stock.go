@@ -0,0 +1,109 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrOutOfStock is returned for adjustments that would take the stock of a
+// book below 0.
+var ErrOutOfStock = errors.New("not enough copies in stock")
+
+// AdjustStock adds delta to the stock of the book with the given id and
+// records the movement with its reason. The check that enough copies are
+// left and the change are a single atomic update, so concurrent adjustments
+// can never take the stock below 0; those that would fail with
+// ErrOutOfStock.
+func (r *Repository) AdjustStock(ctx context.Context, bookID string, delta int, reason string) (*books.StockMovement, error) {
+	movement := books.StockMovement{BookID: bookID, Delta: delta, Reason: reason}
+	if err := validation.Struct(movement); err != nil {
+		var fields validation.Errors
+		if errors.As(err, &fields) {
+			return nil, &ValidationError{"Invalid stock adjustment", fields}
+		}
+		return nil, err
+	}
+
+	err := r.box.Transaction(ctx, func(ctx context.Context) error {
+		return r.adjustStock(ctx, &movement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &movement, nil
+}
+
+// adjustStock applies a validated movement and records it, setting its
+// stock and time. Call it inside a transaction with the context passed to
+// the transaction function.
+func (r *Repository) adjustStock(ctx context.Context, movement *books.StockMovement) error {
+	filter := bson.M{"id": movement.BookID}
+	if movement.Delta < 0 {
+		filter["stock"] = bson.M{"$gte": -movement.Delta}
+	}
+	var book books.Book
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.coll.FindOneAndUpdate(ctx, filter, bson.M{"$inc": bson.M{"stock": movement.Delta}}, opts).Decode(&book)
+	if err == mongo.ErrNoDocuments {
+		if _, err := r.Get(ctx, movement.BookID); err != nil {
+			return err
+		}
+		return ErrOutOfStock
+	}
+	if err != nil {
+		return err
+	}
+
+	movement.Stock = book.Stock
+	movement.Time = time.Now().UTC().Truncate(time.Millisecond)
+	if _, err := r.movements.InsertOne(ctx, movement); err != nil {
+		return err
+	}
+	if err := r.box.Add(ctx, events.StockAdjusted, movement.API()); err != nil {
+		return err
+	}
+	return r.box.Add(ctx, events.BookUpdated, book.API())
+}
+
+// Movements returns the stock movements of the book with the given id,
+// newest first.
+func (r *Repository) Movements(ctx context.Context, bookID string) ([]books.StockMovement, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "time", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.movements.Find(ctx, bson.M{"book_id": bookID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := []books.StockMovement{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// LowStock returns the books whose stock is at or below their reorder
+// level, the lowest stock first.
+func (r *Repository) LowStock(ctx context.Context) ([]books.Book, error) {
+	filter := bson.M{
+		"reorder_level": bson.M{"$gt": 0},
+		// A missing stock is 0.
+		"$expr": bson.M{"$lte": bson.A{bson.M{"$ifNull": bson.A{"$stock", 0}}, "$reorder_level"}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "stock", Value: 1}, {Key: "bookname", Value: 1}})
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := []books.Book{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
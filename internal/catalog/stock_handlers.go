@@ -0,0 +1,98 @@
+package catalog
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/content"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/labstack/echo/v4"
+)
+
+// The REST handlers of the stock of books. Like /api/orders they only speak
+// JSON, but accept JSON, MessagePack and form bodies.
+
+// RegisterStock adds the stock endpoints to e. Only the monolith and the
+// root service serve them.
+func RegisterStock(e *echo.Echo, repo *Repository) {
+	e.GET("/api/books/:id/stock", StockHandler(repo))
+	e.POST("/api/books/:id/stock", AdjustStockHandler(repo))
+	e.GET("/api/stock/low", LowStockHandler(repo))
+}
+
+// StockRequest is the body of POST /api/books/:id/stock.
+type StockRequest struct {
+	Delta  int    `json:"delta" msgpack:"delta"`
+	Reason string `json:"reason" msgpack:"reason"`
+}
+
+func movementsAPI(list []books.StockMovement) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(list))
+	for _, m := range list {
+		ret = append(ret, m.API())
+	}
+	return ret
+}
+
+// StockHandler serves GET /api/books/:id/stock: the stock of the book, its
+// reorder level and its movements, newest first.
+func StockHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		book, err := repo.Get(c.Request().Context(), c.Param("id"))
+		if err == books.ErrNotFound {
+			return problem.NotFound("Book not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to get stock", err)
+		}
+		list, err := repo.Movements(c.Request().Context(), book.ID)
+		if err != nil {
+			return problem.Internal("Failed to get stock", err)
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"stock":        book.Stock,
+			"reorderLevel": book.ReorderLevel,
+			"movements":    movementsAPI(list),
+		})
+	}
+}
+
+// AdjustStockHandler serves POST /api/books/:id/stock. It answers with the
+// movement and the new stock, and 409 if there are not enough copies.
+func AdjustStockHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req StockRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+
+		movement, err := repo.AdjustStock(c.Request().Context(), c.Param("id"), req.Delta, req.Reason)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == books.ErrNotFound:
+			return problem.NotFound("Book not found")
+		case err == ErrOutOfStock:
+			return problem.Conflict("Not enough copies in stock")
+		case err != nil:
+			return problem.Internal("Failed to adjust stock", err)
+		}
+		return c.JSON(http.StatusOK, movement.API())
+	}
+}
+
+// LowStockHandler serves GET /api/stock/low, the books to reorder.
+func LowStockHandler(repo *Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		list, err := repo.LowStock(c.Request().Context())
+		if err != nil {
+			return problem.Internal("Failed to list books low on stock", err)
+		}
+		if err := priced(c, repo, list); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, booksAPI(list))
+	}
+}
@@ -0,0 +1,125 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/content"
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TagsRequest is the body of POST /api/books/:id/tags. Empty tags are
+// dropped, but at least one has to remain.
+type TagsRequest struct {
+	Tags []string `json:"tags" msgpack:"tags" validate:"max=50"`
+}
+
+// AddTags adds tags to the book with the given id, keeping the tags it
+// already has, and returns the updated book.
+func (r *Repository) AddTags(ctx context.Context, id string, tags []string) (*books.Book, error) {
+	if err := validation.Struct(TagsRequest{tags}); err != nil {
+		var fields validation.Errors
+		if errors.As(err, &fields) {
+			return nil, &ValidationError{"Invalid tags", fields}
+		}
+		return nil, err
+	}
+	tags = books.NormalizeTags(tags)
+	if len(tags) == 0 {
+		return nil, &ValidationError{"Invalid tags", validation.Errors{
+			{Field: "tags", Message: "is required"},
+		}}
+	}
+	return r.updateTags(ctx, id, bson.M{"$addToSet": bson.M{"tags": bson.M{"$each": tags}}})
+}
+
+// RemoveTag removes a tag from the book with the given id and returns the
+// updated book. Removing a tag the book does not have is no error.
+func (r *Repository) RemoveTag(ctx context.Context, id, tag string) (*books.Book, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	return r.updateTags(ctx, id, bson.M{"$pull": bson.M{"tags": tag}})
+}
+
+// updateTags applies update to the book with the given id and records the
+// change in the outbox.
+func (r *Repository) updateTags(ctx context.Context, id string, update bson.M) (*books.Book, error) {
+	var book books.Book
+	err := r.box.Transaction(ctx, func(ctx context.Context) error {
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err := r.coll.FindOneAndUpdate(ctx, bson.M{"id": id}, update, opts).Decode(&book)
+		if err == mongo.ErrNoDocuments {
+			return books.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return r.box.Add(ctx, events.BookUpdated, book.API())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// AddTagsHandler serves POST /api/books/:id/tags. It answers with the book
+// and its tags.
+func AddTagsHandler(repo *Repository) echo.HandlerFunc {
+	return content.Negotiate(func(c echo.Context) error {
+		var req TagsRequest
+		if err := content.Decode(c, &req); err != nil {
+			return invalidBody(err)
+		}
+		if err := checkCurrency(c, repo); err != nil {
+			return err
+		}
+
+		book, err := repo.AddTags(c.Request().Context(), c.Param("id"), req.Tags)
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return problem.Validation(invalid.Message, invalid.Fields...)
+		case err == books.ErrNotFound:
+			return problem.NotFound("Book not found")
+		case err != nil:
+			return problem.Internal("Failed to tag book", err)
+		}
+		return writeBook(c, repo, http.StatusOK, *book)
+	})
+}
+
+// RemoveTagHandler serves DELETE /api/books/:id/tags/:tag. It answers with
+// the book and its remaining tags.
+func RemoveTagHandler(repo *Repository) echo.HandlerFunc {
+	return content.Negotiate(func(c echo.Context) error {
+		if err := checkCurrency(c, repo); err != nil {
+			return err
+		}
+
+		book, err := repo.RemoveTag(c.Request().Context(), c.Param("id"), c.Param("tag"))
+		switch {
+		case err == books.ErrNotFound:
+			return problem.NotFound("Book not found")
+		case err != nil:
+			return problem.Internal("Failed to untag book", err)
+		}
+		return writeBook(c, repo, http.StatusOK, *book)
+	})
+}
+
+// RegisterSearch adds the tag, search and genre endpoints to e. Only the
+// monolith and the root service serve them.
+func RegisterSearch(e *echo.Echo, repo *Repository) {
+	e.GET("/api/search", SearchHandler(repo))
+	e.GET("/api/genres", GenresHandler())
+	e.POST("/api/books/:id/tags", AddTagsHandler(repo))
+	e.DELETE("/api/books/:id/tags/:tag", RemoveTagHandler(repo))
+}
@@ -0,0 +1,507 @@
+// Package content implements content negotiation for the /api/books
+// endpoints. Responses honour the Accept header and request bodies the
+// Content-Type header; both understand JSON, protobuf (the bookpb
+// messages), MessagePack and XML. JSON stays the default when a client does
+// not say what it wants or prefers nothing else.
+package content
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/CAPS-Cloud/exercises/internal/bookpb"
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"github.com/gogo/protobuf/proto"
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Supported media types.
+const (
+	JSON     = "application/json"
+	Protobuf = "application/x-protobuf"
+	Msgpack  = "application/msgpack"
+	XML      = "application/xml"
+)
+
+// Supported lists the media types in order of preference.
+var Supported = []string{JSON, Protobuf, Msgpack, XML}
+
+// aliases maps other common names onto the supported media types.
+var aliases = map[string]string{
+	"application/protobuf":  Protobuf,
+	"application/x-msgpack": Msgpack,
+	"text/xml":              XML,
+}
+
+// HTML forms are still accepted as request bodies, as they were before
+// negotiation was added.
+const form = echo.MIMEApplicationForm
+
+// ErrUnsupported is returned when decoding a body of an unsupported type.
+// The error is an *UnsupportedError telling the types that are read.
+var ErrUnsupported = errors.New("unsupported media type")
+
+// bodyTypes are the types of the bodies DecodeBook reads, decodeTypes those
+// Decode reads.
+var (
+	bodyTypes   = append(slices.Clone(Supported), form)
+	decodeTypes = []string{JSON, Msgpack, form}
+)
+
+// UnsupportedError is the error of a body of an unsupported type. It
+// matches ErrUnsupported.
+type UnsupportedError struct {
+	// Supported lists the types that would have been read.
+	Supported []string
+}
+
+func (e *UnsupportedError) Error() string {
+	return ErrUnsupported.Error()
+}
+
+// Is reports whether target is ErrUnsupported.
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+const contextKey = "content.type"
+
+func canonical(mediaType string) string {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if alias, ok := aliases[mediaType]; ok {
+		return alias
+	}
+	return mediaType
+}
+
+// accepted picks the response type for an Accept header. ok is false when
+// none of the supported types is acceptable. The type with the highest
+// quality wins, JSON on ties, and the wildcards stand for JSON. XML is only
+// picked when the client prefers it to everything else: browsers list it
+// below text/html and get JSON.
+func accepted(header string) (mediaType string, ok bool) {
+	if strings.TrimSpace(header) == "" {
+		return JSON, true
+	}
+
+	// q holds the quality of the supported types, other the best quality
+	// of every type but XML.
+	q := make(map[string]float64)
+	other := 0.0
+	for _, part := range strings.Split(header, ",") {
+		mt, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		weight := 1.0
+		if v, ok := params["q"]; ok {
+			if weight, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if weight <= 0 {
+			continue
+		}
+
+		switch mt = canonical(mt); mt {
+		case "*/*", "application/*":
+			mt = JSON
+		case "text/*":
+			mt = XML
+		}
+		if slices.Contains(Supported, mt) {
+			q[mt] = max(q[mt], weight)
+		}
+		if mt != XML {
+			other = max(other, weight)
+		}
+	}
+
+	for _, mt := range Supported {
+		if q[mt] > q[mediaType] {
+			mediaType = mt
+		}
+	}
+	switch {
+	case mediaType == "":
+		return "", false
+	case mediaType == XML && other >= q[XML]:
+		return JSON, true
+	}
+	return mediaType, true
+}
+
+// requestType returns the media type of the request body. A missing
+// Content-Type is read as JSON.
+func requestType(c echo.Context) string {
+	header := c.Request().Header.Get(echo.HeaderContentType)
+	if header == "" {
+		return JSON
+	}
+	mt, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return ""
+	}
+	return canonical(mt)
+}
+
+// Negotiate is a middleware answering 406 Not Acceptable when none of the
+// supported types is acceptable, and 415 Unsupported Media Type for request
+// bodies of another type. It runs before the handler, so nothing is written
+// to the database for a request that cannot be answered. Errors are problem
+// details, see the problem package.
+func Negotiate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		mt, ok := accepted(c.Request().Header.Get(echo.HeaderAccept))
+		if !ok {
+			return problem.New(http.StatusNotAcceptable,
+				"Supported types: "+strings.Join(Supported, ", "))
+		}
+		c.Set(contextKey, mt)
+
+		req := c.Request()
+		if req.ContentLength != 0 && (req.Method == http.MethodPost || req.Method == http.MethodPut) {
+			if rt := requestType(c); !slices.Contains(bodyTypes, rt) {
+				return problem.New(http.StatusUnsupportedMediaType,
+					"Supported types: "+strings.Join(bodyTypes, ", "))
+			}
+		}
+
+		return next(c)
+	}
+}
+
+// responseType is the type picked by Negotiate, JSON outside of it.
+func responseType(c echo.Context) string {
+	if mt, ok := c.Get(contextKey).(string); ok {
+		return mt
+	}
+	return JSON
+}
+
+// write encodes the response in the negotiated type. asJSON is used for JSON
+// and MessagePack, asProto for protobuf and asXML for XML.
+func write(c echo.Context, status int, asJSON interface{}, asProto proto.Message, asXML func(*xml.Encoder) error) error {
+	res := c.Response()
+	switch mt := responseType(c); mt {
+	case Protobuf:
+		data, err := proto.Marshal(asProto)
+		if err != nil {
+			return err
+		}
+		return c.Blob(status, Protobuf, data)
+	case Msgpack:
+		data, err := msgpack.Marshal(asJSON)
+		if err != nil {
+			return err
+		}
+		return c.Blob(status, Msgpack, data)
+	case XML:
+		res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationXMLCharsetUTF8)
+		res.WriteHeader(status)
+		if _, err := res.Write([]byte(xml.Header)); err != nil {
+			return err
+		}
+		return asXML(xml.NewEncoder(res))
+	default:
+		return c.JSON(status, asJSON)
+	}
+}
+
+func element(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+// Book writes a single book.
+func Book(c echo.Context, status int, b books.Book) error {
+	return write(c, status, b.API(), bookpb.FromBook(b), func(enc *xml.Encoder) error {
+		return enc.EncodeElement(b, element("book"))
+	})
+}
+
+// Books writes a list of books.
+func Books(c echo.Context, status int, list []books.Book) error {
+	asJSON := make([]map[string]interface{}, 0, len(list))
+	asProto := &bookpb.BookList{}
+	for _, b := range list {
+		asJSON = append(asJSON, b.API())
+		asProto.Books = append(asProto.Books, bookpb.FromBook(b))
+	}
+	return write(c, status, asJSON, asProto, func(enc *xml.Encoder) error {
+		wrapper := struct {
+			Books []books.Book `xml:"book"`
+		}{list}
+		return enc.EncodeElement(wrapper, element("books"))
+	})
+}
+
+// DecodeBook reads a book from the request body in the client-side format,
+// according to its Content-Type. Fields missing from the body are left
+// empty. Fields the API does not know and values of the wrong type are
+// rejected with a validation.Errors naming each of them; the book itself is
+// validated by the catalog.
+func DecodeBook(c echo.Context) (books.Book, error) {
+	body := c.Request().Body
+
+	switch requestType(c) {
+	case XML:
+		var req struct {
+			books.Request
+			Unknown []struct {
+				XMLName xml.Name
+			} `xml:",any"`
+		}
+		if err := xml.NewDecoder(body).Decode(&req); err != nil {
+			return books.Book{}, err
+		}
+		var errs validation.Errors
+		for _, el := range req.Unknown {
+			errs = append(errs, validation.FieldError{Field: el.XMLName.Local, Message: "is not a known field"})
+		}
+		if len(errs) > 0 {
+			return books.Book{}, errs
+		}
+		return req.Book(), nil
+	case Protobuf:
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return books.Book{}, err
+		}
+		var msg bookpb.Book
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return books.Book{}, err
+		}
+		return msg.ToBook(), nil
+	}
+
+	var req books.Request
+	if err := Decode(c, &req); errors.Is(err, ErrUnsupported) {
+		return books.Book{}, &UnsupportedError{Supported: bodyTypes}
+	} else if err != nil {
+		return books.Book{}, err
+	}
+	return req.Book(), nil
+}
+
+// Decode reads a JSON, MessagePack or form body into v, a pointer to a
+// struct like books.Request, checking its fields like DecodeBook does. A
+// null value is read as an empty one. Lists of strings in forms are
+// repeated keys or values separated by commas. Other types of body are
+// ErrUnsupported.
+func Decode(c echo.Context, v interface{}) error {
+	var fields map[string]interface{}
+	body := c.Request().Body
+
+	switch requestType(c) {
+	case JSON:
+		if err := json.NewDecoder(body).Decode(&fields); err != nil && err != io.EOF {
+			return err
+		}
+	case form:
+		req := c.Request()
+		if err := req.ParseForm(); err != nil {
+			return err
+		}
+		rt := reflect.TypeOf(v).Elem()
+		index := fieldIndex(rt)
+		fields = make(map[string]interface{}, len(req.PostForm))
+		for name, values := range req.PostForm {
+			i, ok := index[name]
+			if !ok || rt.Field(i).Type.Kind() != reflect.Slice {
+				fields[name] = req.PostForm.Get(name)
+				continue
+			}
+			// Lists are given as repeated keys or separated by commas.
+			var list []interface{}
+			for _, value := range values {
+				for _, item := range strings.Split(value, ",") {
+					if item = strings.TrimSpace(item); item != "" {
+						list = append(list, item)
+					}
+				}
+			}
+			fields[name] = list
+		}
+	case Msgpack:
+		if err := msgpack.NewDecoder(body).Decode(&fields); err != nil && err != io.EOF {
+			return err
+		}
+	default:
+		return &UnsupportedError{Supported: decodeTypes}
+	}
+
+	if errs := setFields(reflect.ValueOf(v).Elem(), fields, ""); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// fieldIndex maps the names of the fields of a struct type to their index.
+func fieldIndex(rt reflect.Type) map[string]int {
+	ret := map[string]int{}
+	for i := 0; i < rt.NumField(); i++ {
+		ret[validation.FieldName(rt.Field(i))] = i
+	}
+	return ret
+}
+
+// setFields sets the fields of the struct rv from their decoded values:
+// strings, numbers, lists of strings, lists of objects for slices of
+// structs, or objects for structs. Errors are named after prefix and the
+// field.
+func setFields(rv reflect.Value, fields map[string]interface{}, prefix string) validation.Errors {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	index := fieldIndex(rv.Type())
+	var errs validation.Errors
+	for _, name := range names {
+		i, ok := index[name]
+		if !ok {
+			errs = append(errs, validation.FieldError{Field: prefix + name, Message: "is not a known field"})
+			continue
+		}
+		field := rv.Field(i)
+		if isInt(field.Kind()) {
+			// JSON and MessagePack numbers, or the strings of forms.
+			n, ok := wholeNumber(fields[name])
+			if !ok {
+				errs = append(errs, validation.FieldError{Field: prefix + name, Message: "must be a whole number"})
+				continue
+			}
+			field.SetInt(n)
+			continue
+		}
+		if field.Kind() == reflect.Float64 {
+			f, ok := number(fields[name])
+			if !ok {
+				errs = append(errs, validation.FieldError{Field: prefix + name, Message: "must be a number"})
+				continue
+			}
+			field.SetFloat(f)
+			continue
+		}
+		switch v := fields[name].(type) {
+		case nil:
+		case string:
+			if field.Kind() != reflect.String {
+				errs = append(errs, validation.FieldError{Field: prefix + name, Message: expected(field)})
+				continue
+			}
+			field.SetString(v)
+		case []interface{}:
+			if field.Kind() != reflect.Slice {
+				errs = append(errs, validation.FieldError{Field: prefix + name, Message: expected(field)})
+				continue
+			}
+			list := reflect.MakeSlice(field.Type(), len(v), len(v))
+			for j, elem := range v {
+				elemName := fmt.Sprintf("%s%s[%d]", prefix, name, j)
+				if field.Type().Elem().Kind() == reflect.String {
+					str, ok := elem.(string)
+					if !ok {
+						errs = append(errs, validation.FieldError{Field: elemName, Message: "must be a string"})
+						continue
+					}
+					list.Index(j).SetString(str)
+					continue
+				}
+				obj, ok := elem.(map[string]interface{})
+				if !ok {
+					errs = append(errs, validation.FieldError{Field: elemName, Message: "must be an object"})
+					continue
+				}
+				errs = append(errs, setFields(list.Index(j), obj, elemName+".")...)
+			}
+			field.Set(list)
+		case map[string]interface{}:
+			if field.Kind() != reflect.Struct {
+				errs = append(errs, validation.FieldError{Field: prefix + name, Message: expected(field)})
+				continue
+			}
+			errs = append(errs, setFields(field, v, prefix+name+".")...)
+		default:
+			errs = append(errs, validation.FieldError{Field: prefix + name, Message: expected(field)})
+		}
+	}
+	return errs
+}
+
+// expected tells what kind of value field takes.
+func expected(field reflect.Value) string {
+	switch field.Kind() {
+	case reflect.Slice:
+		return "must be a list"
+	case reflect.Struct:
+		return "must be an object"
+	}
+	return "must be a string"
+}
+
+func isInt(k reflect.Kind) bool {
+	return k == reflect.Int || k == reflect.Int32 || k == reflect.Int64
+}
+
+// wholeNumber reads a decoded value as a whole number. null and the empty
+// inputs of forms are read as 0.
+func wholeNumber(v interface{}) (int64, bool) {
+	if v == nil {
+		return 0, true
+	}
+	if s, ok := v.(string); ok {
+		if s = strings.TrimSpace(s); s == "" {
+			return 0, true
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		return n, err == nil
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return rv.Int(), true
+	case rv.CanUint():
+		return int64(rv.Uint()), rv.Uint() <= math.MaxInt64
+	case rv.CanFloat():
+		f := rv.Float()
+		return int64(f), f == math.Trunc(f) && math.Abs(f) < 1<<53
+	}
+	return 0, false
+}
+
+// number reads a decoded value as a number. null is read as 0.
+func number(v interface{}) (float64, bool) {
+	if v == nil {
+		return 0, true
+	}
+	if s, ok := v.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return f, err == nil
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int()), true
+	case rv.CanUint():
+		return float64(rv.Uint()), true
+	case rv.CanFloat():
+		return rv.Float(), true
+	}
+	return 0, false
+}
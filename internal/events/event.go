@@ -0,0 +1,59 @@
+// Package events turns the book changes recorded in the outbox into a stream
+// of book events. Every service sharing the database records its changes in
+// the same outbox, so the feed sees their writes, not only those of the
+// local process.
+package events
+
+import (
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event types.
+const (
+	BookCreated = "book.created"
+	BookUpdated = "book.updated"
+	BookDeleted = "book.deleted"
+
+	AuthorCreated = "author.created"
+	AuthorUpdated = "author.updated"
+	AuthorDeleted = "author.deleted"
+
+	ReviewCreated = "review.created"
+	ReviewUpdated = "review.updated"
+	ReviewDeleted = "review.deleted"
+
+	StockAdjusted = "stock.adjusted"
+
+	PriceRuleCreated = "price_rule.created"
+	PriceRuleUpdated = "price_rule.updated"
+	PriceRuleDeleted = "price_rule.deleted"
+
+	OrderCreated = "order.created"
+	OrderUpdated = "order.updated"
+
+	CopyAdded     = "copy.added"
+	CopyRemoved   = "copy.removed"
+	LoanCreated   = "loan.created"
+	LoanReturned  = "loan.returned"
+	HoldPlaced    = "hold.placed"
+	HoldReady     = "hold.ready"
+	HoldCancelled = "hold.cancelled"
+)
+
+// Event is a single change of the catalog.
+type Event struct {
+	// Seq is the sequence number of the outbox entry of the event. It
+	// increases with every entry, including those of other kinds, so it
+	// skips numbers between book events.
+	Seq  uint64
+	Type string
+	Time time.Time
+	// MongoID identifies the changed document; the HTML views use it for
+	// their row ids.
+	MongoID primitive.ObjectID
+	// Book is the book after the change. For deletions only the id is set.
+	Book books.Book
+}
@@ -0,0 +1,353 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/outbox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultPollInterval is how often a feed reads the outbox without being
+// woken up by a change stream, e.g. on a standalone MongoDB server.
+const DefaultPollInterval = 2 * time.Second
+
+// subscriberBuffer is the number of events a subscriber may lag behind
+// before it is dropped.
+const subscriberBuffer = 64
+
+// maxMissed is the most events a resuming client is sent. Clients that
+// missed more start over.
+const maxMissed = 1024
+
+// feedBatch is the number of outbox entries read at once.
+const feedBatch = 256
+
+// gapTimeout is how long the feed waits for a missing sequence number.
+// Without transactions an entry becomes visible shortly after its sequence
+// number was handed out, or never when the handler died in between.
+const gapTimeout = 5 * time.Second
+
+// Feed publishes the book events of an outbox to its subscribers.
+//
+// Events carry the sequence number of their outbox entry. The numbers are
+// stored in the database, so they survive restarts and mean the same on
+// every instance: a client can resume on any of them as long as the entries
+// it missed are kept, see outbox.Retention. Writes bypassing the catalog
+// repository, like seeding and migrations, are not in the outbox and not
+// published.
+type Feed struct {
+	box          *outbox.Outbox
+	coll         *mongo.Collection
+	PollInterval time.Duration
+
+	mu     sync.Mutex
+	stream string
+	seq    uint64
+	subs   map[chan Event]struct{}
+
+	// Since when the goroutine running the feed waits for a missing
+	// sequence number.
+	gapSince time.Time
+
+	// Mongo ids of the books by book id. Outbox entries only carry the
+	// book, the HTML views need its Mongo id.
+	idsMu sync.Mutex
+	ids   map[string]primitive.ObjectID
+}
+
+// NewFeed returns a feed for the book events recorded in box. coll is the
+// catalog collection the Mongo ids of the books are looked up in. Call Run
+// to start it.
+func NewFeed(box *outbox.Outbox, coll *mongo.Collection) *Feed {
+	return &Feed{
+		box:          box,
+		coll:         coll,
+		PollInterval: DefaultPollInterval,
+		subs:         make(map[chan Event]struct{}),
+		ids:          make(map[string]primitive.ObjectID),
+	}
+}
+
+// Stream identifies the sequence the event numbers belong to, so a client
+// can tell whether the sequence number it wants to resume from still means
+// anything. It is empty until the feed runs.
+func (f *Feed) Stream() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stream
+}
+
+// Subscribe returns a channel receiving every event published from now on,
+// and a function to cancel the subscription. seq is the sequence number the
+// feed is at. A subscriber that falls too far behind has its channel closed.
+func (f *Feed) Subscribe() (seq uint64, events <-chan Event, cancel func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	events, cancel = f.subscribe()
+	return f.seq, events, cancel
+}
+
+// SubscribeFrom is like Subscribe, but also returns the events published
+// after since, read back from the outbox. ok is false when those events are
+// no longer available, in which case nothing is subscribed.
+func (f *Feed) SubscribeFrom(ctx context.Context, since uint64) (missed []Event, events <-chan Event, cancel func(), ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.stream == "" || since > f.seq {
+		return nil, nil, nil, false
+	}
+	if since < f.seq {
+		first, _, err := f.box.Range(ctx)
+		if err != nil {
+			log.Printf("events: reading the outbox failed: %v", err)
+			return nil, nil, nil, false
+		}
+		if uint64(first) > since+1 {
+			return nil, nil, nil, false
+		}
+
+		entries, err := f.box.After(ctx, int64(since), maxMissed)
+		if err != nil {
+			log.Printf("events: reading the outbox failed: %v", err)
+			return nil, nil, nil, false
+		}
+		if len(entries) == maxMissed && uint64(entries[len(entries)-1].Seq) < f.seq {
+			return nil, nil, nil, false
+		}
+		for _, entry := range entries {
+			if uint64(entry.Seq) > f.seq {
+				break
+			}
+			if ev := f.event(ctx, entry); ev != nil {
+				missed = append(missed, *ev)
+			}
+		}
+	}
+
+	events, cancel = f.subscribe()
+	return missed, events, cancel, true
+}
+
+// subscribe registers a new subscriber. f.mu must be held.
+func (f *Feed) subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	f.subs[ch] = struct{}{}
+
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.subs[ch]; ok {
+			delete(f.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// advance moves the feed to seq and publishes ev, if any.
+func (f *Feed) advance(seq uint64, ev *Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.seq = seq
+	if ev == nil {
+		return
+	}
+	for ch := range f.subs {
+		select {
+		case ch <- *ev:
+		default:
+			delete(f.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Run feeds events until ctx is cancelled. It starts at the latest entry of
+// the outbox and reads new ones whenever a change stream reports them, and
+// every PollInterval in case it does not.
+func (f *Feed) Run(ctx context.Context) error {
+	if err := f.start(ctx); err != nil {
+		return err
+	}
+
+	wake := make(chan struct{}, 1)
+	go f.watch(ctx, wake)
+
+	ticker := time.NewTicker(f.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.catchUp(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("events: reading the outbox failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+func (f *Feed) start(ctx context.Context) error {
+	stream, err := f.box.Stream(ctx)
+	if err != nil {
+		return err
+	}
+	_, last, err := f.box.Range(ctx)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := f.coll.Find(ctx, bson.D{}, options.Find().SetProjection(bson.M{"_id": 1, "id": 1}))
+	if err != nil {
+		return err
+	}
+	var docs []struct {
+		MongoID primitive.ObjectID `bson:"_id"`
+		ID      string             `bson:"id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return err
+	}
+	f.idsMu.Lock()
+	for _, doc := range docs {
+		f.ids[doc.ID] = doc.MongoID
+	}
+	f.idsMu.Unlock()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stream = stream
+	f.seq = uint64(last)
+	return nil
+}
+
+// watch wakes the feed up whenever an entry is added to the outbox.
+func (f *Feed) watch(ctx context.Context, wake chan<- struct{}) {
+	for {
+		err := f.box.Watch(ctx, func() {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, outbox.ErrNoChangeStreams) {
+			log.Printf("events: change streams are not available, polling every %s", f.PollInterval)
+			return
+		}
+
+		log.Printf("events: change stream failed, retrying: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(f.PollInterval):
+		}
+	}
+}
+
+// catchUp publishes the entries following the current sequence number, in
+// order and without gaps unless a missing entry is overdue.
+func (f *Feed) catchUp(ctx context.Context) error {
+	f.mu.Lock()
+	seq := f.seq
+	f.mu.Unlock()
+
+	for {
+		entries, err := f.box.After(ctx, int64(seq), feedBatch)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if uint64(entry.Seq) != seq+1 {
+				if f.gapSince.IsZero() {
+					f.gapSince = time.Now()
+				}
+				if time.Since(f.gapSince) < gapTimeout {
+					return nil
+				}
+				log.Printf("events: skipping missing outbox entries %d to %d", seq+1, entry.Seq-1)
+			}
+			f.gapSince = time.Time{}
+
+			seq = uint64(entry.Seq)
+			f.advance(seq, f.event(ctx, entry))
+		}
+		if len(entries) < feedBatch {
+			return nil
+		}
+	}
+}
+
+// event returns the event for an outbox entry, or nil if it is not a book
+// event.
+func (f *Feed) event(ctx context.Context, entry outbox.Entry) *Event {
+	switch entry.Event {
+	case BookCreated, BookUpdated, BookDeleted:
+	default:
+		return nil
+	}
+
+	// The entry data is the API representation of the book, which has the
+	// same JSON form as books.Book.
+	var book books.Book
+	data, err := json.Marshal(entry.Data)
+	if err == nil {
+		err = json.Unmarshal(data, &book)
+	}
+	if err != nil {
+		log.Printf("events: skipping undecodable outbox entry %d: %v", entry.Seq, err)
+		return nil
+	}
+
+	return &Event{
+		Seq:     uint64(entry.Seq),
+		Type:    entry.Event,
+		Time:    entry.CreatedAt,
+		MongoID: f.mongoID(ctx, entry.Event, book.ID),
+		Book:    book,
+	}
+}
+
+// mongoID returns the Mongo id of a book. Created books are always looked
+// up, as a book id may be reused after a deletion; a deleted book is only
+// found in the cache.
+func (f *Feed) mongoID(ctx context.Context, event, id string) primitive.ObjectID {
+	f.idsMu.Lock()
+	oid, ok := f.ids[id]
+	f.idsMu.Unlock()
+	if ok && event != BookCreated {
+		return oid
+	}
+
+	var doc struct {
+		MongoID primitive.ObjectID `bson:"_id"`
+	}
+	err := f.coll.FindOne(ctx, bson.M{"id": id}, options.FindOne().SetProjection(bson.M{"_id": 1})).Decode(&doc)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Printf("events: looking up book %q failed: %v", id, err)
+		}
+		return oid
+	}
+
+	f.idsMu.Lock()
+	f.ids[id] = doc.MongoID
+	f.idsMu.Unlock()
+	return doc.MongoID
+}
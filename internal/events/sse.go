@@ -0,0 +1,78 @@
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// keepAlive is how often an idle event stream sends a comment, so proxies
+// do not close the connection.
+const keepAlive = 15 * time.Second
+
+// ServeSSE streams the events of f to the client as server-sent events until
+// the client disconnects. format renders the data of a single event, or nil
+// to skip it; the event name is the event type and the event id its
+// sequence number.
+func ServeSSE(c echo.Context, f *Feed, format func(Event) ([]byte, error)) error {
+	_, events, cancel := f.Subscribe()
+	defer cancel()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	// Tell nginx not to buffer the stream.
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+		case ev, ok := <-events:
+			if !ok {
+				// Too slow to keep up; the client reconnects and reloads.
+				return nil
+			}
+			data, err := format(ev)
+			if err != nil {
+				return err
+			}
+			if data == nil {
+				continue
+			}
+			if err := writeSSE(res, ev, data); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+func writeSSE(w *echo.Response, ev Event, data []byte) error {
+	var buf bytes.Buffer
+	buf.WriteString("id: " + strconv.FormatUint(ev.Seq, 10) + "\n")
+	buf.WriteString("event: " + ev.Type + "\n")
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
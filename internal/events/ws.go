@@ -0,0 +1,140 @@
+package events
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	pingInterval = 30 * time.Second
+	writeTimeout = 10 * time.Second
+)
+
+// Message types sent over the WebSocket besides the book event types.
+const (
+	// Hello is the first message of every connection. It carries the
+	// stream id and the current sequence number.
+	Hello = "hello"
+	// Reset tells the client that the requested events are gone. It has to
+	// reload the catalog from GET /api/books and continues from the seq of
+	// this message.
+	Reset = "reset"
+)
+
+// Message is the JSON document sent for every event.
+type Message struct {
+	Type   string      `json:"type"`
+	Seq    uint64      `json:"seq"`
+	Stream string      `json:"stream,omitempty"`
+	Time   *time.Time  `json:"time,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+// NewMessage converts an event into its wire format. Created and updated
+// books are sent in the shape of the REST API, deleted books only by id.
+func NewMessage(ev Event) Message {
+	var data interface{}
+	if ev.Type == BookDeleted {
+		data = map[string]interface{}{"id": ev.Book.ID}
+	} else {
+		data = ev.Book.API()
+	}
+	return Message{Type: ev.Type, Seq: ev.Seq, Time: &ev.Time, Data: data}
+}
+
+var upgrader = websocket.Upgrader{
+	// The API is public, like GET /api/books.
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// ServeWebSocket streams the events of f over a WebSocket. A client resumes
+// with ?stream=<id>&since=<seq>, using the values of the last message it
+// received; the events it missed are sent first.
+func ServeWebSocket(c echo.Context, f *Feed) error {
+	since, sinceErr := strconv.ParseUint(c.QueryParam("since"), 10, 64)
+	resume := c.QueryParam("since") != ""
+	if resume && sinceErr != nil {
+		return problem.Validation("Invalid query parameter",
+			problem.FieldError{Field: "since", Message: "must be a sequence number"})
+	}
+
+	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// The upgrader already answered the request.
+		return nil
+	}
+	defer conn.Close()
+
+	var missed []Event
+	var events <-chan Event
+	var cancel func()
+	ok := false
+	stream := f.Stream()
+	if resume && c.QueryParam("stream") == stream {
+		missed, events, cancel, ok = f.SubscribeFrom(c.Request().Context(), since)
+	}
+
+	first := Message{Type: Hello, Seq: since, Stream: stream}
+	if !ok {
+		first.Seq, events, cancel = f.Subscribe()
+		if resume {
+			first.Type = Reset
+		}
+	}
+	defer cancel()
+
+	if err := write(conn, first); err != nil {
+		return nil
+	}
+	for _, ev := range missed {
+		if err := write(conn, NewMessage(ev)); err != nil {
+			return nil
+		}
+	}
+
+	// The client never sends anything but control frames; reading is only
+	// needed to process them and to notice when the connection closes.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return nil
+		case <-ticker.C:
+			deadline := time.Now().Add(writeTimeout)
+			if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				return nil
+			}
+		case ev, ok := <-events:
+			if !ok {
+				msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow")
+				conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeTimeout))
+				return nil
+			}
+			if err := write(conn, NewMessage(ev)); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func write(conn *websocket.Conn, msg Message) error {
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return conn.WriteJSON(msg)
+}
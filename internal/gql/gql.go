@@ -0,0 +1,739 @@
+// Package gql serves the catalog over GraphQL at /graphql, next to the REST
+// API, so views like "authors with their books" need no route of their own.
+// Reads and writes go through the same repository as the REST handlers.
+package gql
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/catalog"
+	"github.com/CAPS-Cloud/exercises/internal/lending"
+	"github.com/CAPS-Cloud/exercises/internal/orders"
+	"github.com/CAPS-Cloud/exercises/internal/pricing"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+//go:embed graphiql.html
+var graphiqlPage []byte
+
+// Register adds the GraphQL endpoint to e. POST /graphql executes queries
+// and mutations; GET /graphql serves the GraphiQL page to explore them.
+func Register(e *echo.Echo, repo *catalog.Repository) {
+	schema := graphql.MustParseSchema(schemaSDL, &resolver{repo: repo})
+
+	e.GET("/graphql", func(c echo.Context) error {
+		return c.HTMLBlob(http.StatusOK, graphiqlPage)
+	})
+
+	e.POST("/graphql", func(c echo.Context) error {
+		var params struct {
+			Query         string                 `json:"query"`
+			OperationName string                 `json:"operationName"`
+			Variables     map[string]interface{} `json:"variables"`
+		}
+		if err := c.Bind(&params); err != nil {
+			return problem.Validation("Invalid request body")
+		}
+
+		response := schema.Exec(c.Request().Context(), params.Query, params.OperationName, params.Variables)
+		for _, qe := range response.Errors {
+			publicError(qe)
+		}
+		return c.JSON(http.StatusOK, response)
+	})
+}
+
+// publicError rewrites the error a resolver returned like the REST API
+// answers it. Validation errors, missing and conflicting documents keep
+// their message and get a code in the extensions, validation errors also
+// their fields. Other errors are logged and replaced by a fixed message, so
+// clients never see database details. Errors of the query itself are left
+// alone.
+func publicError(qe *gqlerrors.QueryError) {
+	err := qe.ResolverError
+	if err == nil {
+		return
+	}
+
+	var (
+		invalid    *catalog.ValidationError
+		transition *orders.TransitionError
+	)
+	switch {
+	case errors.As(err, &invalid):
+		qe.Message = invalid.Message
+		qe.Extensions = map[string]interface{}{"code": "BAD_USER_INPUT", "fields": invalid.Fields}
+	case errors.Is(err, books.ErrNotFound),
+		errors.Is(err, books.ErrAuthorNotFound),
+		errors.Is(err, books.ErrReviewNotFound),
+		errors.Is(err, orders.ErrCartNotFound),
+		errors.Is(err, orders.ErrOrderNotFound),
+		errors.Is(err, lending.ErrCopyNotFound),
+		errors.Is(err, lending.ErrHoldNotFound):
+		qe.Message = err.Error()
+		qe.Extensions = map[string]interface{}{"code": "NOT_FOUND"}
+	case errors.As(err, &transition),
+		errors.Is(err, catalog.ErrConflict),
+		errors.Is(err, catalog.ErrOutOfStock),
+		errors.Is(err, catalog.ErrAuthorConflict),
+		errors.Is(err, catalog.ErrAuthorInUse),
+		errors.Is(err, orders.ErrEmptyCart),
+		errors.Is(err, orders.ErrCartChanged),
+		errors.Is(err, lending.ErrBarcodeTaken),
+		errors.Is(err, lending.ErrUnavailable),
+		errors.Is(err, lending.ErrNotOnLoan),
+		errors.Is(err, lending.ErrOnLoan),
+		errors.Is(err, lending.ErrDuplicateHold),
+		errors.Is(err, lending.ErrHoldClosed):
+		qe.Message = err.Error()
+		qe.Extensions = map[string]interface{}{"code": "CONFLICT"}
+	default:
+		log.Printf("graphql: %v", err)
+		qe.Message = "internal error"
+		qe.Extensions = map[string]interface{}{"code": "INTERNAL_SERVER_ERROR"}
+	}
+}
+
+type resolver struct {
+	repo *catalog.Repository
+}
+
+type bookResolver struct {
+	b books.Book
+}
+
+func (r bookResolver) ID() graphql.ID  { return graphql.ID(r.b.ID) }
+func (r bookResolver) Title() string   { return r.b.BookName }
+func (r bookResolver) Author() string  { return r.b.BookAuthor }
+func (r bookResolver) Edition() string { return r.b.BookEdition }
+func (r bookResolver) Pages() string   { return r.b.BookPages }
+func (r bookResolver) Year() string    { return r.b.BookYear }
+func (r bookResolver) ISBN() string    { return r.b.ISBN }
+
+func (r bookResolver) Publisher() string { return r.b.Publisher }
+func (r bookResolver) Series() string    { return r.b.Series }
+func (r bookResolver) Volume() string    { return r.b.Volume }
+
+func (r bookResolver) Tags() []string   { return nonNil(r.b.Tags) }
+func (r bookResolver) Genres() []string { return nonNil(r.b.Genres) }
+
+func (r bookResolver) Rating() float64     { return r.b.Rating }
+func (r bookResolver) ReviewCount() int32  { return int32(r.b.ReviewCount) }
+func (r bookResolver) Stock() int32        { return int32(r.b.Stock) }
+func (r bookResolver) ReorderLevel() int32 { return int32(r.b.ReorderLevel) }
+func (r bookResolver) ListPrice() string   { return r.b.ListPrice }
+func (r bookResolver) Currency() string    { return r.b.Currency }
+func (r bookResolver) Copies() int32       { return int32(r.b.Copies) }
+func (r bookResolver) Available() int32    { return int32(r.b.Available) }
+
+type priceResolver struct{ p pricing.Price }
+
+func (r priceResolver) Amount() string    { return r.p.Amount }
+func (r priceResolver) Currency() string  { return r.p.Currency }
+func (r priceResolver) ListPrice() string { return r.p.ListPrice }
+func (r priceResolver) Rule() *graphql.ID {
+	if r.p.Rule == "" {
+		return nil
+	}
+	id := graphql.ID(r.p.Rule)
+	return &id
+}
+
+type priceRuleResolver struct{ r pricing.Rule }
+
+func (r priceRuleResolver) ID() graphql.ID   { return graphql.ID(r.r.ID) }
+func (r priceRuleResolver) Name() string     { return r.r.Name }
+func (r priceRuleResolver) Kind() string     { return r.r.Kind }
+func (r priceRuleResolver) Value() string    { return r.r.Value }
+func (r priceRuleResolver) Currency() string { return r.r.Currency }
+func (r priceRuleResolver) Tag() string      { return r.r.Tag }
+func (r priceRuleResolver) Author() string   { return r.r.Author }
+func (r priceRuleResolver) From() string     { return r.r.From }
+func (r priceRuleResolver) Until() string    { return r.r.Until }
+
+type orderResolver struct{ o orders.Order }
+
+func (r orderResolver) ID() graphql.ID     { return graphql.ID(r.o.ID) }
+func (r orderResolver) CartID() graphql.ID { return graphql.ID(r.o.CartID) }
+func (r orderResolver) Currency() string   { return r.o.Currency }
+func (r orderResolver) Total() string      { return r.o.Total }
+func (r orderResolver) Status() string     { return r.o.Status }
+func (r orderResolver) Created() string    { return r.o.Created.UTC().Format(time.RFC3339) }
+func (r orderResolver) Updated() string    { return r.o.Updated.UTC().Format(time.RFC3339) }
+func (r orderResolver) Lines() []orderLineResolver {
+	ret := make([]orderLineResolver, 0, len(r.o.Lines))
+	for _, l := range r.o.Lines {
+		ret = append(ret, orderLineResolver{l})
+	}
+	return ret
+}
+
+type orderLineResolver struct{ l orders.Line }
+
+func (r orderLineResolver) BookID() graphql.ID { return graphql.ID(r.l.BookID) }
+func (r orderLineResolver) Title() string      { return r.l.Title }
+func (r orderLineResolver) Quantity() int32    { return int32(r.l.Quantity) }
+func (r orderLineResolver) ListPrice() string  { return r.l.ListPrice }
+func (r orderLineResolver) UnitPrice() string  { return r.l.UnitPrice }
+func (r orderLineResolver) Total() string      { return r.l.Total }
+func (r orderLineResolver) Rule() *graphql.ID {
+	if r.l.Rule == "" {
+		return nil
+	}
+	id := graphql.ID(r.l.Rule)
+	return &id
+}
+
+type copyResolver struct{ c lending.Copy }
+
+func (r copyResolver) Barcode() string    { return r.c.Barcode }
+func (r copyResolver) BookID() graphql.ID { return graphql.ID(r.c.BookID) }
+func (r copyResolver) Status() string     { return r.c.Status }
+func (r copyResolver) Borrower() string   { return r.c.Borrower }
+func (r copyResolver) Added() string      { return r.c.Added.UTC().Format(time.RFC3339) }
+
+type loanResolver struct{ l lending.Loan }
+
+func (r loanResolver) ID() graphql.ID     { return graphql.ID(r.l.ID) }
+func (r loanResolver) Barcode() string    { return r.l.Barcode }
+func (r loanResolver) BookID() graphql.ID { return graphql.ID(r.l.BookID) }
+func (r loanResolver) Borrower() string   { return r.l.Borrower }
+func (r loanResolver) Borrowed() string   { return r.l.Borrowed.UTC().Format(time.RFC3339) }
+func (r loanResolver) Due() string        { return r.l.Due.UTC().Format(time.RFC3339) }
+func (r loanResolver) Overdue() bool      { return r.l.Overdue(time.Now()) }
+func (r loanResolver) Returned() *string {
+	if r.l.Returned == nil {
+		return nil
+	}
+	s := r.l.Returned.UTC().Format(time.RFC3339)
+	return &s
+}
+
+type reviewResolver struct{ r books.Review }
+
+func (r reviewResolver) ID() graphql.ID   { return graphql.ID(r.r.ID) }
+func (r reviewResolver) Reviewer() string { return r.r.Reviewer }
+func (r reviewResolver) Rating() int32    { return int32(r.r.Rating) }
+func (r reviewResolver) Text() string     { return r.r.Text }
+func (r reviewResolver) Created() string  { return r.r.Created.UTC().Format(time.RFC3339) }
+
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+func (r bookResolver) Authors() []contributorResolver {
+	list := r.b.Contributors()
+	ret := make([]contributorResolver, 0, len(list))
+	for _, c := range list {
+		ret = append(ret, contributorResolver{c})
+	}
+	return ret
+}
+
+type contributorResolver struct {
+	c books.Contributor
+}
+
+func (r contributorResolver) Name() string { return r.c.Name }
+func (r contributorResolver) Role() string { return r.c.Role }
+
+func (r contributorResolver) AuthorID() *graphql.ID {
+	if r.c.AuthorID == "" {
+		return nil
+	}
+	id := graphql.ID(r.c.AuthorID)
+	return &id
+}
+
+func resolveBooks(list []books.Book) []bookResolver {
+	ret := make([]bookResolver, 0, len(list))
+	for _, b := range list {
+		ret = append(ret, bookResolver{b})
+	}
+	return ret
+}
+
+// group collects the books by the value of key, ordered by that value.
+// Books with an empty value are left out.
+type group struct {
+	key   string
+	books []books.Book
+}
+
+// groupBy puts every book into the groups of its keys, once per group.
+func groupBy(list []books.Book, keys func(books.Book) []string) []group {
+	index := map[string]int{}
+	var ret []group
+	for _, b := range list {
+		for _, k := range keys(b) {
+			if k == "" {
+				continue
+			}
+			i, ok := index[k]
+			if !ok {
+				i = len(ret)
+				index[k] = i
+				ret = append(ret, group{key: k})
+			}
+			if n := len(ret[i].books); n > 0 && ret[i].books[n-1].ID == b.ID {
+				continue
+			}
+			ret[i].books = append(ret[i].books, b)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].key < ret[j].key })
+	return ret
+}
+
+type authorResolver struct{ group }
+
+func (r authorResolver) Name() string          { return r.key }
+func (r authorResolver) BookCount() int32      { return int32(len(r.books)) }
+func (r authorResolver) Books() []bookResolver { return resolveBooks(r.books) }
+
+type yearResolver struct{ group }
+
+func (r yearResolver) Year() string          { return r.key }
+func (r yearResolver) BookCount() int32      { return int32(len(r.books)) }
+func (r yearResolver) Books() []bookResolver { return resolveBooks(r.books) }
+
+// byAuthor groups by everybody who worked on a book, not only its authors.
+func byAuthor(b books.Book) []string {
+	var names []string
+	for _, c := range b.Contributors() {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func byYear(b books.Book) []string      { return []string{b.BookYear} }
+func byPublisher(b books.Book) []string { return []string{b.Publisher} }
+func bySeries(b books.Book) []string    { return []string{b.Series} }
+
+type publisherResolver struct{ group }
+
+func (r publisherResolver) Name() string          { return r.key }
+func (r publisherResolver) BookCount() int32      { return int32(len(r.books)) }
+func (r publisherResolver) Books() []bookResolver { return resolveBooks(r.books) }
+
+// seriesResolver holds the books of a series ordered by volume.
+type seriesResolver struct{ group }
+
+func (r seriesResolver) Name() string          { return r.key }
+func (r seriesResolver) BookCount() int32      { return int32(len(r.books)) }
+func (r seriesResolver) Books() []bookResolver { return resolveBooks(r.books) }
+
+func (r *resolver) Books(ctx context.Context, args struct {
+	Author *string
+	Year   *string
+	ISBN   *string
+	Genre  *string
+	Tag    *string
+}) ([]bookResolver, error) {
+	var list []books.Book
+	var err error
+	if args.ISBN != nil {
+		list, err = r.repo.FindByISBN(ctx, *args.ISBN)
+	} else {
+		list, err = r.repo.List(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []bookResolver
+	for _, b := range list {
+		if args.Author != nil && !slices.Contains(byAuthor(b), *args.Author) {
+			continue
+		}
+		if args.Year != nil && b.BookYear != *args.Year {
+			continue
+		}
+		if args.Genre != nil && !slices.Contains(b.Genres, strings.ToLower(*args.Genre)) {
+			continue
+		}
+		if args.Tag != nil && !slices.Contains(b.Tags, strings.ToLower(*args.Tag)) {
+			continue
+		}
+		ret = append(ret, bookResolver{b})
+	}
+	return ret, nil
+}
+
+func (r *resolver) Book(ctx context.Context, args struct{ ID graphql.ID }) (*bookResolver, error) {
+	b, err := r.repo.Get(ctx, string(args.ID))
+	if err == books.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bookResolver{*b}, nil
+}
+
+func (r *resolver) Authors(ctx context.Context) ([]authorResolver, error) {
+	list, err := r.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []authorResolver
+	for _, g := range groupBy(list, byAuthor) {
+		ret = append(ret, authorResolver{g})
+	}
+	return ret, nil
+}
+
+func (r *resolver) Author(ctx context.Context, args struct{ Name string }) (*authorResolver, error) {
+	authors, err := r.Authors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range authors {
+		if a.key == args.Name {
+			return &a, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *resolver) Years(ctx context.Context) ([]yearResolver, error) {
+	list, err := r.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []yearResolver
+	for _, g := range groupBy(list, byYear) {
+		ret = append(ret, yearResolver{g})
+	}
+	return ret, nil
+}
+
+func (r *resolver) Publishers(ctx context.Context) ([]publisherResolver, error) {
+	list, err := r.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []publisherResolver
+	for _, g := range groupBy(list, byPublisher) {
+		ret = append(ret, publisherResolver{g})
+	}
+	return ret, nil
+}
+
+func (r *resolver) Series(ctx context.Context) ([]seriesResolver, error) {
+	list, err := r.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []seriesResolver
+	for _, g := range groupBy(list, bySeries) {
+		books.SortByVolume(g.books)
+		ret = append(ret, seriesResolver{g})
+	}
+	return ret, nil
+}
+
+func (r *resolver) Genres() []string { return books.Genres }
+
+func (r *resolver) LowStock(ctx context.Context) ([]bookResolver, error) {
+	list, err := r.repo.LowStock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resolveBooks(list), nil
+}
+
+func (r *resolver) Price(ctx context.Context, args struct {
+	ID       graphql.ID
+	Currency *string
+}) (*priceResolver, error) {
+	b, err := r.repo.Get(ctx, string(args.ID))
+	if err != nil {
+		return nil, err
+	}
+	var currency string
+	if args.Currency != nil {
+		currency = *args.Currency
+	}
+	list := []books.Book{*b}
+	if err := r.repo.Price(ctx, currency, list); err != nil {
+		return nil, err
+	}
+	if list[0].EffectivePrice == nil {
+		return nil, nil
+	}
+	return &priceResolver{*list[0].EffectivePrice}, nil
+}
+
+func (r *resolver) PriceRules(ctx context.Context) ([]priceRuleResolver, error) {
+	list, err := r.repo.PriceRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]priceRuleResolver, 0, len(list))
+	for _, rule := range list {
+		ret = append(ret, priceRuleResolver{rule})
+	}
+	return ret, nil
+}
+
+func (r *resolver) Orders(ctx context.Context, args struct{ Status *string }) ([]orderResolver, error) {
+	var status string
+	if args.Status != nil {
+		status = *args.Status
+	}
+	list, err := r.repo.Orders(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]orderResolver, 0, len(list))
+	for _, o := range list {
+		ret = append(ret, orderResolver{o})
+	}
+	return ret, nil
+}
+
+func (r *resolver) Order(ctx context.Context, args struct{ ID graphql.ID }) (*orderResolver, error) {
+	o, err := r.repo.GetOrder(ctx, string(args.ID))
+	if err == orders.ErrOrderNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &orderResolver{*o}, nil
+}
+
+func (r *resolver) Copies(ctx context.Context, args struct{ BookID graphql.ID }) ([]copyResolver, error) {
+	list, err := r.repo.Copies(ctx, string(args.BookID))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]copyResolver, 0, len(list))
+	for _, c := range list {
+		ret = append(ret, copyResolver{c})
+	}
+	return ret, nil
+}
+
+func (r *resolver) OverdueLoans(ctx context.Context) ([]loanResolver, error) {
+	list, err := r.repo.Overdue(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]loanResolver, 0, len(list))
+	for _, l := range list {
+		ret = append(ret, loanResolver{l})
+	}
+	return ret, nil
+}
+
+func (r *resolver) Reviews(ctx context.Context, args struct{ BookID graphql.ID }) ([]reviewResolver, error) {
+	list, err := r.repo.ListReviews(ctx, string(args.BookID))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]reviewResolver, 0, len(list))
+	for _, review := range list {
+		ret = append(ret, reviewResolver{review})
+	}
+	return ret, nil
+}
+
+type bookInput struct {
+	ID        *graphql.ID
+	Title     *string
+	Author    *string
+	Edition   *string
+	Pages     *string
+	Year      *string
+	ISBN      *string
+	Authors   *[]contributorInput
+	Publisher *string
+	Series    *string
+	Volume    *string
+	Tags      *[]string
+	Genres    *[]string
+
+	ReorderLevel *int32
+	ListPrice    *string
+	Currency     *string
+}
+
+type contributorInput struct {
+	Name     *string
+	Role     *string
+	AuthorID *graphql.ID
+}
+
+func (in bookInput) book() books.Book {
+	str := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	var id string
+	if in.ID != nil {
+		id = string(*in.ID)
+	}
+	var authors []books.Contributor
+	if in.Authors != nil {
+		for _, c := range *in.Authors {
+			var authorID string
+			if c.AuthorID != nil {
+				authorID = string(*c.AuthorID)
+			}
+			authors = append(authors, books.Contributor{Name: str(c.Name), Role: str(c.Role), AuthorID: authorID})
+		}
+	}
+	list := func(l *[]string) []string {
+		if l == nil {
+			return nil
+		}
+		return *l
+	}
+	var reorderLevel int
+	if in.ReorderLevel != nil {
+		reorderLevel = int(*in.ReorderLevel)
+	}
+	return books.Book{
+		ID:          id,
+		BookName:    str(in.Title),
+		BookAuthor:  str(in.Author),
+		BookEdition: str(in.Edition),
+		BookPages:   str(in.Pages),
+		BookYear:    str(in.Year),
+		ISBN:        str(in.ISBN),
+		Authors:     authors,
+		Publisher:   str(in.Publisher),
+		Series:      str(in.Series),
+		Volume:      str(in.Volume),
+		Tags:        list(in.Tags),
+		Genres:      list(in.Genres),
+
+		ReorderLevel: reorderLevel,
+		ListPrice:    str(in.ListPrice),
+		Currency:     str(in.Currency),
+	}
+}
+
+func (r *resolver) CreateBook(ctx context.Context, args struct{ Input bookInput }) (bookResolver, error) {
+	b, err := r.repo.Create(ctx, args.Input.book())
+	if err != nil {
+		return bookResolver{}, err
+	}
+	return bookResolver{*b}, nil
+}
+
+func (r *resolver) UpdateBook(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input bookInput
+}) (bookResolver, error) {
+	b, err := r.repo.Update(ctx, string(args.ID), args.Input.book())
+	if err != nil {
+		return bookResolver{}, err
+	}
+	return bookResolver{*b}, nil
+}
+
+func (r *resolver) DeleteBook(ctx context.Context, args struct{ ID graphql.ID }) (graphql.ID, error) {
+	if err := r.repo.Delete(ctx, string(args.ID)); err != nil {
+		return "", err
+	}
+	return args.ID, nil
+}
+
+func (r *resolver) AddTags(ctx context.Context, args struct {
+	ID   graphql.ID
+	Tags []string
+}) (bookResolver, error) {
+	b, err := r.repo.AddTags(ctx, string(args.ID), args.Tags)
+	if err != nil {
+		return bookResolver{}, err
+	}
+	return bookResolver{*b}, nil
+}
+
+func (r *resolver) AdjustStock(ctx context.Context, args struct {
+	ID     graphql.ID
+	Delta  int32
+	Reason string
+}) (bookResolver, error) {
+	if _, err := r.repo.AdjustStock(ctx, string(args.ID), int(args.Delta), args.Reason); err != nil {
+		return bookResolver{}, err
+	}
+	b, err := r.repo.Get(ctx, string(args.ID))
+	if err != nil {
+		return bookResolver{}, err
+	}
+	return bookResolver{*b}, nil
+}
+
+func (r *resolver) SetOrderStatus(ctx context.Context, args struct {
+	ID     graphql.ID
+	Status string
+}) (orderResolver, error) {
+	o, err := r.repo.SetOrderStatus(ctx, string(args.ID), args.Status)
+	if err != nil {
+		return orderResolver{}, err
+	}
+	return orderResolver{*o}, nil
+}
+
+func (r *resolver) LendCopy(ctx context.Context, args struct {
+	Barcode  string
+	Borrower string
+	Days     *int32
+}) (loanResolver, error) {
+	req := lending.CheckoutRequest{Borrower: args.Borrower}
+	if args.Days != nil {
+		req.Days = int(*args.Days)
+	}
+	l, err := r.repo.Lend(ctx, args.Barcode, req)
+	if err != nil {
+		return loanResolver{}, err
+	}
+	return loanResolver{*l}, nil
+}
+
+func (r *resolver) ReturnCopy(ctx context.Context, args struct{ Barcode string }) (loanResolver, error) {
+	l, _, err := r.repo.Return(ctx, args.Barcode)
+	if err != nil {
+		return loanResolver{}, err
+	}
+	return loanResolver{*l}, nil
+}
+
+func (r *resolver) RemoveTag(ctx context.Context, args struct {
+	ID  graphql.ID
+	Tag string
+}) (bookResolver, error) {
+	b, err := r.repo.RemoveTag(ctx, string(args.ID), args.Tag)
+	if err != nil {
+		return bookResolver{}, err
+	}
+	return bookResolver{*b}, nil
+}
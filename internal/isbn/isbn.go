@@ -0,0 +1,127 @@
+// Package isbn parses and converts International Standard Book Numbers.
+//
+// The catalog stores ISBNs normalized: as ISBN-13, without hyphens or
+// spaces. Clients may send either form, hyphenated or not.
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors returned by Parse.
+var (
+	ErrLength   = errors.New("must have 10 or 13 digits")
+	ErrDigits   = errors.New("must only contain digits, hyphens and spaces, and X as last character of an ISBN-10")
+	ErrChecksum = errors.New("has an invalid check digit")
+	ErrPrefix   = errors.New("must start with 978 or 979")
+)
+
+// ISBN is a valid ISBN-13 without hyphens, e.g. "9783649646099".
+type ISBN string
+
+// Parse validates an ISBN-10 or ISBN-13, hyphenated or not, and returns it as
+// ISBN-13.
+func Parse(s string) (ISBN, error) {
+	digits := compact(s)
+	switch len(digits) {
+	case 10:
+		if !allDigits(digits[:9]) || !(allDigits(digits[9:]) || digits[9] == 'X') {
+			return "", ErrDigits
+		}
+		if checkDigit10(digits[:9]) != digits[9] {
+			return "", ErrChecksum
+		}
+		return ISBN(to13(digits[:9])), nil
+	case 13:
+		if !allDigits(digits) {
+			return "", ErrDigits
+		}
+		if !strings.HasPrefix(digits, "978") && !strings.HasPrefix(digits, "979") {
+			return "", ErrPrefix
+		}
+		if checkDigit13(digits[:12]) != digits[12] {
+			return "", ErrChecksum
+		}
+		return ISBN(digits), nil
+	}
+	return "", ErrLength
+}
+
+// Valid reports whether s is a valid ISBN-10 or ISBN-13.
+func Valid(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
+
+// Normalize returns s as ISBN-13 without hyphens, or s unchanged if it is
+// not a valid ISBN.
+func Normalize(s string) string {
+	if n, err := Parse(s); err == nil {
+		return string(n)
+	}
+	return s
+}
+
+// String returns the ISBN-13.
+func (n ISBN) String() string {
+	return string(n)
+}
+
+// ISBN10 returns the ISBN-10 of n. ok is false for ISBNs starting with 979,
+// which have no ISBN-10.
+func (n ISBN) ISBN10() (isbn10 string, ok bool) {
+	if !strings.HasPrefix(string(n), "978") {
+		return "", false
+	}
+	body := string(n)[3:12]
+	return body + string(checkDigit10(body)), true
+}
+
+// compact removes hyphens and spaces and upper-cases a trailing x.
+func compact(s string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(s)))
+}
+
+func to13(body10 string) string {
+	body := "978" + body10
+	return body + string(checkDigit13(body))
+}
+
+// checkDigit10 computes the check digit of the first nine digits of an
+// ISBN-10: weights 10 down to 2, modulo 11, with X standing for 10.
+func checkDigit10(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(body[i]-'0')
+	}
+	switch check := (11 - sum%11) % 11; check {
+	case 10:
+		return 'X'
+	default:
+		return byte('0' + check)
+	}
+}
+
+// checkDigit13 computes the check digit of the first twelve digits of an
+// ISBN-13: alternating weights 1 and 3, modulo 10.
+func checkDigit13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(body[i]-'0')
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
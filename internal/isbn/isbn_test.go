@@ -0,0 +1,80 @@
+package isbn
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ISBN
+		err  error
+	}{
+		{"9783649646099", "9783649646099", nil},
+		{"978-3-649-64609-9", "9783649646099", nil},
+		{"978 3 649 64609 9", "9783649646099", nil},
+		{" 9780306406157 ", "9780306406157", nil},
+		{"979-10-90636-07-1", "9791090636071", nil},
+		{"0306406152", "9780306406157", nil},
+		{"0-306-40615-2", "9780306406157", nil},
+		{"3 649 64609 9", "9783649646099", nil},
+		{"080442957X", "9780804429573", nil},
+		{"0-8044-2957-x", "9780804429573", nil},
+
+		{"", "", ErrLength},
+		{"12345", "", ErrLength},
+		{"97836496460999", "", ErrLength},
+		{"0306406152X", "", ErrLength},
+		{"X306406152", "", ErrDigits},
+		{"08044295X7", "", ErrDigits},
+		{"030640615.", "", ErrDigits},
+		{"978364964609X", "", ErrDigits},
+		{"978364964609_", "", ErrDigits},
+		{"0306406153", "", ErrChecksum},
+		{"0-306-40615-X", "", ErrChecksum},
+		{"9783649646098", "", ErrChecksum},
+		{"979-10-90636-07-2", "", ErrChecksum},
+		{"9773649646099", "", ErrPrefix},
+		{"1234567890128", "", ErrPrefix},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if got != tt.want || err != tt.err {
+			t.Errorf("Parse(%q) = %q, %v, want %q, %v", tt.in, got, err, tt.want, tt.err)
+		}
+		if valid := Valid(tt.in); valid != (tt.err == nil) {
+			t.Errorf("Valid(%q) = %v", tt.in, valid)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"0-306-40615-2", "9780306406157"},
+		{"978-3-649-64609-9", "9783649646099"},
+		{"0-306-40615-3", "0-306-40615-3"},
+		{"not an isbn", "not an isbn"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestISBN10(t *testing.T) {
+	tests := []struct {
+		in   ISBN
+		want string
+		ok   bool
+	}{
+		{"9780306406157", "0306406152", true},
+		{"9780804429573", "080442957X", true},
+		{"9783649646099", "3649646099", true},
+		{"9791090636071", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.in.ISBN10()
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s.ISBN10() = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
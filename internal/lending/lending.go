@@ -0,0 +1,162 @@
+// Package lending lends the physical copies of books to borrowers. Every
+// copy has a barcode of its own and is either available, on loan, or set
+// aside for a borrower who placed a hold on its book.
+//
+// Holds queue up per book in the order they were placed. A copy coming back
+// to the library, returned or newly added, goes to the oldest waiting hold
+// and is kept for that borrower until they check it out or cancel the hold;
+// only copies nobody waits for become available.
+package lending
+
+import (
+	"errors"
+	"time"
+)
+
+// Collections of the lending library.
+const (
+	CopyCollection = "copies"
+	LoanCollection = "loans"
+	HoldCollection = "holds"
+)
+
+// LoanPeriod is how long a copy is lent out unless the checkout asks for
+// another number of days, at most MaxLoanDays.
+const (
+	LoanPeriod  = 21 * 24 * time.Hour
+	MaxLoanDays = 90
+)
+
+var (
+	ErrCopyNotFound = errors.New("copy not found")
+	ErrHoldNotFound = errors.New("hold not found")
+	// ErrBarcodeTaken is returned when adding a copy with the barcode of
+	// another one.
+	ErrBarcodeTaken = errors.New("the barcode is taken")
+	// ErrUnavailable is returned when checking out a copy that is on loan
+	// or set aside for another borrower.
+	ErrUnavailable = errors.New("the copy is not available")
+	ErrNotOnLoan   = errors.New("the copy is not on loan")
+	// ErrOnLoan is returned when removing a copy that is on loan.
+	ErrOnLoan = errors.New("the copy is on loan")
+	// ErrDuplicateHold is returned when a borrower places a second hold on
+	// a book.
+	ErrDuplicateHold = errors.New("the borrower already holds the book")
+	// ErrHoldClosed is returned when cancelling a hold that was fulfilled
+	// or cancelled before.
+	ErrHoldClosed = errors.New("the hold is closed")
+)
+
+// Statuses of copies.
+const (
+	CopyAvailable = "available"
+	CopyOnLoan    = "on_loan"
+	// CopyOnHold copies are set aside for the borrower of a ready hold.
+	CopyOnHold = "on_hold"
+)
+
+// Copy is a physical copy of a book.
+type Copy struct {
+	Barcode string `bson:"barcode" json:"barcode"`
+	BookID  string `bson:"book_id" json:"bookId"`
+	Status  string `bson:"status" json:"status"`
+	// Borrower has the copy on loan, or it is set aside for them.
+	Borrower string    `bson:"borrower" json:"borrower"`
+	Added    time.Time `bson:"added" json:"-"`
+}
+
+// API returns the copy in the form used by the /api/copies endpoints.
+func (c Copy) API() map[string]interface{} {
+	return map[string]interface{}{
+		"barcode":  c.Barcode,
+		"bookId":   c.BookID,
+		"status":   c.Status,
+		"borrower": c.Borrower,
+		"added":    c.Added.UTC().Format(time.RFC3339),
+	}
+}
+
+// Loan is the lending of a copy to a borrower. Open loans have no return
+// time.
+type Loan struct {
+	ID       string     `bson:"id" json:"id"`
+	Barcode  string     `bson:"barcode" json:"barcode"`
+	BookID   string     `bson:"book_id" json:"bookId"`
+	Borrower string     `bson:"borrower" json:"borrower"`
+	Borrowed time.Time  `bson:"borrowed" json:"-"`
+	Due      time.Time  `bson:"due" json:"-"`
+	Returned *time.Time `bson:"returned,omitempty" json:"-"`
+}
+
+// Overdue reports whether the loan is open after its due time.
+func (l Loan) Overdue(now time.Time) bool {
+	return l.Returned == nil && now.After(l.Due)
+}
+
+// API returns the loan in the form used by the /api/loans endpoints.
+func (l Loan) API() map[string]interface{} {
+	ret := map[string]interface{}{
+		"id":       l.ID,
+		"barcode":  l.Barcode,
+		"bookId":   l.BookID,
+		"borrower": l.Borrower,
+		"borrowed": l.Borrowed.UTC().Format(time.RFC3339),
+		"due":      l.Due.UTC().Format(time.RFC3339),
+		"overdue":  l.Overdue(time.Now()),
+	}
+	if l.Returned != nil {
+		ret["returned"] = l.Returned.UTC().Format(time.RFC3339)
+	}
+	return ret
+}
+
+// Statuses of holds. Waiting holds queue for a copy, ready ones have one set
+// aside.
+const (
+	HoldWaiting   = "waiting"
+	HoldReady     = "ready"
+	HoldFulfilled = "fulfilled"
+	HoldCancelled = "cancelled"
+)
+
+// Hold is the request of a borrower for the next copy of a book.
+type Hold struct {
+	ID       string `bson:"id" json:"id"`
+	BookID   string `bson:"book_id" json:"bookId"`
+	Borrower string `bson:"borrower" json:"borrower"`
+	Status   string `bson:"status" json:"status"`
+	// Barcode is the copy set aside for a ready hold.
+	Barcode string    `bson:"barcode" json:"barcode"`
+	Placed  time.Time `bson:"placed" json:"-"`
+	Updated time.Time `bson:"updated" json:"-"`
+}
+
+// API returns the hold in the form used by the /api/holds endpoints.
+func (h Hold) API() map[string]interface{} {
+	return map[string]interface{}{
+		"id":       h.ID,
+		"bookId":   h.BookID,
+		"borrower": h.Borrower,
+		"status":   h.Status,
+		"barcode":  h.Barcode,
+		"placed":   h.Placed.UTC().Format(time.RFC3339),
+		"updated":  h.Updated.UTC().Format(time.RFC3339),
+	}
+}
+
+// CopyRequest is the body of POST /api/books/:id/copies.
+type CopyRequest struct {
+	Barcode string `json:"barcode" msgpack:"barcode" validate:"required,max=50"`
+}
+
+// CheckoutRequest is the body of POST /api/copies/:barcode/checkout.
+type CheckoutRequest struct {
+	Borrower string `json:"borrower" msgpack:"borrower" validate:"required,max=200"`
+	// Days is the loan period in days, LoanPeriod if 0.
+	Days int `json:"days" msgpack:"days" validate:"between=1 90"`
+}
+
+// HoldRequest is the body of POST /api/books/:id/holds.
+type HoldRequest struct {
+	Borrower string `json:"borrower" msgpack:"borrower" validate:"required,max=200"`
+}
@@ -0,0 +1,170 @@
+// Package migrate applies versioned schema changes to the catalog database.
+//
+// Migrations are plain Go functions registered with Register. Every applied
+// version is recorded in the schema_migrations collection, so running Up
+// again only applies what is missing and Down reverts the latest steps.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection records the applied migrations.
+const Collection = "schema_migrations"
+
+// Migration is a single versioned step. Down may be nil for steps that
+// cannot be reverted.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+	Down        func(ctx context.Context, db *mongo.Database) error
+}
+
+var registry []Migration
+
+// Register adds a migration to the registry. It panics on a duplicate or
+// non-positive version, since that is a programming error.
+func Register(m Migration) {
+	if m.Version <= 0 {
+		panic(fmt.Sprintf("migrate: invalid version %d", m.Version))
+	}
+	for _, r := range registry {
+		if r.Version == m.Version {
+			panic(fmt.Sprintf("migrate: duplicate version %d", m.Version))
+		}
+	}
+	registry = append(registry, m)
+	slices.SortFunc(registry, func(a, b Migration) int { return a.Version - b.Version })
+}
+
+// Migrations returns the registered migrations ordered by version.
+func Migrations() []Migration {
+	return slices.Clone(registry)
+}
+
+// record is the document stored in schema_migrations.
+type record struct {
+	Version     int       `bson:"version"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Status describes a registered migration and whether it has been applied.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator runs the registered migrations against a database.
+type Migrator struct {
+	db   *mongo.Database
+	coll *mongo.Collection
+}
+
+// New returns a Migrator for db.
+func New(db *mongo.Database) *Migrator {
+	return &Migrator{db: db, coll: db.Collection(Collection)}
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]record, error) {
+	cursor, err := m.coll.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[int]record, len(records))
+	for _, r := range records {
+		ret[r.Version] = r
+	}
+	return ret, nil
+}
+
+// Status lists every registered migration in order.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []Status
+	for _, mig := range registry {
+		r, ok := applied[mig.Version]
+		ret = append(ret, Status{Migration: mig, Applied: ok, AppliedAt: r.AppliedAt})
+	}
+	return ret, nil
+}
+
+// Up applies every pending migration up to and including target, in version
+// order. A target of 0 applies all of them. It returns the applied steps.
+func (m *Migrator) Up(ctx context.Context, target int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, mig := range registry {
+		if target > 0 && mig.Version > target {
+			break
+		}
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+
+		if err := mig.Up(ctx, m.db); err != nil {
+			return done, fmt.Errorf("migration %d (%s): %w", mig.Version, mig.Description, err)
+		}
+		_, err := m.coll.UpdateOne(ctx,
+			bson.M{"version": mig.Version},
+			bson.M{"$set": record{Version: mig.Version, Description: mig.Description, AppliedAt: time.Now().UTC()}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return done, err
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Down reverts the latest steps applied migrations, newest first. It returns
+// the reverted steps.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(registry) - 1; i >= 0 && len(done) < steps; i-- {
+		mig := registry[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if mig.Down == nil {
+			return done, fmt.Errorf("migration %d (%s) cannot be reverted", mig.Version, mig.Description)
+		}
+
+		if err := mig.Down(ctx, m.db); err != nil {
+			return done, fmt.Errorf("migration %d (%s): %w", mig.Version, mig.Description, err)
+		}
+		if _, err := m.coll.DeleteOne(ctx, bson.M{"version": mig.Version}); err != nil {
+			return done, err
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
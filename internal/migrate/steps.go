@@ -0,0 +1,563 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/isbn"
+	"github.com/CAPS-Cloud/exercises/internal/lending"
+	"github.com/CAPS-Cloud/exercises/internal/orders"
+	"github.com/CAPS-Cloud/exercises/internal/outbox"
+	"github.com/CAPS-Cloud/exercises/internal/pricing"
+	"github.com/CAPS-Cloud/exercises/internal/webhooks"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The catalog migrations, in order. New steps are appended with the next
+// free version and must never change once released.
+func init() {
+	Register(Migration{
+		Version:     1,
+		Description: "fill in missing book fields",
+		Up:          fillBookFields,
+		// Filling in empty strings is harmless, so there is nothing to undo.
+		Down: func(context.Context, *mongo.Database) error { return nil },
+	})
+	Register(Migration{
+		Version:     2,
+		Description: "unique index on book id",
+		Up:          createBookIDIndex,
+		Down:        dropBookIDIndex,
+	})
+	Register(Migration{
+		Version:     3,
+		Description: "index for the outbox relay",
+		Up:          createOutboxIndex,
+		Down:        dropOutboxIndex,
+	})
+	Register(Migration{
+		Version:     4,
+		Description: "move ISBNs out of the edition",
+		Up:          moveISBNs,
+		Down:        restoreISBNs,
+	})
+	Register(Migration{
+		Version:     5,
+		Description: "split author strings into lists of contributors",
+		Up:          splitAuthors,
+		// bookauthor is kept up to date, so dropping the lists loses nothing
+		// older versions know about.
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(books.Collection).UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"authors": ""}})
+			return err
+		},
+	})
+	Register(Migration{
+		Version:     6,
+		Description: "indexes for authors and the books referring to them",
+		Up:          createAuthorIndexes,
+		Down:        dropAuthorIndexes,
+	})
+	Register(Migration{
+		Version:     7,
+		Description: "indexes for filtering books by genre and tag",
+		Up:          createTagIndexes,
+		Down:        dropTagIndexes,
+	})
+	Register(Migration{
+		Version:     8,
+		Description: "indexes for reviews and sorting books by rating",
+		Up:          createReviewIndexes,
+		Down:        dropReviewIndexes,
+	})
+	Register(Migration{
+		Version:     9,
+		Description: "indexes for stock movements and the low-stock report",
+		Up:          createStockIndexes,
+		Down:        dropStockIndexes,
+	})
+	Register(Migration{
+		Version:     10,
+		Description: "indexes for price rules",
+		Up:          createPriceRuleIndexes,
+		Down:        dropPriceRuleIndexes,
+	})
+	Register(Migration{
+		Version:     11,
+		Description: "indexes for carts and orders, expiring untouched carts",
+		Up:          createOrderIndexes,
+		Down:        dropOrderIndexes,
+	})
+	Register(Migration{
+		Version:     12,
+		Description: "indexes for lending copies, loans and holds",
+		Up:          createLendingIndexes,
+		Down:        dropLendingIndexes,
+	})
+	Register(Migration{
+		Version:     13,
+		Description: "expire sent outbox entries and finished webhook deliveries",
+		Up:          createExpiryIndexes,
+		Down:        dropExpiryIndexes,
+	})
+	Register(Migration{
+		Version:     14,
+		Description: "index for the event feed reading the outbox",
+		Up:          createOutboxSeqIndex,
+		Down:        dropOutboxSeqIndex,
+	})
+}
+
+const bookIDIndex = "id_unique"
+
+// Documents written by older versions of the monolith and the split services
+// do not always carry every field. Give them all the same shape so decoding
+// and filtering behave the same everywhere.
+func fillBookFields(ctx context.Context, db *mongo.Database) error {
+	coll := db.Collection(books.Collection)
+	for _, field := range []string{"bookname", "bookauthor", "bookedition", "bookpages", "bookyear"} {
+		_, err := coll.UpdateMany(ctx,
+			bson.M{field: bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{field: ""}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createBookIDIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(books.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetName(bookIDIndex).SetUnique(true),
+	})
+	return err
+}
+
+func dropBookIDIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(books.Collection).Indexes().DropOne(ctx, bookIDIndex)
+	return err
+}
+
+const outboxIndex = "unsent_by_seq"
+
+// The relay repeatedly asks for the oldest unsent entries.
+func createOutboxIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(outbox.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "sent_at", Value: 1}, {Key: "seq", Value: 1}},
+		Options: options.Index().SetName(outboxIndex),
+	})
+	return err
+}
+
+func dropOutboxIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(outbox.Collection).Indexes().DropOne(ctx, outboxIndex)
+	return err
+}
+
+const isbnIndex = "isbn"
+
+// Before books had an isbn field the edition was used for it. Editions that
+// are valid ISBNs move to isbn, normalized to ISBN-13, the others stay.
+func moveISBNs(ctx context.Context, db *mongo.Database) error {
+	coll := db.Collection(books.Collection)
+	cursor, err := coll.Find(ctx, bson.M{
+		"bookedition": bson.M{"$ne": ""},
+		"isbn":        bson.M{"$in": bson.A{"", nil}},
+	})
+	if err != nil {
+		return err
+	}
+	var list []books.Book
+	if err := cursor.All(ctx, &list); err != nil {
+		return err
+	}
+	for _, b := range list {
+		n, err := isbn.Parse(b.BookEdition)
+		if err != nil {
+			continue
+		}
+		_, err = coll.UpdateOne(ctx,
+			bson.M{"id": b.ID},
+			bson.M{"$set": bson.M{"isbn": n.String(), "bookedition": ""}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = coll.UpdateMany(ctx,
+		bson.M{"isbn": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"isbn": ""}},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "isbn", Value: 1}},
+		Options: options.Index().SetName(isbnIndex),
+	})
+	return err
+}
+
+// ISBNs go back to the edition where it is empty, books that have both
+// lose the ISBN.
+func restoreISBNs(ctx context.Context, db *mongo.Database) error {
+	coll := db.Collection(books.Collection)
+	_, err := coll.UpdateMany(ctx,
+		bson.M{"bookedition": "", "isbn": bson.M{"$nin": bson.A{"", nil}}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"bookedition": "$isbn"}}}},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = coll.UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"isbn": ""}})
+	if err != nil {
+		return err
+	}
+	_, err = coll.Indexes().DropOne(ctx, isbnIndex)
+	return err
+}
+
+// Co-authors used to share one author string, like "Ahsanul Bari, Anupom
+// Syam". Give every book the list of its authors.
+func splitAuthors(ctx context.Context, db *mongo.Database) error {
+	coll := db.Collection(books.Collection)
+	cursor, err := coll.Find(ctx, bson.M{"authors": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	var list []books.Book
+	if err := cursor.All(ctx, &list); err != nil {
+		return err
+	}
+	for _, b := range list {
+		b.NormalizeAuthors()
+		_, err = coll.UpdateOne(ctx,
+			bson.M{"id": b.ID},
+			bson.M{"$set": bson.M{"authors": b.Authors, "bookauthor": b.BookAuthor}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	authorIDIndex   = "id_unique"
+	bookAuthorIndex = "authors_author_id"
+)
+
+// Authors are looked up by id, and renaming or deleting one looks for the
+// books referring to it.
+func createAuthorIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(books.AuthorCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetName(authorIDIndex).SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection(books.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "authors.author_id", Value: 1}},
+		Options: options.Index().SetName(bookAuthorIndex),
+	})
+	return err
+}
+
+func dropAuthorIndexes(ctx context.Context, db *mongo.Database) error {
+	if _, err := db.Collection(books.Collection).Indexes().DropOne(ctx, bookAuthorIndex); err != nil {
+		return err
+	}
+	_, err := db.Collection(books.AuthorCollection).Indexes().DropOne(ctx, authorIDIndex)
+	return err
+}
+
+const (
+	genresIndex = "genres"
+	tagsIndex   = "tags"
+)
+
+// The filter chips of the book table and GET /api/search select books by
+// genre and tag.
+func createTagIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(books.Collection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "genres", Value: 1}}, Options: options.Index().SetName(genresIndex)},
+		{Keys: bson.D{{Key: "tags", Value: 1}}, Options: options.Index().SetName(tagsIndex)},
+	})
+	return err
+}
+
+func dropTagIndexes(ctx context.Context, db *mongo.Database) error {
+	indexes := db.Collection(books.Collection).Indexes()
+	if _, err := indexes.DropOne(ctx, tagsIndex); err != nil {
+		return err
+	}
+	_, err := indexes.DropOne(ctx, genresIndex)
+	return err
+}
+
+const (
+	reviewIDIndex   = "book_id_id_unique"
+	bookRatingIndex = "rating"
+)
+
+// Reviews are looked up and listed by book, newest first, and the book list
+// can be sorted by rating.
+func createReviewIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(books.ReviewCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "book_id", Value: 1}, {Key: "id", Value: 1}},
+		Options: options.Index().SetName(reviewIDIndex).SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection(books.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "rating", Value: -1}, {Key: "review_count", Value: -1}},
+		Options: options.Index().SetName(bookRatingIndex),
+	})
+	return err
+}
+
+func dropReviewIndexes(ctx context.Context, db *mongo.Database) error {
+	if _, err := db.Collection(books.Collection).Indexes().DropOne(ctx, bookRatingIndex); err != nil {
+		return err
+	}
+	_, err := db.Collection(books.ReviewCollection).Indexes().DropOne(ctx, reviewIDIndex)
+	return err
+}
+
+const (
+	movementBookIndex = "book_id_time"
+	reorderLevelIndex = "reorder_level"
+)
+
+// Stock movements are listed by book, newest first, and the low-stock report
+// only looks at books with a reorder level.
+func createStockIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(books.MovementCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "book_id", Value: 1}, {Key: "time", Value: -1}},
+		Options: options.Index().SetName(movementBookIndex),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection(books.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "reorder_level", Value: 1}},
+		Options: options.Index().SetName(reorderLevelIndex),
+	})
+	return err
+}
+
+func dropStockIndexes(ctx context.Context, db *mongo.Database) error {
+	if _, err := db.Collection(books.Collection).Indexes().DropOne(ctx, reorderLevelIndex); err != nil {
+		return err
+	}
+	_, err := db.Collection(books.MovementCollection).Indexes().DropOne(ctx, movementBookIndex)
+	return err
+}
+
+const (
+	priceRuleIDIndex   = "id_unique"
+	priceRuleNameIndex = "name_id"
+)
+
+// Price rules are looked up by id and listed by name.
+func createPriceRuleIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(pricing.RuleCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "id", Value: 1}},
+			Options: options.Index().SetName(priceRuleIDIndex).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}, {Key: "id", Value: 1}},
+			Options: options.Index().SetName(priceRuleNameIndex),
+		},
+	})
+	return err
+}
+
+func dropPriceRuleIndexes(ctx context.Context, db *mongo.Database) error {
+	coll := db.Collection(pricing.RuleCollection)
+	if _, err := coll.Indexes().DropOne(ctx, priceRuleNameIndex); err != nil {
+		return err
+	}
+	_, err := coll.Indexes().DropOne(ctx, priceRuleIDIndex)
+	return err
+}
+
+const (
+	cartIDIndex      = "id_unique"
+	cartExpiryIndex  = "updated_ttl"
+	orderIDIndex     = "id_unique"
+	orderStatusIndex = "status_created"
+)
+
+// Carts are looked up by id and expire orders.CartLifetime after their last
+// change. Orders are looked up by id and listed newest first, optionally by
+// status.
+func createOrderIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(orders.CartCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "id", Value: 1}},
+			Options: options.Index().SetName(cartIDIndex).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "updated", Value: 1}},
+			Options: options.Index().SetName(cartExpiryIndex).SetExpireAfterSeconds(int32(orders.CartLifetime.Seconds())),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection(orders.OrderCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "id", Value: 1}},
+			Options: options.Index().SetName(orderIDIndex).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "created", Value: -1}},
+			Options: options.Index().SetName(orderStatusIndex),
+		},
+	})
+	return err
+}
+
+func dropOrderIndexes(ctx context.Context, db *mongo.Database) error {
+	for coll, names := range map[string][]string{
+		orders.OrderCollection: {orderStatusIndex, orderIDIndex},
+		orders.CartCollection:  {cartExpiryIndex, cartIDIndex},
+	} {
+		for _, name := range names {
+			if _, err := db.Collection(coll).Indexes().DropOne(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+const (
+	copyBarcodeIndex = "barcode_unique"
+	copyBookIndex    = "book_id_barcode"
+	loanIDIndex      = "id_unique"
+	loanBarcodeIndex = "barcode_returned"
+	loanDueIndex     = "due_id"
+	holdIDIndex      = "id_unique"
+	holdQueueIndex   = "book_id_status_placed"
+)
+
+// Barcodes are unique; copies are listed per book by barcode. The open loan
+// of a copy is found by its barcode and open loans are listed by due time.
+// Holds are looked up by id and queue per book in the order they were
+// placed.
+func createLendingIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(lending.CopyCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "barcode", Value: 1}},
+			Options: options.Index().SetName(copyBarcodeIndex).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "book_id", Value: 1}, {Key: "barcode", Value: 1}},
+			Options: options.Index().SetName(copyBookIndex),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection(lending.LoanCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "id", Value: 1}},
+			Options: options.Index().SetName(loanIDIndex).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "barcode", Value: 1}, {Key: "returned", Value: 1}},
+			Options: options.Index().SetName(loanBarcodeIndex),
+		},
+		{
+			Keys:    bson.D{{Key: "due", Value: 1}, {Key: "id", Value: 1}},
+			Options: options.Index().SetName(loanDueIndex),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection(lending.HoldCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "id", Value: 1}},
+			Options: options.Index().SetName(holdIDIndex).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "book_id", Value: 1}, {Key: "status", Value: 1}, {Key: "placed", Value: 1}},
+			Options: options.Index().SetName(holdQueueIndex),
+		},
+	})
+	return err
+}
+
+func dropLendingIndexes(ctx context.Context, db *mongo.Database) error {
+	for coll, names := range map[string][]string{
+		lending.HoldCollection: {holdQueueIndex, holdIDIndex},
+		lending.LoanCollection: {loanDueIndex, loanBarcodeIndex, loanIDIndex},
+		lending.CopyCollection: {copyBookIndex, copyBarcodeIndex},
+	} {
+		for _, name := range names {
+			if _, err := db.Collection(coll).Indexes().DropOne(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+const (
+	outboxExpiryIndex   = "sent_at_expiry"
+	deliveryExpiryIndex = "finished_at_expiry"
+)
+
+// Sent outbox entries and finished webhook deliveries are only kept for a
+// while. TTL indexes skip documents without the date, so unsent entries and
+// pending deliveries stay.
+func createExpiryIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(outbox.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "sent_at", Value: 1}},
+		Options: options.Index().SetName(outboxExpiryIndex).SetExpireAfterSeconds(int32(outbox.Retention.Seconds())),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection(webhooks.DeliveryCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "finished_at", Value: 1}},
+		Options: options.Index().SetName(deliveryExpiryIndex).SetExpireAfterSeconds(int32(webhooks.Retention.Seconds())),
+	})
+	return err
+}
+
+func dropExpiryIndexes(ctx context.Context, db *mongo.Database) error {
+	if _, err := db.Collection(webhooks.DeliveryCollection).Indexes().DropOne(ctx, deliveryExpiryIndex); err != nil {
+		return err
+	}
+	_, err := db.Collection(outbox.Collection).Indexes().DropOne(ctx, outboxExpiryIndex)
+	return err
+}
+
+const outboxSeqIndex = "seq"
+
+// The event feed reads entries by sequence number, sent or not. Sequence
+// numbers come from a single counter, so they are unique.
+func createOutboxSeqIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(outbox.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "seq", Value: 1}},
+		Options: options.Index().SetName(outboxSeqIndex).SetUnique(true),
+	})
+	return err
+}
+
+func dropOutboxSeqIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(outbox.Collection).Indexes().DropOne(ctx, outboxSeqIndex)
+	return err
+}
@@ -0,0 +1,93 @@
+// Package openapi holds the OpenAPI 3 document of /api/books, the contract
+// every binary serving those routes has to keep. It serves the document and a
+// Swagger UI page, and validates requests, and optionally responses, against
+// it.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/CAPS-Cloud/exercises/internal/content"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+//go:embed swagger.html
+var swaggerPage []byte
+
+var (
+	doc      *openapi3.T
+	specJSON []byte
+	router   routers.Router
+)
+
+func init() {
+	var err error
+	if doc, err = openapi3.NewLoader().LoadFromData(specYAML); err != nil {
+		panic(fmt.Sprintf("openapi: failed to load openapi.yaml: %v", err))
+	}
+	if err = doc.Validate(context.Background()); err != nil {
+		panic(fmt.Sprintf("openapi: openapi.yaml is invalid: %v", err))
+	}
+	if specJSON, err = doc.MarshalJSON(); err != nil {
+		panic(fmt.Sprintf("openapi: failed to encode openapi.yaml: %v", err))
+	}
+	if router, err = gorillamux.NewRouter(doc); err != nil {
+		panic(fmt.Sprintf("openapi: failed to route openapi.yaml: %v", err))
+	}
+
+	// MessagePack bodies are validated like JSON ones. The other types
+	// have no schema in the document and are left to the handlers.
+	openapi3filter.RegisterBodyDecoder(content.Msgpack, func(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+		var value interface{}
+		if err := msgpack.NewDecoder(body).Decode(&value); err != nil {
+			return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+		}
+		return value, nil
+	})
+}
+
+// Document returns the parsed OpenAPI document.
+func Document() *openapi3.T {
+	return doc
+}
+
+// Register serves the document at GET /api/openapi.json and a Swagger UI
+// page for it at GET /api/docs.
+func Register(e *echo.Echo) {
+	e.GET("/api/openapi.json", func(c echo.Context) error {
+		return c.JSONBlob(http.StatusOK, specJSON)
+	})
+
+	e.GET("/api/docs", func(c echo.Context) error {
+		return c.HTMLBlob(http.StatusOK, swaggerPage)
+	})
+}
+
+// Options configures the validation middleware.
+type Options struct {
+	// ValidateResponses checks every response against the document as
+	// well, and replaces those not matching it by a 500. Responses are
+	// buffered for this, so it is meant for tests rather than production.
+	ValidateResponses bool
+}
+
+// OptionsFromEnv reads the options from the environment:
+// OPENAPI_VALIDATE_RESPONSES=true turns on response validation.
+func OptionsFromEnv() Options {
+	validate, _ := strconv.ParseBool(os.Getenv("OPENAPI_VALIDATE_RESPONSES"))
+	return Options{ValidateResponses: validate}
+}
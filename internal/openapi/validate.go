@@ -0,0 +1,198 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/CAPS-Cloud/exercises/internal/content"
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/labstack/echo/v4"
+)
+
+// Validator returns a middleware checking requests to the routes of the
+// document against it. Invalid requests are answered with a 400 problem,
+// listing every field in error, before they reach a handler. Requests to
+// routes the document does not describe pass through untouched.
+func Validator(opts Options) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			route, params, err := router.FindRoute(req)
+			if err != nil {
+				return next(c)
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: params,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+					MultiError:         true,
+					// Bodies of types the operation does not list are
+					// answered with 415 by the handler.
+					ExcludeRequestBody: !declared(route, req),
+				},
+			}
+			if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+				return problem.Validation("The request does not match the API document", fieldErrors(err)...)
+			}
+
+			if !opts.ValidateResponses {
+				return next(c)
+			}
+			return validateResponse(c, next, input)
+		}
+	}
+}
+
+// declared reports whether the type of the request body is one the
+// operation lists. A body without Content-Type is read as JSON, as the
+// handlers do.
+func declared(route *routers.Route, req *http.Request) bool {
+	body := route.Operation.RequestBody
+	if body == nil || body.Value == nil {
+		return true
+	}
+	header := req.Header.Get(echo.HeaderContentType)
+	if header == "" {
+		if req.ContentLength == 0 {
+			return true
+		}
+		req.Header.Set(echo.HeaderContentType, content.JSON)
+		header = content.JSON
+	}
+	mt, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return false
+	}
+	return body.Value.Content.Get(mt) != nil
+}
+
+// validateResponse runs next with the response buffered, and only sends
+// it once it matches the document.
+func validateResponse(c echo.Context, next echo.HandlerFunc, input *openapi3filter.RequestValidationInput) error {
+	res := c.Response()
+	writer := res.Writer
+	buf := &bufferedWriter{header: writer.Header(), status: http.StatusOK}
+	res.Writer = buf
+	// Errors are written here rather than by Echo, so that they are
+	// validated as well.
+	if err := next(c); err != nil {
+		c.Error(err)
+	}
+	res.Writer = writer
+
+	check := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 buf.status,
+		Header:                 buf.header,
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	}
+	check.SetBodyBytes(buf.body.Bytes())
+	if err := openapi3filter.ValidateResponse(c.Request().Context(), check); err != nil {
+		res.Committed = false
+		res.Size = 0
+		// Logged by the error handler, like every 500.
+		return problem.Internal("Response does not match the API document: "+reason(err), nil)
+	}
+
+	writer.WriteHeader(buf.status)
+	_, err := writer.Write(buf.body.Bytes())
+	return err
+}
+
+// bufferedWriter holds back a response until it has been validated.
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) Header() http.Header         { return w.header }
+func (w *bufferedWriter) WriteHeader(status int)      { w.status = status }
+func (w *bufferedWriter) Write(p []byte) (int, error) { return w.body.Write(p) }
+
+// propertyReason matches the reasons naming the property they are about,
+// like `property "foo" is unsupported`.
+var propertyReason = regexp.MustCompile(`^property "([^"]+)" is`)
+
+// fieldErrors lists what is wrong with each field of a request.
+func fieldErrors(err error) []problem.FieldError {
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		var ret []problem.FieldError
+		for _, e := range err {
+			ret = append(ret, fieldErrors(e)...)
+		}
+		return ret
+	case *openapi3filter.RequestError:
+		if err.Parameter != nil {
+			msg := err.Reason
+			var schemaErr *openapi3.SchemaError
+			if errors.As(err.Err, &schemaErr) {
+				msg = schemaErr.Reason
+			} else if msg == "" && err.Err != nil {
+				msg = err.Err.Error()
+			}
+			return []problem.FieldError{{Field: err.Parameter.Name, Message: msg}}
+		}
+		if err.Err != nil {
+			return fieldErrors(err.Err)
+		}
+	case *openapi3.SchemaError:
+		// Missing properties are part of the pointer, unsupported ones not.
+		pointer := err.JSONPointer()
+		if m := propertyReason.FindStringSubmatch(err.Reason); m != nil && (len(pointer) == 0 || pointer[len(pointer)-1] != m[1]) {
+			pointer = append(pointer, m[1])
+		}
+		field := fieldPath(pointer)
+		return []problem.FieldError{{Field: field, Message: err.Reason}}
+	}
+	return []problem.FieldError{{Message: reason(err)}}
+}
+
+// fieldPath names a field the way the validation package does, e.g.
+// authors[0].name.
+func fieldPath(pointer []string) string {
+	var sb strings.Builder
+	for _, p := range pointer {
+		if _, err := strconv.Atoi(p); err == nil {
+			sb.WriteString("[" + p + "]")
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(p)
+	}
+	return sb.String()
+}
+
+// reason shortens a validation error to what the client needs to fix.
+func reason(err error) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		if field := fieldPath(schemaErr.JSONPointer()); field != "" {
+			return field + ": " + schemaErr.Reason
+		}
+		return schemaErr.Reason
+	}
+	var reqErr *openapi3filter.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.Error()
+	}
+	var resErr *openapi3filter.ResponseError
+	if errors.As(err, &resErr) {
+		return resErr.Reason
+	}
+	return err.Error()
+}
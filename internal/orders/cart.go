@@ -0,0 +1,83 @@
+package orders
+
+import (
+	"errors"
+	"time"
+)
+
+// CartCollection holds the carts. Carts nobody changed for CartLifetime are
+// removed by MongoDB.
+const CartCollection = "carts"
+
+// CartLifetime is how long an untouched cart is kept.
+const CartLifetime = 30 * 24 * time.Hour
+
+// MaxQuantity is the most copies of a book a cart can hold.
+const MaxQuantity = 100
+
+// ErrCartNotFound is returned for unknown carts.
+var ErrCartNotFound = errors.New("cart not found")
+
+// ErrEmptyCart is returned when checking out a cart without items.
+var ErrEmptyCart = errors.New("the cart is empty")
+
+// ErrCartChanged is returned when a cart changed while it was checked out.
+var ErrCartChanged = errors.New("the cart changed during checkout")
+
+// Item is a book in a cart and the number of copies of it.
+type Item struct {
+	BookID   string `bson:"book_id" json:"bookId" msgpack:"bookId" validate:"required,max=100"`
+	Quantity int    `bson:"quantity" json:"quantity" msgpack:"quantity" validate:"required,between=1 100"`
+}
+
+// Cart holds the books a customer is about to order, each book once, in the
+// order they were added.
+type Cart struct {
+	// ID is set by the catalog, like the times.
+	ID    string `bson:"id" json:"id"`
+	Items []Item `bson:"items" json:"items" validate:"dive"`
+
+	Created time.Time `bson:"created" json:"-"`
+	Updated time.Time `bson:"updated" json:"-"`
+}
+
+// Add puts the copies of item into the cart, on top of those of the same
+// book already in it. The quantity is capped at MaxQuantity.
+func (c *Cart) Add(item Item) {
+	for i := range c.Items {
+		if c.Items[i].BookID == item.BookID {
+			c.Items[i].Quantity = min(c.Items[i].Quantity+item.Quantity, MaxQuantity)
+			return
+		}
+	}
+	c.Items = append(c.Items, item)
+}
+
+// Set changes the quantity of a book in the cart, adding the book if it is
+// not in it yet.
+func (c *Cart) Set(item Item) {
+	for i := range c.Items {
+		if c.Items[i].BookID == item.BookID {
+			c.Items[i].Quantity = item.Quantity
+			return
+		}
+	}
+	c.Items = append(c.Items, item)
+}
+
+// API returns the cart in the form used by the /api/carts endpoints.
+func (c Cart) API() map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(c.Items))
+	for _, item := range c.Items {
+		items = append(items, map[string]interface{}{
+			"bookId":   item.BookID,
+			"quantity": item.Quantity,
+		})
+	}
+	return map[string]interface{}{
+		"id":      c.ID,
+		"items":   items,
+		"created": c.Created.UTC().Format(time.RFC3339),
+		"updated": c.Updated.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,157 @@
+// Package orders holds the carts of customers and the orders checked out
+// from them. An order keeps the prices its books had at checkout, so later
+// changes of list prices or price rules leave it alone.
+//
+// Orders start pending and are paid, then shipped; pending and paid orders
+// can be cancelled instead. Shipped and cancelled orders do not change any
+// more.
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/pricing"
+)
+
+// OrderCollection holds the orders.
+const OrderCollection = "orders"
+
+// ErrOrderNotFound is returned for unknown orders.
+var ErrOrderNotFound = errors.New("order not found")
+
+// Statuses of orders.
+const (
+	StatusPending   = "pending"
+	StatusPaid      = "paid"
+	StatusShipped   = "shipped"
+	StatusCancelled = "cancelled"
+)
+
+// Statuses lists the statuses in the order an order goes through them.
+var Statuses = []string{StatusPending, StatusPaid, StatusShipped, StatusCancelled}
+
+// next maps each status to those an order can move to from it.
+var next = map[string][]string{
+	StatusPending: {StatusPaid, StatusCancelled},
+	StatusPaid:    {StatusShipped, StatusCancelled},
+}
+
+// CanMove reports whether an order can move from one status to another.
+func CanMove(from, to string) bool {
+	return slices.Contains(next[from], to)
+}
+
+// Sources returns the statuses an order can move to status from.
+func Sources(status string) []string {
+	var ret []string
+	for _, from := range Statuses {
+		if CanMove(from, status) {
+			ret = append(ret, from)
+		}
+	}
+	return ret
+}
+
+// TransitionError is returned for status changes CanMove does not allow.
+type TransitionError struct {
+	From, To string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("a %s order cannot be %s", e.From, e.To)
+}
+
+// StatusChange is the body of PUT /api/orders/:id/status.
+type StatusChange struct {
+	Status string `json:"status" msgpack:"status" validate:"required,oneof=pending paid shipped cancelled"`
+}
+
+// Line is a book of an order with its prices at checkout, all in the
+// currency of the order.
+type Line struct {
+	BookID   string `bson:"book_id" json:"bookId"`
+	Title    string `bson:"title" json:"title"`
+	Quantity int    `bson:"quantity" json:"quantity"`
+	// ListPrice and UnitPrice are the prices of a single copy, before and
+	// after the discount of Rule.
+	ListPrice string `bson:"list_price" json:"listPrice"`
+	UnitPrice string `bson:"unit_price" json:"unitPrice"`
+	Rule      string `bson:"rule" json:"rule"`
+	// Total is UnitPrice times Quantity.
+	Total string `bson:"total" json:"total"`
+}
+
+// Order is a checked-out cart.
+type Order struct {
+	// ID is set by the catalog, like the times.
+	ID       string `bson:"id" json:"id"`
+	CartID   string `bson:"cart_id" json:"cartId"`
+	Lines    []Line `bson:"lines" json:"lines"`
+	Currency string `bson:"currency" json:"currency"`
+	// Total is the sum of the totals of the lines.
+	Total  string `bson:"total" json:"total"`
+	Status string `bson:"status" json:"status"`
+
+	Created time.Time `bson:"created" json:"-"`
+	Updated time.Time `bson:"updated" json:"-"`
+}
+
+// AddLine adds quantity copies of a book to the order at price, which must
+// be in the currency of the order, and updates the total.
+func (o *Order) AddLine(bookID, title string, quantity int, price pricing.Price) error {
+	if price.Currency != o.Currency {
+		return fmt.Errorf("orders: price in %s for an order in %s", price.Currency, o.Currency)
+	}
+	unit, err := pricing.ParseAmount(price.Amount)
+	if err != nil {
+		return err
+	}
+	total := int64(0)
+	if o.Total != "" {
+		if total, err = pricing.ParseAmount(o.Total); err != nil {
+			return err
+		}
+	}
+
+	line := unit * int64(quantity)
+	o.Lines = append(o.Lines, Line{
+		BookID:    bookID,
+		Title:     title,
+		Quantity:  quantity,
+		ListPrice: price.ListPrice,
+		UnitPrice: price.Amount,
+		Rule:      price.Rule,
+		Total:     pricing.FormatAmount(line),
+	})
+	o.Total = pricing.FormatAmount(total + line)
+	return nil
+}
+
+// API returns the order in the form used by the /api/orders endpoints.
+func (o Order) API() map[string]interface{} {
+	lines := make([]map[string]interface{}, 0, len(o.Lines))
+	for _, l := range o.Lines {
+		lines = append(lines, map[string]interface{}{
+			"bookId":    l.BookID,
+			"title":     l.Title,
+			"quantity":  l.Quantity,
+			"listPrice": l.ListPrice,
+			"unitPrice": l.UnitPrice,
+			"rule":      l.Rule,
+			"total":     l.Total,
+		})
+	}
+	return map[string]interface{}{
+		"id":       o.ID,
+		"cartId":   o.CartID,
+		"lines":    lines,
+		"currency": o.Currency,
+		"total":    o.Total,
+		"status":   o.Status,
+		"created":  o.Created.UTC().Format(time.RFC3339),
+		"updated":  o.Updated.UTC().Format(time.RFC3339),
+	}
+}
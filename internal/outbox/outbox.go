@@ -0,0 +1,218 @@
+// Package outbox records book events in the same MongoDB transaction as the
+// book change itself, so an event can never get lost between the write and
+// its publication. A relay publishes the recorded entries in order and marks
+// them as sent.
+//
+// Publication is at-least-once: if the relay dies after publishing an entry
+// but before marking it, the entry is published again.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collections used by the outbox.
+const (
+	Collection        = "outbox"
+	CounterCollection = "counters"
+)
+
+// Retention is how long entries are kept after they were sent. A TTL index
+// on their sent_at removes them afterwards; unsent entries never expire.
+const Retention = 7 * 24 * time.Hour
+
+// counterID is the id of the counters document handing out outbox sequence
+// numbers.
+const counterID = "outbox"
+
+// counter is the counters document handing out outbox sequence numbers.
+type counter struct {
+	Seq    int64  `bson:"seq"`
+	Stream string `bson:"stream"`
+}
+
+// Entry is a recorded event.
+type Entry struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Seq       int64              `bson:"seq"`
+	Event     string             `bson:"event"`
+	Data      interface{}        `bson:"data"`
+	CreatedAt time.Time          `bson:"created_at"`
+	SentAt    *time.Time         `bson:"sent_at"`
+}
+
+// Outbox writes entries into the outbox collection of a database.
+type Outbox struct {
+	db       *mongo.Database
+	coll     *mongo.Collection
+	counters *mongo.Collection
+
+	// Set once the server turned out not to support transactions.
+	noTransactions atomic.Bool
+}
+
+// New returns the outbox of db.
+func New(db *mongo.Database) *Outbox {
+	// Decode nested documents of the event data as maps, so they encode to
+	// JSON objects when the entries are published.
+	collOpts := options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+
+	return &Outbox{
+		db:       db,
+		coll:     db.Collection(Collection, collOpts),
+		counters: db.Collection(CounterCollection),
+	}
+}
+
+// Transaction runs fn in a transaction. Every write fn makes through the
+// context it is given, including Add, commits or aborts together.
+//
+// Transactions need a replica set. On a standalone server fn runs without
+// one: the outbox entry is then written right after the book change, which
+// still survives a crash of the relay but not of the handler in between.
+func (o *Outbox) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if o.noTransactions.Load() {
+		return fn(ctx)
+	}
+
+	sess, err := o.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer sess.EndSession(ctx)
+
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+
+	// 20 (IllegalOperation): "Transaction numbers are only allowed on a
+	// replica set member or mongos". Nothing was written, so it is safe to
+	// run fn again without a transaction.
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == 20 {
+		log.Printf("outbox: transactions are not supported by the server, writing without them")
+		o.noTransactions.Store(true)
+		return fn(ctx)
+	}
+	return err
+}
+
+// Add records an event. Call it inside Transaction with the context passed
+// to the transaction function.
+func (o *Outbox) Add(ctx context.Context, event string, data interface{}) error {
+	// Incrementing the same counter document makes concurrent transactions
+	// conflict, so sequence numbers are committed in order.
+	var c counter
+	err := o.counters.FindOneAndUpdate(ctx,
+		bson.M{"_id": counterID},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&c)
+	if err != nil {
+		return err
+	}
+
+	_, err = o.coll.InsertOne(ctx, Entry{
+		Seq:       c.Seq,
+		Event:     event,
+		Data:      data,
+		CreatedAt: time.Now().UTC(),
+	})
+	return err
+}
+
+// ErrNoChangeStreams is returned by Watch when the server has no change
+// streams, e.g. a standalone server.
+var ErrNoChangeStreams = errors.New("change streams not supported")
+
+// Stream returns the id of the outbox sequence. It is created together with
+// the counter and never changes, so sequence numbers only mean the same
+// entry as long as the stream stays the same, e.g. until the database is
+// dropped.
+func (o *Outbox) Stream(ctx context.Context) (string, error) {
+	// Counters created before streams existed get one on first use. When
+	// the counter already has a stream the filter does not match and the
+	// upsert fails on the existing _id, which is fine.
+	_, err := o.counters.UpdateOne(ctx,
+		bson.M{"_id": counterID, "stream": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"stream": primitive.NewObjectID().Hex()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return "", err
+	}
+
+	var c counter
+	if err := o.counters.FindOne(ctx, bson.M{"_id": counterID}).Decode(&c); err != nil {
+		return "", err
+	}
+	return c.Stream, nil
+}
+
+// Range returns the sequence numbers of the oldest entry kept and of the
+// latest one handed out. first is last+1 when the outbox is empty.
+func (o *Outbox) Range(ctx context.Context) (first, last int64, err error) {
+	var c counter
+	err = o.counters.FindOne(ctx, bson.M{"_id": counterID}).Decode(&c)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, 0, err
+	}
+
+	var oldest Entry
+	err = o.coll.FindOne(ctx, bson.D{},
+		options.FindOne().SetSort(bson.D{{Key: "seq", Value: 1}}).SetProjection(bson.M{"seq": 1}),
+	).Decode(&oldest)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Seq + 1, c.Seq, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return oldest.Seq, c.Seq, nil
+}
+
+// After returns up to limit entries following seq, in sequence order.
+func (o *Outbox) After(ctx context.Context, seq, limit int64) ([]Entry, error) {
+	cursor, err := o.coll.Find(ctx,
+		bson.M{"seq": bson.M{"$gt": seq}},
+		options.Find().SetSort(bson.D{{Key: "seq", Value: 1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Watch calls fn whenever an entry is added, until ctx is cancelled or the
+// change stream fails. fn is only a hint: read the entries with After.
+func (o *Outbox) Watch(ctx context.Context, fn func()) error {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": "insert"}}}}
+	stream, err := o.coll.Watch(ctx, pipeline)
+	if err != nil {
+		var cmdErr mongo.CommandError
+		// 40573: "The $changeStream stage is only supported on replica sets"
+		if errors.As(err, &cmdErr) && cmdErr.Code == 40573 {
+			return ErrNoChangeStreams
+		}
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		fn()
+	}
+	return stream.Err()
+}
@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// relayBatch is the number of entries read per round.
+const relayBatch = 100
+
+// PublishFunc publishes a single entry.
+type PublishFunc func(ctx context.Context, entry Entry) error
+
+// Relay publishes the unsent entries of an outbox in sequence order.
+type Relay struct {
+	Outbox  *Outbox
+	Publish PublishFunc
+	// PollInterval is how long the relay waits when the outbox is empty or
+	// publishing failed.
+	PollInterval time.Duration
+}
+
+// NewRelay returns a relay publishing the entries of o with publish.
+func NewRelay(o *Outbox, publish PublishFunc) *Relay {
+	return &Relay{Outbox: o, Publish: publish, PollInterval: time.Second}
+}
+
+// Run publishes entries until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		n, err := r.publishBatch(ctx)
+		if err != nil {
+			log.Printf("outbox: %v", err)
+		}
+		if err != nil || n < relayBatch {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(r.PollInterval):
+			}
+		}
+	}
+}
+
+// publishBatch publishes the oldest unsent entries. It stops at the first
+// entry that fails, so later entries are never published before it.
+func (r *Relay) publishBatch(ctx context.Context) (int, error) {
+	cursor, err := r.Outbox.coll.Find(ctx,
+		bson.M{"sent_at": nil},
+		options.Find().SetSort(bson.D{{Key: "seq", Value: 1}}).SetLimit(relayBatch),
+	)
+	if err != nil {
+		return 0, err
+	}
+	var entries []Entry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return 0, err
+	}
+
+	for i, entry := range entries {
+		if err := r.Publish(ctx, entry); err != nil {
+			return i, err
+		}
+		_, err := r.Outbox.coll.UpdateOne(ctx,
+			bson.M{"_id": entry.ID},
+			bson.M{"$set": bson.M{"sent_at": time.Now().UTC()}},
+		)
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(entries), nil
+}
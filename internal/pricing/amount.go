@@ -0,0 +1,97 @@
+// Package pricing computes the prices of books: their list price less the
+// best discount of the price rules matching them, converted to other
+// currencies with a locally configured table of exchange rates, so that
+// prices work without any network access.
+//
+// Amounts are decimal strings like "12.99" in the API and whole cents
+// internally. Every currency is given with two decimals.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+)
+
+// MaxAmount is the largest amount accepted, in cents.
+const MaxAmount = 1_000_000_000_00
+
+var (
+	errAmount   = errors.New("must be an amount like 12.99")
+	errCurrency = errors.New("must be a three-letter currency code like EUR")
+)
+
+func init() {
+	// A non-negative amount with up to two decimals.
+	validation.Register("amount", func(value, _ string) string {
+		if _, err := ParseAmount(value); err != nil {
+			return err.Error()
+		}
+		return ""
+	})
+	// A three-letter currency code, in any case.
+	validation.Register("currency", func(value, _ string) string {
+		if _, err := ParseCurrency(value); err != nil {
+			return err.Error()
+		}
+		return ""
+	})
+}
+
+// ParseAmount reads an amount like "12", "12.5" or "12.99" as cents.
+// Negative amounts and more than two decimals are rejected.
+func ParseAmount(s string) (int64, error) {
+	whole, frac, hasFrac := strings.Cut(strings.TrimSpace(s), ".")
+	if whole == "" || len(frac) > 2 || hasFrac && frac == "" || !digits(whole) || !digits(frac) {
+		return 0, errAmount
+	}
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || n > MaxAmount/100 {
+		return 0, fmt.Errorf("must be at most %s", FormatAmount(MaxAmount))
+	}
+	cents := n * 100
+	if frac != "" {
+		f, _ := strconv.Atoi(frac + strings.Repeat("0", 2-len(frac)))
+		cents += int64(f)
+	}
+	if cents > MaxAmount {
+		return 0, fmt.Errorf("must be at most %s", FormatAmount(MaxAmount))
+	}
+	return cents, nil
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatAmount writes cents as an amount with two decimals, e.g. "12.50".
+func FormatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// ParseCurrency reads a three-letter currency code like "eur" as "EUR". It
+// does not check that the currency is in a rates table, see Rates.Has.
+func ParseCurrency(s string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	if len(code) != 3 {
+		return "", errCurrency
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", errCurrency
+		}
+	}
+	return code, nil
+}
@@ -0,0 +1,75 @@
+package pricing
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  int64
+		valid bool
+	}{
+		{"12", 1200, true},
+		{"12.5", 1250, true},
+		{"12.99", 1299, true},
+		{"0.07", 7, true},
+		{"0", 0, true},
+		{" 3.10 ", 310, true},
+		{"1000000000", MaxAmount, true},
+
+		{"", 0, false},
+		{"12.", 0, false},
+		{".5", 0, false},
+		{"-1", 0, false},
+		{"+1", 0, false},
+		{"1.234", 0, false},
+		{"1,50", 0, false},
+		{"1e3", 0, false},
+		{"1000000000.01", 0, false},
+		{"99999999999999999999", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in)
+		if (err == nil) != tt.valid || got != tt.want {
+			t.Errorf("ParseAmount(%q) = %d, %v, want %d, valid %v", tt.in, got, err, tt.want, tt.valid)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{1250, "12.50"},
+		{1299, "12.99"},
+		{-1250, "-12.50"},
+	}
+	for _, tt := range tests {
+		if got := FormatAmount(tt.in); got != tt.want {
+			t.Errorf("FormatAmount(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		in, want string
+		valid    bool
+	}{
+		{"EUR", "EUR", true},
+		{"eur", "EUR", true},
+		{" usd ", "USD", true},
+		{"", "", false},
+		{"EU", "", false},
+		{"EURO", "", false},
+		{"EU1", "", false},
+	}
+	for _, tt := range tests {
+		got, err := ParseCurrency(tt.in)
+		if (err == nil) != tt.valid || got != tt.want {
+			t.Errorf("ParseCurrency(%q) = %q, %v, want %q, valid %v", tt.in, got, err, tt.want, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package pricing
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rates.yaml
+var defaultRates []byte
+
+// ErrUnknownCurrency is returned for currencies missing from the rates
+// table.
+var ErrUnknownCurrency = errors.New("is not a currency of the rates table")
+
+// Rates is a table of exchange rates: Rates holds the units of each
+// currency that one unit of Base buys. Base itself is always 1.
+type Rates struct {
+	Base  string             `yaml:"base" json:"base"`
+	Rates map[string]float64 `yaml:"rates" json:"rates"`
+}
+
+// DefaultRates returns the built-in table, see rates.yaml.
+func DefaultRates() Rates {
+	rates, err := ParseRates(defaultRates)
+	if err != nil {
+		panic(fmt.Sprintf("pricing: invalid rates.yaml: %v", err))
+	}
+	return rates
+}
+
+// RatesFromEnv reads the table of the file named by PRICING_RATES_FILE, or
+// returns the built-in one if it is not set.
+func RatesFromEnv() (Rates, error) {
+	path := os.Getenv("PRICING_RATES_FILE")
+	if path == "" {
+		return DefaultRates(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rates{}, err
+	}
+	rates, err := ParseRates(data)
+	if err != nil {
+		return Rates{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return rates, nil
+}
+
+// MustRatesFromEnv is RatesFromEnv for the start of a service, which cannot
+// run without its rates: it panics if the file cannot be read.
+func MustRatesFromEnv() Rates {
+	rates, err := RatesFromEnv()
+	if err != nil {
+		panic(fmt.Sprintf("pricing: failed to load the exchange rates: %v", err))
+	}
+	return rates
+}
+
+// ParseRates reads a YAML rates table and checks that its currency codes
+// and rates are valid.
+func ParseRates(data []byte) (Rates, error) {
+	var raw Rates
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return Rates{}, err
+	}
+	base, err := ParseCurrency(raw.Base)
+	if err != nil {
+		return Rates{}, fmt.Errorf("base %q %w", raw.Base, err)
+	}
+	rates := Rates{Base: base, Rates: map[string]float64{base: 1}}
+	for code, rate := range raw.Rates {
+		c, err := ParseCurrency(code)
+		if err != nil {
+			return Rates{}, fmt.Errorf("%q %w", code, err)
+		}
+		if c == base {
+			continue
+		}
+		if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return Rates{}, fmt.Errorf("rate of %s must be a positive number", c)
+		}
+		rates.Rates[c] = rate
+	}
+	return rates, nil
+}
+
+// Has reports whether the table can convert from and to currency.
+func (r Rates) Has(currency string) bool {
+	_, ok := r.Rates[currency]
+	return ok
+}
+
+// Currencies returns the currencies of the table, ordered by code.
+func (r Rates) Currencies() []string {
+	ret := make([]string, 0, len(r.Rates))
+	for code := range r.Rates {
+		ret = append(ret, code)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Convert converts cents of from into cents of to, rounded to the nearest
+// cent.
+func (r Rates) Convert(cents int64, from, to string) (int64, error) {
+	if from == to {
+		return cents, nil
+	}
+	rateFrom, ok := r.Rates[from]
+	if !ok {
+		return 0, fmt.Errorf("%s %w", from, ErrUnknownCurrency)
+	}
+	rateTo, ok := r.Rates[to]
+	if !ok {
+		return 0, fmt.Errorf("%s %w", to, ErrUnknownCurrency)
+	}
+	return int64(math.Round(float64(cents) / rateFrom * rateTo)), nil
+}
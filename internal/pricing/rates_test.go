@@ -0,0 +1,74 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+)
+
+var testRates = Rates{
+	Base:  "EUR",
+	Rates: map[string]float64{"EUR": 1, "USD": 1.1, "JPY": 160},
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		cents    int64
+		from, to string
+		want     int64
+	}{
+		{1999, "EUR", "EUR", 1999},
+		{1999, "EUR", "USD", 2199},
+		{1000, "EUR", "JPY", 160000},
+		{1100, "USD", "EUR", 1000},
+		{1100, "USD", "JPY", 160000},
+		{500, "USD", "EUR", 455},
+		{1, "EUR", "USD", 1},
+		{5, "EUR", "USD", 6},
+		{0, "USD", "JPY", 0},
+	}
+	for _, tt := range tests {
+		got, err := testRates.Convert(tt.cents, tt.from, tt.to)
+		if err != nil || got != tt.want {
+			t.Errorf("Convert(%d, %s, %s) = %d, %v, want %d", tt.cents, tt.from, tt.to, got, err, tt.want)
+		}
+	}
+
+	for _, pair := range [][2]string{{"EUR", "XXX"}, {"XXX", "EUR"}} {
+		if _, err := testRates.Convert(100, pair[0], pair[1]); !errors.Is(err, ErrUnknownCurrency) {
+			t.Errorf("Convert(100, %s, %s) = %v, want ErrUnknownCurrency", pair[0], pair[1], err)
+		}
+	}
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates([]byte("base: usd\nrates:\n  eur: 0.9\n  USD: 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The base is normalized and always 1.
+	if rates.Base != "USD" || rates.Rates["USD"] != 1 || rates.Rates["EUR"] != 0.9 {
+		t.Errorf("ParseRates = %+v", rates)
+	}
+	if got := rates.Currencies(); len(got) != 2 || got[0] != "EUR" || got[1] != "USD" {
+		t.Errorf("Currencies() = %v", got)
+	}
+
+	for _, data := range []string{
+		"base: EURO\n",
+		"base: EUR\nrates:\n  US: 1.1\n",
+		"base: EUR\nrates:\n  USD: 0\n",
+		"base: EUR\nrates:\n  USD: -1.1\n",
+		"base: [EUR]\n",
+	} {
+		if _, err := ParseRates([]byte(data)); err == nil {
+			t.Errorf("ParseRates(%q) succeeded", data)
+		}
+	}
+}
+
+func TestDefaultRates(t *testing.T) {
+	rates := DefaultRates()
+	if rates.Base == "" || !rates.Has(rates.Base) || !rates.Has("USD") {
+		t.Errorf("DefaultRates() = %+v", rates)
+	}
+}
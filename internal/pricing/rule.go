@@ -0,0 +1,199 @@
+package pricing
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RuleCollection holds the price rules.
+const RuleCollection = "price_rules"
+
+// ErrRuleNotFound is returned when there is no price rule with the
+// requested id.
+var ErrRuleNotFound = errors.New("price rule not found")
+
+// Kinds of discounts.
+const (
+	// KindPercent takes Value percent off the list price.
+	KindPercent = "percent"
+	// KindFixed takes the amount Value in Currency off the list price.
+	KindFixed = "fixed"
+)
+
+// Rule is a discount on the books matching all of its conditions: a tag, an
+// author and a window of days. Conditions left empty match every book, so a
+// rule without any is a discount on the whole catalog. Rules do not add
+// up; a book gets the single discount leaving the lowest price.
+type Rule struct {
+	// ID is set by the catalog.
+	ID   string `bson:"id" json:"id"`
+	Name string `bson:"name" json:"name" validate:"required,max=100"`
+	Kind string `bson:"kind" json:"kind" validate:"required,oneof=percent fixed"`
+	// Value is a percentage like "12.5", up to 100, or an amount like
+	// "2.50".
+	Value string `bson:"value" json:"value" validate:"required,amount"`
+	// Currency is the currency of fixed discounts, the base currency of
+	// the rates table if empty. Percentages ignore it.
+	Currency string `bson:"currency" json:"currency" validate:"currency"`
+	// Tag and Author are compared ignoring case, Author with the names of
+	// the authors of the book; editors and translators do not count.
+	Tag    string `bson:"tag" json:"tag" validate:"max=50"`
+	Author string `bson:"author" json:"author" validate:"max=200"`
+	// From and Until are the first and the last day of the rule, in UTC.
+	From  string `bson:"from" json:"from" validate:"date"`
+	Until string `bson:"until" json:"until" validate:"date"`
+}
+
+// API returns the rule in the form used by the /api/pricing/rules
+// endpoints.
+func (r Rule) API() map[string]interface{} {
+	return map[string]interface{}{
+		"id":       r.ID,
+		"name":     r.Name,
+		"kind":     r.Kind,
+		"value":    r.Value,
+		"currency": r.Currency,
+		"tag":      r.Tag,
+		"author":   r.Author,
+		"from":     r.From,
+		"until":    r.Until,
+	}
+}
+
+// Merge copies the fields set in patch into r. Empty fields of patch are
+// left alone, so a patch cannot clear a condition: a rule limited to a tag,
+// an author or a window of days keeps that condition until it is deleted
+// and created again without it.
+func (r *Rule) Merge(patch Rule) {
+	for _, f := range []struct{ dst, src *string }{
+		{&r.Name, &patch.Name},
+		{&r.Kind, &patch.Kind},
+		{&r.Value, &patch.Value},
+		{&r.Currency, &patch.Currency},
+		{&r.Tag, &patch.Tag},
+		{&r.Author, &patch.Author},
+		{&r.From, &patch.From},
+		{&r.Until, &patch.Until},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+}
+
+// Item is what the rules look at in a book.
+type Item struct {
+	// ListPrice is an amount in Currency; items without one have no price.
+	ListPrice string
+	Currency  string
+	Tags      []string
+	Authors   []string
+}
+
+// Active reports whether the window of the rule includes the day of now.
+func (r Rule) Active(now time.Time) bool {
+	day := now.UTC().Format(time.DateOnly)
+	return (r.From == "" || r.From <= day) && (r.Until == "" || day <= r.Until)
+}
+
+// Matches reports whether the rule applies to item at now.
+func (r Rule) Matches(item Item, now time.Time) bool {
+	if !r.Active(now) {
+		return false
+	}
+	if r.Tag != "" && !slices.ContainsFunc(item.Tags, func(t string) bool { return strings.EqualFold(t, r.Tag) }) {
+		return false
+	}
+	if r.Author != "" && !slices.ContainsFunc(item.Authors, func(a string) bool { return strings.EqualFold(a, r.Author) }) {
+		return false
+	}
+	return true
+}
+
+// discount returns the price of cents in currency after the rule.
+func (r Rule) discount(cents int64, currency string, rates Rates) (int64, error) {
+	value, err := ParseAmount(r.Value)
+	if err != nil {
+		return 0, err
+	}
+	if r.Kind == KindPercent {
+		return cents - (cents*value+5000)/10000, nil
+	}
+	from := r.Currency
+	if from == "" {
+		from = rates.Base
+	}
+	off, err := rates.Convert(value, from, currency)
+	if err != nil {
+		return 0, err
+	}
+	return max(cents-off, 0), nil
+}
+
+// Price is the price of a book after its discount, in the currency it was
+// asked for.
+type Price struct {
+	Amount    string `json:"amount" xml:"amount" msgpack:"amount"`
+	Currency  string `json:"currency" xml:"currency" msgpack:"currency"`
+	ListPrice string `json:"listPrice" xml:"listPrice" msgpack:"listPrice"`
+	// Rule is the id of the rule giving the discount, empty without one.
+	Rule string `json:"rule" xml:"rule,omitempty" msgpack:"rule"`
+}
+
+// API returns the price in the form used in books.
+func (p Price) API() map[string]interface{} {
+	return map[string]interface{}{
+		"amount":    p.Amount,
+		"currency":  p.Currency,
+		"listPrice": p.ListPrice,
+		"rule":      p.Rule,
+	}
+}
+
+// Quote prices item at now: its list price less the best discount of the
+// matching rules, converted to currency, or left in the currency of the
+// item if currency is empty. It returns nil for items without a list price.
+func Quote(item Item, rules []Rule, rates Rates, currency string, now time.Time) (*Price, error) {
+	if item.ListPrice == "" {
+		return nil, nil
+	}
+	list, err := ParseAmount(item.ListPrice)
+	if err != nil {
+		return nil, err
+	}
+	if item.Currency == "" {
+		item.Currency = rates.Base
+	}
+	if currency == "" {
+		currency = item.Currency
+	}
+
+	best, rule := list, ""
+	for _, r := range rules {
+		if !r.Matches(item, now) {
+			continue
+		}
+		cents, err := r.discount(list, item.Currency, rates)
+		if err != nil {
+			return nil, err
+		}
+		if cents < best {
+			best, rule = cents, r.ID
+		}
+	}
+
+	if list, err = rates.Convert(list, item.Currency, currency); err != nil {
+		return nil, err
+	}
+	if best, err = rates.Convert(best, item.Currency, currency); err != nil {
+		return nil, err
+	}
+	return &Price{
+		Amount:    FormatAmount(best),
+		Currency:  currency,
+		ListPrice: FormatAmount(list),
+		Rule:      rule,
+	}, nil
+}
@@ -0,0 +1,195 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+func TestActive(t *testing.T) {
+	october := Rule{From: "2026-10-01", Until: "2026-10-31"}
+	berlin := time.FixedZone("CEST", 2*60*60)
+	tests := []struct {
+		rule Rule
+		now  time.Time
+		want bool
+	}{
+		{Rule{}, testNow, true},
+		{october, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), true},
+		{october, time.Date(2026, 10, 31, 23, 59, 59, 0, time.UTC), true},
+		{october, time.Date(2026, 9, 30, 23, 59, 59, 0, time.UTC), false},
+		{october, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), false},
+		// Days are UTC days: this is still the 31st of October in UTC.
+		{october, time.Date(2026, 11, 1, 1, 0, 0, 0, berlin), true},
+		{Rule{From: "2026-10-16"}, testNow, false},
+		{Rule{From: "2026-10-15"}, testNow, true},
+		{Rule{Until: "2026-10-14"}, testNow, false},
+		{Rule{Until: "2026-10-15"}, testNow, true},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Active(tt.now); got != tt.want {
+			t.Errorf("%+v.Active(%s) = %v, want %v", tt.rule, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	item := Item{
+		ListPrice: "19.99",
+		Tags:      []string{"fantasy", "Classics"},
+		Authors:   []string{"Ursula K. Le Guin"},
+	}
+	tests := []struct {
+		rule Rule
+		want bool
+	}{
+		{Rule{}, true},
+		{Rule{Tag: "classics"}, true},
+		{Rule{Tag: "FANTASY"}, true},
+		{Rule{Tag: "horror"}, false},
+		{Rule{Author: "ursula k. le guin"}, true},
+		{Rule{Author: "Le Guin"}, false},
+		{Rule{Tag: "fantasy", Author: "Ursula K. Le Guin"}, true},
+		{Rule{Tag: "fantasy", Author: "Tolkien"}, false},
+		{Rule{Tag: "fantasy", Until: "2026-10-14"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Matches(item, testNow); got != tt.want {
+			t.Errorf("%+v.Matches = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tenPercent := Rule{ID: "ten", Kind: KindPercent, Value: "10"}
+	tests := []struct {
+		name     string
+		item     Item
+		rules    []Rule
+		currency string
+		want     *Price
+	}{
+		{
+			name: "no list price",
+			item: Item{Currency: "EUR"},
+		},
+		{
+			name: "no rules",
+			item: Item{ListPrice: "19.99", Currency: "EUR"},
+			want: &Price{Amount: "19.99", Currency: "EUR", ListPrice: "19.99"},
+		},
+		{
+			name:  "percent rounds to the nearest cent",
+			item:  Item{ListPrice: "19.99", Currency: "EUR"},
+			rules: []Rule{tenPercent},
+			want:  &Price{Amount: "17.99", Currency: "EUR", ListPrice: "19.99", Rule: "ten"},
+		},
+		{
+			name:  "fractional percent",
+			item:  Item{ListPrice: "19.99", Currency: "EUR"},
+			rules: []Rule{{ID: "r", Kind: KindPercent, Value: "12.5"}},
+			want:  &Price{Amount: "17.49", Currency: "EUR", ListPrice: "19.99", Rule: "r"},
+		},
+		{
+			name:  "hundred percent",
+			item:  Item{ListPrice: "19.99", Currency: "EUR"},
+			rules: []Rule{{ID: "free", Kind: KindPercent, Value: "100"}},
+			want:  &Price{Amount: "0.00", Currency: "EUR", ListPrice: "19.99", Rule: "free"},
+		},
+		{
+			name:  "fixed in the base currency",
+			item:  Item{ListPrice: "19.99"},
+			rules: []Rule{{ID: "r", Kind: KindFixed, Value: "2.50"}},
+			want:  &Price{Amount: "17.49", Currency: "EUR", ListPrice: "19.99", Rule: "r"},
+		},
+		{
+			name:  "fixed in another currency",
+			item:  Item{ListPrice: "19.99", Currency: "EUR"},
+			rules: []Rule{{ID: "r", Kind: KindFixed, Value: "5", Currency: "USD"}},
+			want:  &Price{Amount: "15.44", Currency: "EUR", ListPrice: "19.99", Rule: "r"},
+		},
+		{
+			name:  "fixed above the list price",
+			item:  Item{ListPrice: "1.99", Currency: "EUR"},
+			rules: []Rule{{ID: "r", Kind: KindFixed, Value: "5"}},
+			want:  &Price{Amount: "0.00", Currency: "EUR", ListPrice: "1.99", Rule: "r"},
+		},
+		{
+			name: "the lowest price wins, discounts do not add up",
+			item: Item{ListPrice: "19.99", Currency: "EUR"},
+			rules: []Rule{
+				tenPercent,
+				{ID: "fixed", Kind: KindFixed, Value: "2.50"},
+				{ID: "small", Kind: KindFixed, Value: "1"},
+			},
+			want: &Price{Amount: "17.49", Currency: "EUR", ListPrice: "19.99", Rule: "fixed"},
+		},
+		{
+			name: "the first of equal discounts wins",
+			item: Item{ListPrice: "20", Currency: "EUR"},
+			rules: []Rule{
+				{ID: "first", Kind: KindFixed, Value: "2"},
+				tenPercent,
+			},
+			want: &Price{Amount: "18.00", Currency: "EUR", ListPrice: "20.00", Rule: "first"},
+		},
+		{
+			name: "rules that do not match are skipped",
+			item: Item{ListPrice: "19.99", Currency: "EUR", Tags: []string{"fantasy"}},
+			rules: []Rule{
+				{ID: "horror", Kind: KindPercent, Value: "50", Tag: "horror"},
+				{ID: "expired", Kind: KindPercent, Value: "50", Until: "2026-10-14"},
+				{ID: "fantasy", Kind: KindPercent, Value: "10", Tag: "fantasy"},
+			},
+			want: &Price{Amount: "17.99", Currency: "EUR", ListPrice: "19.99", Rule: "fantasy"},
+		},
+		{
+			name:     "converted after the discount",
+			item:     Item{ListPrice: "19.99", Currency: "EUR"},
+			rules:    []Rule{{ID: "r", Kind: KindFixed, Value: "2.50"}},
+			currency: "USD",
+			want:     &Price{Amount: "19.24", Currency: "USD", ListPrice: "21.99", Rule: "r"},
+		},
+		{
+			name:     "converted from the currency of the item",
+			item:     Item{ListPrice: "11", Currency: "USD"},
+			currency: "JPY",
+			want:     &Price{Amount: "1600.00", Currency: "JPY", ListPrice: "1600.00"},
+		},
+	}
+	for _, tt := range tests {
+		got, err := Quote(tt.item, tt.rules, testRates, tt.currency, testNow)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+			t.Errorf("%s: Quote = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteErrors(t *testing.T) {
+	item := Item{ListPrice: "19.99", Currency: "EUR"}
+	if _, err := Quote(item, nil, testRates, "XXX", testNow); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("Quote in XXX = %v, want ErrUnknownCurrency", err)
+	}
+	rules := []Rule{{Kind: KindFixed, Value: "1", Currency: "XXX"}}
+	if _, err := Quote(item, rules, testRates, "", testNow); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("Quote with a rule in XXX = %v, want ErrUnknownCurrency", err)
+	}
+	if _, err := Quote(Item{ListPrice: "cheap"}, nil, testRates, "", testNow); err == nil {
+		t.Error("Quote of an invalid list price succeeded")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	rule := Rule{ID: "r", Name: "Autumn", Kind: KindPercent, Value: "10", Tag: "fantasy", Until: "2026-10-31"}
+	rule.Merge(Rule{Name: "Halloween", Value: "13"})
+	want := Rule{ID: "r", Name: "Halloween", Kind: KindPercent, Value: "13", Tag: "fantasy", Until: "2026-10-31"}
+	if rule != want {
+		t.Errorf("Merge = %+v, want %+v", rule, want)
+	}
+}
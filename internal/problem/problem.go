@@ -0,0 +1,218 @@
+// Package problem implements the error responses of every binary: RFC 7807
+// problem details, sent as application/problem+json, or as
+// application/problem+xml to clients that only accept XML.
+//
+// Handlers return a *Problem as their error; Handler, installed as Echo's
+// HTTPErrorHandler by Register, writes it together with the request id.
+// Errors that are not problems, like Echo's own 404 and 405, are turned into
+// one on the way.
+package problem
+
+import (
+	"encoding/xml"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/CAPS-Cloud/exercises/internal/validation"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// Media types of problem details.
+const (
+	MediaType    = "application/problem+json"
+	MediaTypeXML = "application/problem+xml"
+)
+
+// Problem types, relative to the API. Problems without a more specific
+// type use "about:blank", their title is then the HTTP status text.
+const (
+	TypeValidation = "/problems/validation"
+	TypeNotFound   = "/problems/not-found"
+	TypeConflict   = "/problems/conflict"
+)
+
+// FieldError describes what is wrong with one field of a request.
+type FieldError = validation.FieldError
+
+// Problem is an error response.
+type Problem struct {
+	XMLName   xml.Name     `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type      string       `json:"type" xml:"type"`
+	Title     string       `json:"title" xml:"title"`
+	Status    int          `json:"status" xml:"status"`
+	Detail    string       `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty" xml:"instance,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty" xml:"-"`
+	RequestID string       `json:"requestId,omitempty" xml:"requestId,omitempty"`
+
+	// cause is logged, but not sent to the client.
+	cause error
+}
+
+func (p *Problem) Error() string {
+	msg := p.Title
+	if p.Detail != "" {
+		msg = p.Detail
+	}
+	if p.cause != nil {
+		msg += ": " + p.cause.Error()
+	}
+	return msg
+}
+
+func (p *Problem) Unwrap() error {
+	return p.cause
+}
+
+// New returns a problem of type "about:blank" with the given status.
+func New(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Validation returns a 400 problem for a request that is not valid, with
+// what is wrong with each field.
+func Validation(detail string, errs ...FieldError) *Problem {
+	return &Problem{
+		Type:   TypeValidation,
+		Title:  "Your request is not valid",
+		Status: http.StatusBadRequest,
+		Detail: detail,
+		Errors: errs,
+	}
+}
+
+// NotFound returns a 404 problem.
+func NotFound(detail string) *Problem {
+	return &Problem{
+		Type:   TypeNotFound,
+		Title:  "Resource not found",
+		Status: http.StatusNotFound,
+		Detail: detail,
+	}
+}
+
+// Conflict returns a 409 problem.
+func Conflict(detail string) *Problem {
+	return &Problem{
+		Type:   TypeConflict,
+		Title:  "Resource already exists",
+		Status: http.StatusConflict,
+		Detail: detail,
+	}
+}
+
+// Internal returns a 500 problem. The cause is logged, the client only sees
+// detail.
+func Internal(detail string, cause error) *Problem {
+	p := New(http.StatusInternalServerError, detail)
+	p.cause = cause
+	return p
+}
+
+// From turns any error into a problem. Validation errors become a 400,
+// Echo's HTTP errors keep their status, other errors become a 500 that does
+// not reveal them.
+func From(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return Validation("", fields...)
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		detail := ""
+		if msg, ok := he.Message.(string); ok && msg != http.StatusText(he.Code) {
+			detail = msg
+		}
+		p := New(he.Code, detail)
+		p.cause = he.Internal
+		return p
+	}
+	return Internal("", err)
+}
+
+// Register installs Handler as the error handler of e, and gives every
+// request an id, taken from its X-Request-Id header or generated.
+func Register(e *echo.Echo) {
+	e.Pre(middleware.RequestID())
+	e.HTTPErrorHandler = Handler
+}
+
+// Handler is an echo.HTTPErrorHandler writing every error as a problem.
+func Handler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	// Copy, so that problems kept in variables are not changed.
+	p := *From(err)
+	if p.Status >= http.StatusInternalServerError {
+		c.Logger().Errorf("%s %s: %v", c.Request().Method, c.Request().URL.Path, err)
+	}
+	p.Instance = c.Request().URL.Path
+	p.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+
+	if werr := Write(c, &p); werr != nil {
+		c.Logger().Error(werr)
+	}
+}
+
+// Write sends p in the format the client accepts.
+func Write(c echo.Context, p *Problem) error {
+	if c.Request().Method == http.MethodHead {
+		return c.NoContent(p.Status)
+	}
+
+	if !prefersXML(c.Request().Header.Get(echo.HeaderAccept)) {
+		c.Response().Header().Set(echo.HeaderContentType, MediaType)
+		return c.JSON(p.Status, p)
+	}
+
+	// An empty errors element would be written for errors>error.
+	type fieldErrors struct {
+		Errors []FieldError `xml:"error"`
+	}
+	out := struct {
+		*Problem
+		Errors *fieldErrors `xml:"errors,omitempty"`
+	}{Problem: p}
+	if len(p.Errors) > 0 {
+		out.Errors = &fieldErrors{p.Errors}
+	}
+	data, err := xml.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return c.Blob(p.Status, MediaTypeXML, append([]byte(xml.Header), data...))
+}
+
+// prefersXML reports whether an Accept header asks for XML but not for
+// JSON. Problems are JSON for everyone else, including protobuf and
+// MessagePack clients, whose formats have no problem details.
+func prefersXML(accept string) bool {
+	wantXML, wantJSON := false, false
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(part)
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(mt, "/json"), strings.HasSuffix(mt, "+json"), mt == "*/*", mt == "application/*":
+			wantJSON = true
+		case strings.HasSuffix(mt, "/xml"), strings.HasSuffix(mt, "+xml"), mt == "text/*":
+			wantXML = true
+		}
+	}
+	return wantXML && !wantJSON
+}
@@ -0,0 +1,188 @@
+// Package rpc serves the catalog over gRPC, next to the REST API. It uses
+// the same repository as the REST handlers, so both APIs validate and store
+// books the same way, and the same event feed for Watch.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/CAPS-Cloud/exercises/internal/bookpb"
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/catalog"
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"github.com/gogo/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Codec encodes the gogo/protobuf messages of bookpb. gRPC's default codec
+// only handles messages generated for google.golang.org/protobuf.
+type Codec struct{}
+
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("rpc: cannot marshal %T", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("rpc: cannot unmarshal into %T", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+func (Codec) Name() string {
+	return "proto"
+}
+
+// NewServer returns a gRPC server with the book service registered.
+func NewServer(repo *catalog.Repository, feed *events.Feed) *grpc.Server {
+	srv := grpc.NewServer(grpc.ForceServerCodec(Codec{}))
+	bookpb.RegisterBookServiceServer(srv, &service{repo: repo, feed: feed})
+	return srv
+}
+
+// Dial connects to a book service without TLS, using the matching codec.
+func Dial(target string) (bookpb.BookServiceClient, *grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(Codec{})),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bookpb.NewBookServiceClient(conn), conn, nil
+}
+
+// statusError maps repository errors onto gRPC status codes. Other errors
+// are logged and answered with a fixed message, like the 500 problems of the
+// REST API, so clients never see database details.
+func statusError(err error) error {
+	var invalid *catalog.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		return status.Error(codes.InvalidArgument, invalid.Error())
+	case errors.Is(err, catalog.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, books.ErrNotFound):
+		return status.Error(codes.NotFound, "Book not found")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		log.Printf("rpc: %v", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+type service struct {
+	repo *catalog.Repository
+	feed *events.Feed
+}
+
+func (s *service) Get(ctx context.Context, req *bookpb.GetBookRequest) (*bookpb.Book, error) {
+	book, err := s.repo.Get(ctx, req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return bookpb.FromBook(*book), nil
+}
+
+func (s *service) List(req *bookpb.ListBooksRequest, stream bookpb.BookService_ListServer) error {
+	list, err := s.repo.List(stream.Context())
+	if err != nil {
+		return statusError(err)
+	}
+	for _, book := range list {
+		if err := stream.Send(bookpb.FromBook(book)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) Create(ctx context.Context, req *bookpb.CreateBookRequest) (*bookpb.Book, error) {
+	book, err := s.repo.Create(ctx, req.Book.ToBook())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return bookpb.FromBook(*book), nil
+}
+
+func (s *service) Update(ctx context.Context, req *bookpb.UpdateBookRequest) (*bookpb.Book, error) {
+	book, err := s.repo.Update(ctx, req.Id, req.Book.ToBook())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return bookpb.FromBook(*book), nil
+}
+
+func (s *service) Delete(ctx context.Context, req *bookpb.DeleteBookRequest) (*bookpb.DeleteBookResponse, error) {
+	if err := s.repo.Delete(ctx, req.Id); err != nil {
+		return nil, statusError(err)
+	}
+	return &bookpb.DeleteBookResponse{}, nil
+}
+
+// Watch follows the same protocol as the /api/events WebSocket: a hello (or
+// reset) event first, then the missed events when resuming, then live ones.
+func (s *service) Watch(req *bookpb.WatchBooksRequest, stream bookpb.BookService_WatchServer) error {
+	var missed []events.Event
+	var ch <-chan events.Event
+	var cancel func()
+	resume := req.Stream != "" || req.Since != 0
+	ok := false
+	feedStream := s.feed.Stream()
+	if resume && req.Stream == feedStream {
+		missed, ch, cancel, ok = s.feed.SubscribeFrom(stream.Context(), req.Since)
+	}
+
+	first := &bookpb.BookEvent{Type: events.Hello, Seq: req.Since, Stream: feedStream}
+	if !ok {
+		first.Seq, ch, cancel = s.feed.Subscribe()
+		if resume {
+			first.Type = events.Reset
+		}
+	}
+	defer cancel()
+
+	if err := stream.Send(first); err != nil {
+		return err
+	}
+	for _, ev := range missed {
+		if err := stream.Send(eventToProto(ev)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case ev, ok := <-ch:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "client too slow")
+			}
+			if err := stream.Send(eventToProto(ev)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func eventToProto(ev events.Event) *bookpb.BookEvent {
+	return &bookpb.BookEvent{
+		Type:         ev.Type,
+		Seq:          ev.Seq,
+		TimeUnixNano: ev.Time.UnixNano(),
+		Book:         bookpb.FromBook(ev.Book),
+	}
+}
@@ -0,0 +1,205 @@
+// Package seed loads fixture books into the catalog on startup. Fixtures are
+// upserted by their id, so running the same seed twice leaves the collection
+// untouched and only reports what actually changed.
+package seed
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/CAPS-Cloud/exercises/internal/books"
+	"github.com/CAPS-Cloud/exercises/internal/isbn"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported values for the --seed flag.
+const (
+	ModeNone = "none"
+	ModeDemo = "demo"
+	ModeFile = "file"
+)
+
+//go:embed demo.yaml
+var demoFixtures []byte
+
+// Fixtures is the content of a fixtures file.
+type Fixtures struct {
+	Books []books.Book `json:"books" yaml:"books"`
+}
+
+// Options selects which fixtures are loaded. The defaults come from the
+// SEED and SEED_FILE environment variables, so the containers can be
+// configured the same way as DATABASE_URI.
+type Options struct {
+	Mode string
+	File string
+}
+
+// RegisterFlags adds --seed and --seed-file to fs. defaultMode is used when
+// the SEED environment variable is not set.
+func RegisterFlags(fs *flag.FlagSet, defaultMode string) *Options {
+	mode := os.Getenv("SEED")
+	if mode == "" {
+		mode = defaultMode
+	}
+
+	opts := &Options{}
+	fs.StringVar(&opts.Mode, "seed", mode, "seed data loaded on startup: none, demo or file")
+	fs.StringVar(&opts.File, "seed-file", os.Getenv("SEED_FILE"), "YAML or JSON fixtures file used with --seed=file")
+	return opts
+}
+
+// Demo returns the built-in demo catalog.
+func Demo() (*Fixtures, error) {
+	return Parse(demoFixtures, "yaml")
+}
+
+// Load reads a fixtures file. The format is picked from the file extension:
+// .yaml and .yml are read as YAML, everything else as JSON.
+func Load(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	format := "json"
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = "yaml"
+	}
+
+	fixtures, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return fixtures, nil
+}
+
+// Parse decodes fixtures in the given format ("json" or "yaml") and checks
+// that every book has a unique id. ISBNs are normalized to ISBN-13, author
+// strings split into lists of authors and tags and genres normalized.
+func Parse(data []byte, format string) (*Fixtures, error) {
+	var fixtures Fixtures
+	var err error
+	switch format {
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&fixtures)
+	case "yaml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err = dec.Decode(&fixtures); err == io.EOF {
+			err = nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown fixtures format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(fixtures.Books))
+	for i, book := range fixtures.Books {
+		if err := books.NewRequest(book).Validate(); err != nil {
+			return nil, fmt.Errorf("book %d (%q) is invalid: %w", i, book.ID, err)
+		}
+		if seen[book.ID] {
+			return nil, fmt.Errorf("duplicate book id %q", book.ID)
+		}
+		seen[book.ID] = true
+		fixtures.Books[i].ISBN = isbn.Normalize(book.ISBN)
+		fixtures.Books[i].NormalizeAuthors()
+		fixtures.Books[i].Tags = books.NormalizeTags(book.Tags)
+		fixtures.Books[i].Genres = books.NormalizeTags(book.Genres)
+	}
+
+	return &fixtures, nil
+}
+
+// Resolve returns the fixtures selected by the options, or nil when seeding
+// is disabled.
+func (o *Options) Resolve() (*Fixtures, error) {
+	switch o.Mode {
+	case ModeNone, "":
+		return nil, nil
+	case ModeDemo:
+		return Demo()
+	case ModeFile:
+		if o.File == "" {
+			return nil, errors.New("--seed=file requires --seed-file or SEED_FILE")
+		}
+		return Load(o.File)
+	default:
+		return nil, fmt.Errorf("unknown seed mode %q, expected none, demo or file", o.Mode)
+	}
+}
+
+// Report lists the ids touched by a seed run.
+type Report struct {
+	Inserted  []string
+	Updated   []string
+	Unchanged []string
+}
+
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "seed: %d inserted, %d updated, %d unchanged\n",
+		len(r.Inserted), len(r.Updated), len(r.Unchanged))
+	for _, id := range r.Inserted {
+		fmt.Fprintf(&sb, "  + %s\n", id)
+	}
+	for _, id := range r.Updated {
+		fmt.Fprintf(&sb, "  ~ %s\n", id)
+	}
+	return sb.String()
+}
+
+// Apply upserts every fixture book into coll, matching existing documents by
+// id. Fields missing from a fixture are overwritten with empty values, so the
+// fixtures file stays the single source of truth for the books it lists.
+func Apply(ctx context.Context, coll *mongo.Collection, fixtures *Fixtures) (*Report, error) {
+	report := &Report{}
+	for _, book := range fixtures.Books {
+		result, err := coll.UpdateOne(ctx,
+			bson.M{"id": book.ID},
+			bson.M{"$set": book},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return report, fmt.Errorf("upsert %q: %w", book.ID, err)
+		}
+
+		switch {
+		case result.UpsertedCount > 0:
+			report.Inserted = append(report.Inserted, book.ID)
+		case result.ModifiedCount > 0:
+			report.Updated = append(report.Updated, book.ID)
+		default:
+			report.Unchanged = append(report.Unchanged, book.ID)
+		}
+	}
+	return report, nil
+}
+
+// Run resolves the options and applies the selected fixtures. It returns a
+// nil report when seeding is disabled.
+func Run(ctx context.Context, coll *mongo.Collection, opts *Options) (*Report, error) {
+	fixtures, err := opts.Resolve()
+	if err != nil || fixtures == nil {
+		return nil, err
+	}
+	return Apply(ctx, coll, fixtures)
+}
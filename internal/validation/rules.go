@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+func init() {
+	Register("max", maxLength)
+	Register("min", minLength)
+	Register("oneof", oneOf)
+	Register("positive", positive)
+	Register("between", between)
+	Register("year", year)
+	Register("date", date)
+}
+
+func maxLength(value, param string) string {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		panic(fmt.Sprintf("validation: invalid max=%s", param))
+	}
+	if utf8.RuneCountInString(value) > n {
+		return fmt.Sprintf("must be at most %d characters long", n)
+	}
+	return ""
+}
+
+func minLength(value, param string) string {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		panic(fmt.Sprintf("validation: invalid min=%s", param))
+	}
+	if utf8.RuneCountInString(value) < n {
+		return fmt.Sprintf("must be at least %d characters long", n)
+	}
+	return ""
+}
+
+func oneOf(value, param string) string {
+	values := strings.Fields(param)
+	if !slices.Contains(values, value) {
+		return "must be one of " + strings.Join(values, ", ")
+	}
+	return ""
+}
+
+func positive(value, _ string) string {
+	if n, err := strconv.ParseUint(value, 10, 32); err != nil || n == 0 {
+		return "must be a positive whole number"
+	}
+	return ""
+}
+
+func between(value, param string) string {
+	var lo, hi int
+	if _, err := fmt.Sscanf(param, "%d %d", &lo, &hi); err != nil {
+		panic(fmt.Sprintf("validation: invalid between=%s", param))
+	}
+	if n, err := strconv.Atoi(value); err != nil || n < lo || n > hi {
+		return fmt.Sprintf("must be a whole number from %d to %d", lo, hi)
+	}
+	return ""
+}
+
+func year(value, _ string) string {
+	// Next year, for books announced but not yet published.
+	last := time.Now().Year() + 1
+	if n, err := strconv.Atoi(value); err != nil || n < 1 || n > last {
+		return fmt.Sprintf("must be a year from 1 to %d", last)
+	}
+	return ""
+}
+
+func date(value, _ string) string {
+	if _, err := time.Parse(time.DateOnly, value); err != nil {
+		return "must be a date like 2025-12-31"
+	}
+	return ""
+}
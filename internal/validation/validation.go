@@ -0,0 +1,205 @@
+// Package validation checks structs against rules declared in their
+// validate tags, e.g.
+//
+//	type Request struct {
+//		Title string `json:"title" validate:"required,max=300"`
+//		Year  string `json:"year" validate:"year"`
+//	}
+//
+// Every field is checked and all failures are returned together, named
+// after the json tag of their field. Rules other than required accept empty
+// values, so optional fields only need to be valid when they are set.
+//
+// Rules:
+//
+//	required             the value must not be empty
+//	required_without=F   the value must not be empty when field F is
+//	max=N                at most N characters
+//	min=N                at least N characters
+//	oneof=A B            one of the space-separated values
+//	positive             a whole number greater than zero
+//	between=A B          a whole number from A to B
+//	year                 a whole number from 1 to next year
+//	date                 a date like 2025-12-31
+//
+// The packages of the domain register their own rules with Register, like
+// isbn and genre in books, or amount and currency in pricing.
+//
+// Fields are strings, whole numbers which count as empty when zero, slices
+// of strings whose elements each have to pass the rules, or slices of
+// structs tagged validate:"dive" whose elements are checked in turn.
+// Failures of elements are named like genres[1] or authors[0].name.
+// Elements are always checked in full, also by Partial: a patch replaces
+// the whole slice.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FieldError describes what is wrong with one field.
+type FieldError struct {
+	Field   string `json:"field,omitempty" xml:"field,attr,omitempty"`
+	Message string `json:"message" xml:",chardata"`
+}
+
+// Errors lists the fields that failed their rules, in the order of the
+// struct fields.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, f := range e {
+		parts = append(parts, strings.TrimSpace(f.Field+" "+f.Message))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Rule checks a non-empty value. param is what follows "=" in the tag. It
+// returns a message like "must be a number", or "" if the value is valid.
+type Rule func(value, param string) string
+
+var (
+	mu    sync.RWMutex
+	rules = map[string]Rule{}
+)
+
+// Register adds a rule usable in validate tags. It panics if the name is
+// taken.
+func Register(name string, rule Rule) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := rules[name]; ok || name == "required" || name == "required_without" || name == "dive" {
+		panic(fmt.Sprintf("validation: rule %q registered twice", name))
+	}
+	rules[name] = rule
+}
+
+// Struct checks v, a struct or a pointer to one. It returns nil if every
+// field is valid.
+func Struct(v interface{}) error {
+	return check(v, false)
+}
+
+// Partial is Struct without the required rule, for updates that only
+// change the fields they set.
+func Partial(v interface{}) error {
+	return check(v, true)
+}
+
+func check(v interface{}, partial bool) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: %T is not a struct", v))
+	}
+	if errs := checkStruct(rv, "", partial); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func checkStruct(rv reflect.Value, prefix string, partial bool) Errors {
+	var errs Errors
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name := prefix + FieldName(sf)
+
+		if tag == "dive" {
+			if sf.Type.Kind() != reflect.Slice || sf.Type.Elem().Kind() != reflect.Struct {
+				panic(fmt.Sprintf("validation: field %s of %s is not a slice of structs", sf.Name, rt))
+			}
+			for j := 0; j < rv.Field(i).Len(); j++ {
+				errs = append(errs, checkStruct(rv.Field(i).Index(j), fmt.Sprintf("%s[%d].", name, j), false)...)
+			}
+			continue
+		}
+		if sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.String {
+			for j := 0; j < rv.Field(i).Len(); j++ {
+				if msg := checkField(rv, rv.Field(i).Index(j).String(), tag, false); msg != "" {
+					errs = append(errs, FieldError{Field: fmt.Sprintf("%s[%d]", name, j), Message: msg})
+				}
+			}
+			continue
+		}
+		var value string
+		switch sf.Type.Kind() {
+		case reflect.String:
+			value = rv.Field(i).String()
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			if n := rv.Field(i).Int(); n != 0 {
+				value = strconv.FormatInt(n, 10)
+			}
+		default:
+			panic(fmt.Sprintf("validation: field %s of %s is not a string or a whole number", sf.Name, rt))
+		}
+		if msg := checkField(rv, value, tag, partial); msg != "" {
+			errs = append(errs, FieldError{Field: name, Message: msg})
+		}
+	}
+	return errs
+}
+
+// checkField returns the message of the first rule of tag that value fails.
+// parent is the struct holding the field.
+func checkField(parent reflect.Value, value, tag string, partial bool) string {
+	for _, spec := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(spec, "=")
+		switch name {
+		case "required":
+			if value == "" && !partial {
+				return "is required"
+			}
+			continue
+		case "required_without":
+			if value == "" && !partial && empty(parent, param) {
+				return "is required without " + param
+			}
+			continue
+		}
+		if value == "" {
+			continue
+		}
+
+		mu.RLock()
+		rule, ok := rules[name]
+		mu.RUnlock()
+		if !ok {
+			panic(fmt.Sprintf("validation: unknown rule %q", name))
+		}
+		if msg := rule(value, param); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// empty reports whether the field of parent called name is empty.
+func empty(parent reflect.Value, name string) bool {
+	rt := parent.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		if FieldName(rt.Field(i)) == name {
+			v := parent.Field(i)
+			return v.IsZero() || v.Kind() == reflect.Slice && v.Len() == 0
+		}
+	}
+	panic(fmt.Sprintf("validation: %s has no field %q", rt, name))
+}
+
+// FieldName is the name of a field in requests and errors: the name of its
+// json tag, or its Go name.
+func FieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
@@ -0,0 +1,174 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+type contributor struct {
+	Name     string `json:"name" validate:"required_without=authorId,max=10"`
+	Role     string `json:"role" validate:"oneof=author editor"`
+	AuthorID string `json:"authorId" validate:"max=5"`
+}
+
+type request struct {
+	ID      string        `json:"id" validate:"required,max=5"`
+	Author  string        `json:"author" validate:"required_without=authors"`
+	Authors []contributor `json:"authors" validate:"dive"`
+	Rating  int           `json:"rating" validate:"between=1 5"`
+	Pages   string        `validate:"positive"`
+	Tags    []string      `json:"tags" validate:"min=2,max=4"`
+	Note    string        `json:"-"`
+}
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		name string
+		req  request
+		want Errors
+	}{
+		{
+			name: "valid",
+			req:  request{ID: "b1", Author: "Mary", Rating: 5, Pages: "12", Tags: []string{"ab", "abcd"}},
+		},
+		{
+			name: "required and max",
+			req:  request{Author: "Mary"},
+			want: Errors{{Field: "id", Message: "is required"}},
+		},
+		{
+			name: "first failing rule",
+			req:  request{ID: "abcdef", Author: "Mary"},
+			want: Errors{{Field: "id", Message: "must be at most 5 characters long"}},
+		},
+		{
+			name: "required without an empty field",
+			req:  request{ID: "b1"},
+			want: Errors{{Field: "author", Message: "is required without authors"}},
+		},
+		{
+			name: "required without a set field",
+			req:  request{ID: "b1", Authors: []contributor{{Name: "Mary"}}},
+		},
+		{
+			name: "dive",
+			req: request{ID: "b1", Authors: []contributor{
+				{Name: "Mary", Role: "author"},
+				{Role: "reader"},
+				{AuthorID: "a1"},
+				{Name: "Mary Wollstonecraft Shelley", AuthorID: "author-1"},
+			}},
+			want: Errors{
+				{Field: "authors[1].name", Message: "is required without authorId"},
+				{Field: "authors[1].role", Message: "must be one of author, editor"},
+				{Field: "authors[3].name", Message: "must be at most 10 characters long"},
+				{Field: "authors[3].authorId", Message: "must be at most 5 characters long"},
+			},
+		},
+		{
+			name: "between",
+			req:  request{ID: "b1", Author: "Mary", Rating: 6},
+			want: Errors{{Field: "rating", Message: "must be a whole number from 1 to 5"}},
+		},
+		{
+			name: "between below",
+			req:  request{ID: "b1", Author: "Mary", Rating: -1},
+			want: Errors{{Field: "rating", Message: "must be a whole number from 1 to 5"}},
+		},
+		{
+			name: "field without json tag",
+			req:  request{ID: "b1", Author: "Mary", Pages: "0"},
+			want: Errors{{Field: "Pages", Message: "must be a positive whole number"}},
+		},
+		{
+			name: "elements of string slices",
+			req:  request{ID: "b1", Author: "Mary", Tags: []string{"ab", "a", "", "abcde"}},
+			want: Errors{
+				{Field: "tags[1]", Message: "must be at least 2 characters long"},
+				{Field: "tags[3]", Message: "must be at most 4 characters long"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		err := Struct(&tt.req)
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%s: Struct = %v, want nil", tt.name, err)
+			}
+			continue
+		}
+		if got, _ := err.(Errors); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Struct = %#v, want %#v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestPartial(t *testing.T) {
+	// Partial skips required and required_without, but not the other rules.
+	if err := Partial(request{}); err != nil {
+		t.Errorf("Partial of an empty request = %v, want nil", err)
+	}
+	err := Partial(request{ID: "abcdef", Rating: 9})
+	want := Errors{
+		{Field: "id", Message: "must be at most 5 characters long"},
+		{Field: "rating", Message: "must be a whole number from 1 to 5"},
+	}
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("Partial = %#v, want %#v", err, want)
+	}
+
+	// A patch replaces the whole slice, so its elements are checked in full.
+	err = Partial(request{Authors: []contributor{{Role: "author"}}})
+	want = Errors{{Field: "authors[0].name", Message: "is required without authorId"}}
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("Partial with authors = %#v, want %#v", err, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	errs := Errors{{Field: "id", Message: "is required"}, {Message: "is empty"}}
+	if got, want := errs.Error(), "id is required; is empty"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("test_even", func(value, _ string) string {
+		if len(value)%2 != 0 {
+			return "must have an even length"
+		}
+		return ""
+	})
+	type even struct {
+		Value string `json:"value" validate:"test_even"`
+	}
+	want := Errors{{Field: "value", Message: "must have an even length"}}
+	if err := Struct(even{"abc"}); !reflect.DeepEqual(err, want) {
+		t.Errorf("Struct = %#v, want %#v", err, want)
+	}
+	if err := Struct(even{"ab"}); err != nil {
+		t.Errorf("Struct = %v, want nil", err)
+	}
+
+	for _, name := range []string{"test_even", "max", "required", "required_without", "dive"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q) did not panic", name)
+				}
+			}()
+			Register(name, func(string, string) string { return "" })
+		}()
+	}
+}
+
+func TestUnknownRule(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Struct with an unknown rule did not panic")
+		}
+	}()
+	Struct(struct {
+		Value string `validate:"no_such_rule"`
+	}{"x"})
+}
@@ -0,0 +1,145 @@
+package webhooks
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/CAPS-Cloud/exercises/internal/problem"
+	"github.com/labstack/echo/v4"
+)
+
+// subscriptionRequest is the body of POST and PUT /api/webhooks.
+type subscriptionRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+func (r *subscriptionRequest) validate() error {
+	var fields []problem.FieldError
+	u, err := url.Parse(r.URL)
+	switch {
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		fields = append(fields, problem.FieldError{Field: "url", Message: "must be an absolute http or https URL"})
+	case checkTarget(u) != nil:
+		fields = append(fields, problem.FieldError{Field: "url", Message: "must not point to a loopback, private or link-local address"})
+	}
+	for _, ev := range r.Events {
+		if !slices.Contains(EventTypes, ev) {
+			fields = append(fields, problem.FieldError{
+				Field:   "events",
+				Message: fmt.Sprintf("unknown event %q, expected one of %v", ev, EventTypes),
+			})
+		}
+	}
+	if len(fields) > 0 {
+		return problem.Validation("Invalid webhook", fields...)
+	}
+	return nil
+}
+
+// Register adds the /api/webhooks routes to e:
+//
+//	GET    /api/webhooks                          list subscriptions
+//	POST   /api/webhooks                          create a subscription
+//	GET    /api/webhooks/:id                      get a subscription
+//	PUT    /api/webhooks/:id                      update a subscription
+//	DELETE /api/webhooks/:id                      delete a subscription
+//	GET    /api/webhooks/dead-letters             list failed deliveries
+//	POST   /api/webhooks/dead-letters/:id/retry   queue a failed delivery again
+//
+// The secret is only returned when a subscription is created.
+func Register(e *echo.Echo, store *Store) {
+	g := e.Group("/api/webhooks")
+
+	g.GET("", func(c echo.Context) error {
+		subs, err := store.List(c.Request().Context())
+		if err != nil {
+			return problem.Internal("Failed to list webhooks", err)
+		}
+		for i := range subs {
+			subs[i].Secret = ""
+		}
+		return c.JSON(http.StatusOK, subs)
+	})
+
+	g.POST("", func(c echo.Context) error {
+		var req subscriptionRequest
+		if err := c.Bind(&req); err != nil {
+			return problem.Validation("Invalid request body")
+		}
+		if err := req.validate(); err != nil {
+			return err
+		}
+
+		sub := &Subscription{URL: req.URL, Events: req.Events, Secret: req.Secret}
+		if err := store.Create(c.Request().Context(), sub); err != nil {
+			return problem.Internal("Failed to create webhook", err)
+		}
+		return c.JSON(http.StatusCreated, sub)
+	})
+
+	g.GET("/:id", func(c echo.Context) error {
+		sub, err := store.Get(c.Request().Context(), c.Param("id"))
+		if err == ErrNotFound {
+			return problem.NotFound("Webhook not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to get webhook", err)
+		}
+		sub.Secret = ""
+		return c.JSON(http.StatusOK, sub)
+	})
+
+	g.PUT("/:id", func(c echo.Context) error {
+		var req subscriptionRequest
+		if err := c.Bind(&req); err != nil {
+			return problem.Validation("Invalid request body")
+		}
+		if err := req.validate(); err != nil {
+			return err
+		}
+
+		sub := &Subscription{ID: c.Param("id"), URL: req.URL, Events: req.Events, Secret: req.Secret}
+		err := store.Update(c.Request().Context(), sub)
+		if err == ErrNotFound {
+			return problem.NotFound("Webhook not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to update webhook", err)
+		}
+		return c.NoContent(http.StatusOK)
+	})
+
+	g.DELETE("/:id", func(c echo.Context) error {
+		err := store.Delete(c.Request().Context(), c.Param("id"))
+		if err == ErrNotFound {
+			return problem.NotFound("Webhook not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to delete webhook", err)
+		}
+		return c.NoContent(http.StatusOK)
+	})
+
+	g.GET("/dead-letters", func(c echo.Context) error {
+		deliveries, err := store.DeadLetters(c.Request().Context())
+		if err != nil {
+			return problem.Internal("Failed to list dead letters", err)
+		}
+		return c.JSON(http.StatusOK, deliveries)
+	})
+
+	g.POST("/dead-letters/:id/retry", func(c echo.Context) error {
+		err := store.Retry(c.Request().Context(), c.Param("id"))
+		if err == ErrNotFound {
+			return problem.NotFound("Dead letter not found")
+		}
+		if err != nil {
+			return problem.Internal("Failed to retry delivery", err)
+		}
+		return c.NoContent(http.StatusAccepted)
+	})
+}
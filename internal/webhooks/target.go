@@ -0,0 +1,92 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenTarget is returned when a webhook would reach an address that
+// is not public: loopback, private, link-local (like the cloud metadata
+// endpoint 169.254.169.254) and the other special-purpose ranges. Anybody
+// can subscribe a URL, so the worker must not become a way into the
+// network it runs in.
+var ErrForbiddenTarget = errors.New("the webhook target is not a public address")
+
+// reserved are the special-purpose ranges that netip does not classify.
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// publicAddr reports whether webhooks may be delivered to addr.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	// Loopback, link-local, multicast and unspecified addresses are not
+	// global unicast; private ones are.
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, p := range reserved {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkTarget rejects URLs that obviously point inside the network, so
+// clients learn about it when they subscribe. Host names can resolve to
+// anything, and differently later, so the worker checks every address it
+// connects to again, see newClient.
+func checkTarget(u *url.URL) error {
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrForbiddenTarget
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddr(addr) {
+		return ErrForbiddenTarget
+	}
+	return nil
+}
+
+// newClient returns the HTTP client of the worker. Its dialer refuses
+// connections to addresses that are not public after the host name was
+// resolved, which covers redirects and DNS names pointing inside the
+// network as well. Proxies are not used, they would be dialed instead of
+// the target.
+func newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !publicAddr(addr) {
+				return fmt.Errorf("%w: %s", ErrForbiddenTarget, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
@@ -0,0 +1,265 @@
+// Package webhooks delivers book events to URLs registered by clients.
+//
+// The book handlers enqueue a delivery per matching subscription; a worker
+// sends them with an HMAC-SHA256 signature, retrying with exponential
+// backoff. Deliveries that keep failing end up in a dead-letter list. Both
+// subscriptions and deliveries live in MongoDB, so any service can enqueue
+// and any number of workers can send.
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/CAPS-Cloud/exercises/internal/events"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collections used by the store.
+const (
+	SubscriptionCollection = "webhooks"
+	DeliveryCollection     = "webhook_deliveries"
+)
+
+// Retention is how long finished deliveries are kept: delivered ones, and
+// dead ones, which can be retried until then. A TTL index on FinishedAt
+// removes them afterwards.
+const Retention = 30 * 24 * time.Hour
+
+// Delivery states.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+// EventTypes are the events a subscription can ask for.
+var EventTypes = []string{
+	events.BookCreated, events.BookUpdated, events.BookDeleted,
+	events.AuthorCreated, events.AuthorUpdated, events.AuthorDeleted,
+	events.ReviewCreated, events.ReviewUpdated, events.ReviewDeleted,
+	events.StockAdjusted,
+	events.PriceRuleCreated, events.PriceRuleUpdated, events.PriceRuleDeleted,
+	events.OrderCreated, events.OrderUpdated,
+	events.CopyAdded, events.CopyRemoved, events.LoanCreated, events.LoanReturned,
+	events.HoldPlaced, events.HoldReady, events.HoldCancelled,
+}
+
+// ErrNotFound is returned for unknown subscriptions and deliveries.
+var ErrNotFound = errors.New("not found")
+
+// Subscription is a registered webhook. An empty Events list subscribes to
+// every event type.
+type Subscription struct {
+	ID        string    `bson:"id" json:"id"`
+	URL       string    `bson:"url" json:"url"`
+	Events    []string  `bson:"events" json:"events"`
+	Secret    string    `bson:"secret" json:"secret,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"createdAt"`
+}
+
+// Wants reports whether the subscription receives events of type t.
+func (s *Subscription) Wants(t string) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, t)
+}
+
+// Delivery is a single event on its way to a subscription.
+type Delivery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SubscriptionID string             `bson:"subscription_id" json:"subscriptionId"`
+	Event          string             `bson:"event" json:"event"`
+	Payload        string             `bson:"payload" json:"payload"`
+	Status         string             `bson:"status" json:"status"`
+	Attempts       int                `bson:"attempts" json:"attempts"`
+	NextAttempt    time.Time          `bson:"next_attempt" json:"nextAttempt"`
+	LastError      string             `bson:"last_error,omitempty" json:"lastError,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at" json:"createdAt"`
+	// FinishedAt is set when the delivery is delivered or dead.
+	FinishedAt *time.Time `bson:"finished_at,omitempty" json:"finishedAt,omitempty"`
+}
+
+// Payload is the JSON body posted to a webhook.
+type Payload struct {
+	Delivery string      `json:"delivery"`
+	Type     string      `json:"type"`
+	Time     time.Time   `json:"time"`
+	Data     interface{} `json:"data"`
+}
+
+// Store keeps subscriptions and deliveries.
+type Store struct {
+	subs       *mongo.Collection
+	deliveries *mongo.Collection
+}
+
+// NewStore returns a store using the collections of db.
+func NewStore(db *mongo.Database) *Store {
+	return &Store{
+		subs:       db.Collection(SubscriptionCollection),
+		deliveries: db.Collection(DeliveryCollection),
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Create stores a new subscription, filling in its id, creation time and,
+// if none was given, a random secret.
+func (s *Store) Create(ctx context.Context, sub *Subscription) error {
+	sub.ID = randomHex(8)
+	sub.CreatedAt = time.Now().UTC()
+	if sub.Secret == "" {
+		sub.Secret = randomHex(32)
+	}
+	if sub.Events == nil {
+		sub.Events = []string{}
+	}
+	_, err := s.subs.InsertOne(ctx, sub)
+	return err
+}
+
+// List returns every subscription.
+func (s *Store) List(ctx context.Context) ([]Subscription, error) {
+	cursor, err := s.subs.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	ret := []Subscription{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Get returns a subscription by id.
+func (s *Store) Get(ctx context.Context, id string) (*Subscription, error) {
+	var sub Subscription
+	err := s.subs.FindOne(ctx, bson.M{"id": id}).Decode(&sub)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Update replaces the URL, events and, if set, the secret of a subscription.
+func (s *Store) Update(ctx context.Context, sub *Subscription) error {
+	set := bson.M{"url": sub.URL, "events": sub.Events}
+	if sub.Events == nil {
+		set["events"] = []string{}
+	}
+	if sub.Secret != "" {
+		set["secret"] = sub.Secret
+	}
+	result, err := s.subs.UpdateOne(ctx, bson.M{"id": sub.ID}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a subscription. Its pending deliveries are dropped by the
+// worker when they come up.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	result, err := s.subs.DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Enqueue creates a delivery of the event for every subscription that wants
+// it. data is sent as the "data" field of the payload.
+func (s *Store) Enqueue(ctx context.Context, eventType string, data interface{}) error {
+	subs, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var docs []interface{}
+	for _, sub := range subs {
+		if !sub.Wants(eventType) {
+			continue
+		}
+
+		id := primitive.NewObjectID()
+		payload, err := json.Marshal(Payload{Delivery: id.Hex(), Type: eventType, Time: now, Data: data})
+		if err != nil {
+			return err
+		}
+		docs = append(docs, Delivery{
+			ID:             id,
+			SubscriptionID: sub.ID,
+			Event:          eventType,
+			Payload:        string(payload),
+			Status:         StatusPending,
+			NextAttempt:    now,
+			CreatedAt:      now,
+		})
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+	_, err = s.deliveries.InsertMany(ctx, docs)
+	return err
+}
+
+// DeadLetters returns the deliveries that were given up on, newest first.
+func (s *Store) DeadLetters(ctx context.Context) ([]Delivery, error) {
+	cursor, err := s.deliveries.Find(ctx,
+		bson.M{"status": StatusDead},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ret := []Delivery{}
+	if err := cursor.All(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Retry puts a dead delivery back into the queue with a fresh attempt count.
+// It is no longer finished, so it does not expire.
+func (s *Store) Retry(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	result, err := s.deliveries.UpdateOne(ctx,
+		bson.M{"_id": oid, "status": StatusDead},
+		bson.M{
+			"$set":   bson.M{"status": StatusPending, "attempts": 0, "next_attempt": time.Now().UTC()},
+			"$unset": bson.M{"finished_at": ""},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Headers set on every delivery.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Sign returns the value of the signature header for body: "sha256=" and the
+// hex encoded HMAC-SHA256 of the body keyed with the subscription secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// queue is what the worker needs of a Store. The tests use one in memory.
+type queue interface {
+	Get(ctx context.Context, id string) (*Subscription, error)
+	claim(ctx context.Context, now time.Time, lease time.Duration) (*Delivery, error)
+	reschedule(ctx context.Context, id primitive.ObjectID, next time.Time, lastError string) error
+	finish(ctx context.Context, id primitive.ObjectID, at time.Time, status, lastError string) error
+}
+
+// errQueueEmpty is returned by claim when no delivery is due.
+var errQueueEmpty = errors.New("no delivery is due")
+
+// Worker sends the queued deliveries.
+type Worker struct {
+	queue queue
+	// now is the clock of the worker, time.Now outside the tests.
+	now func() time.Time
+
+	// Client sends the deliveries. The one of NewWorker only connects to
+	// public addresses.
+	Client *http.Client
+
+	// Backoff is the delay before the first retry; it doubles with every
+	// further attempt up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxAttempts is the number of attempts before a delivery is moved to
+	// the dead-letter list.
+	MaxAttempts int
+	// PollInterval is how often the queue is checked when it is empty.
+	PollInterval time.Duration
+	// Lease is how long a claimed delivery is hidden from other workers.
+	Lease time.Duration
+}
+
+// NewWorker returns a worker with the default retry policy.
+func NewWorker(store *Store) *Worker {
+	return &Worker{
+		queue:        store,
+		now:          time.Now,
+		Client:       newClient(),
+		Backoff:      5 * time.Second,
+		MaxBackoff:   time.Hour,
+		MaxAttempts:  8,
+		PollInterval: time.Second,
+		Lease:        time.Minute,
+	}
+}
+
+func (w *Worker) backoff(attempts int) time.Duration {
+	d := w.Backoff
+	for i := 1; i < attempts && d < w.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, w.MaxBackoff)
+}
+
+// Run sends deliveries until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) error {
+	for {
+		err := w.work(ctx)
+		if err != nil && err != errQueueEmpty {
+			log.Printf("webhooks: %v", err)
+		}
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(w.PollInterval):
+			}
+		}
+	}
+}
+
+// work claims the next due delivery and sends it. It returns errQueueEmpty
+// if no delivery is due.
+func (w *Worker) work(ctx context.Context) error {
+	delivery, err := w.queue.claim(ctx, w.now().UTC(), w.Lease)
+	if err == errQueueEmpty {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("claiming a delivery failed: %w", err)
+	}
+	if err := w.deliver(ctx, delivery); err != nil {
+		return fmt.Errorf("delivery %s: %w", delivery.ID.Hex(), err)
+	}
+	return nil
+}
+
+func (w *Worker) deliver(ctx context.Context, d *Delivery) error {
+	sub, err := w.queue.Get(ctx, d.SubscriptionID)
+	if err == ErrNotFound {
+		return w.queue.finish(ctx, d.ID, w.now().UTC(), StatusDead, "webhook was deleted")
+	}
+	if err != nil {
+		return err
+	}
+
+	sendErr := w.send(ctx, sub, d)
+	if sendErr == nil {
+		return w.queue.finish(ctx, d.ID, w.now().UTC(), StatusDelivered, "")
+	}
+	if d.Attempts >= w.MaxAttempts {
+		return w.queue.finish(ctx, d.ID, w.now().UTC(), StatusDead, sendErr.Error())
+	}
+	return w.queue.reschedule(ctx, d.ID, w.now().UTC().Add(w.backoff(d.Attempts)), sendErr.Error())
+}
+
+// claim takes the next delivery due at now. It stays pending but is hidden
+// from other workers for the lease; if this worker dies, it comes up again
+// after the lease expires.
+func (s *Store) claim(ctx context.Context, now time.Time, lease time.Duration) (*Delivery, error) {
+	var d Delivery
+	err := s.deliveries.FindOneAndUpdate(ctx,
+		bson.M{"status": StatusPending, "next_attempt": bson.M{"$lte": now}},
+		bson.M{
+			"$set": bson.M{"next_attempt": now.Add(lease)},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "next_attempt", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&d)
+	if err == mongo.ErrNoDocuments {
+		return nil, errQueueEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// reschedule makes a failed delivery due again at next.
+func (s *Store) reschedule(ctx context.Context, id primitive.ObjectID, next time.Time, lastError string) error {
+	_, err := s.deliveries.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"next_attempt": next, "last_error": lastError}},
+	)
+	return err
+}
+
+// finish moves a delivery to the delivered or dead status at the given
+// time, from which on it expires, see Retention.
+func (s *Store) finish(ctx context.Context, id primitive.ObjectID, at time.Time, status, lastError string) error {
+	set := bson.M{"status": status, "finished_at": at}
+	if lastError != "" {
+		set["last_error"] = lastError
+	}
+	_, err := s.deliveries.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	return err
+}
+
+func (w *Worker) send(ctx context.Context, sub *Subscription, d *Delivery) error {
+	body := []byte(d.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, body))
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, d.ID.Hex())
+
+	res, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("receiver answered %s", res.Status)
+	}
+	return nil
+}
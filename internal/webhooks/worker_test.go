@@ -0,0 +1,420 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memQueue is a queue in memory with the semantics of the Store.
+type memQueue struct {
+	mu         sync.Mutex
+	subs       map[string]*Subscription
+	deliveries []*Delivery
+}
+
+func newMemQueue() *memQueue {
+	return &memQueue{subs: map[string]*Subscription{}}
+}
+
+func (q *memQueue) Get(_ context.Context, id string) (*Subscription, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	sub, ok := q.subs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *sub
+	return &c, nil
+}
+
+func (q *memQueue) claim(_ context.Context, now time.Time, lease time.Duration) (*Delivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due []*Delivery
+	for _, d := range q.deliveries {
+		if d.Status == StatusPending && !d.NextAttempt.After(now) {
+			due = append(due, d)
+		}
+	}
+	if len(due) == 0 {
+		return nil, errQueueEmpty
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttempt.Before(due[j].NextAttempt) })
+	d := due[0]
+	d.NextAttempt = now.Add(lease)
+	d.Attempts++
+	c := *d
+	return &c, nil
+}
+
+func (q *memQueue) find(id primitive.ObjectID) *Delivery {
+	for _, d := range q.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+func (q *memQueue) reschedule(_ context.Context, id primitive.ObjectID, next time.Time, lastError string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	d := q.find(id)
+	d.NextAttempt = next
+	d.LastError = lastError
+	return nil
+}
+
+func (q *memQueue) finish(_ context.Context, id primitive.ObjectID, at time.Time, status, lastError string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	d := q.find(id)
+	d.Status = status
+	d.FinishedAt = &at
+	if lastError != "" {
+		d.LastError = lastError
+	}
+	return nil
+}
+
+// delivery returns a copy of the delivery with the given id.
+func (q *memQueue) delivery(id primitive.ObjectID) Delivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return *q.find(id)
+}
+
+// clock is a manual clock for the worker.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+func (c *clock) set(t time.Time)         { c.t = t }
+
+var start = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// setup returns a worker sending to srv through its client, the queue with
+// a subscription of srv and a pending delivery for it, and the clock of the
+// worker.
+func setup(t *testing.T, srv *httptest.Server) (*Worker, *memQueue, *clock, primitive.ObjectID) {
+	t.Helper()
+	q := newMemQueue()
+	q.subs["sub"] = &Subscription{ID: "sub", URL: srv.URL + "/hook", Secret: "s3cr3t"}
+	id := primitive.NewObjectID()
+	q.deliveries = append(q.deliveries, &Delivery{
+		ID:             id,
+		SubscriptionID: "sub",
+		Event:          "book.created",
+		Payload:        `{"type":"book.created","data":{"id":"42"}}`,
+		Status:         StatusPending,
+		NextAttempt:    start,
+		CreatedAt:      start,
+	})
+
+	c := &clock{t: start}
+	w := NewWorker(nil)
+	w.queue = q
+	w.now = c.now
+	// The httptest receiver listens on loopback, which the client of
+	// NewWorker refuses.
+	w.Client = srv.Client()
+	return w, q, c, id
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"a":1}' | openssl dgst -sha256 -hmac key
+	want := "sha256=88a67f24bbcdaed0e6c997404bb79a743baf44c6bab2f4c27328e3009d22e342"
+	if got := Sign("key", []byte(`{"a":1}`)); got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+	if Sign("other", []byte(`{"a":1}`)) == want {
+		t.Error("Sign() does not depend on the secret")
+	}
+}
+
+func TestDeliverSigned(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{r.Header.Clone(), body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	w, q, _, id := setup(t, srv)
+	if err := w.work(context.Background()); err != nil {
+		t.Fatalf("work() = %v", err)
+	}
+
+	r := <-got
+	d := q.delivery(id)
+	if string(r.body) != d.Payload {
+		t.Errorf("body = %s, want %s", r.body, d.Payload)
+	}
+	if sig, want := r.header.Get(SignatureHeader), Sign("s3cr3t", r.body); sig != want {
+		t.Errorf("%s = %q, want %q", SignatureHeader, sig, want)
+	}
+	if ev := r.header.Get(EventHeader); ev != "book.created" {
+		t.Errorf("%s = %q, want book.created", EventHeader, ev)
+	}
+	if h := r.header.Get(DeliveryHeader); h != id.Hex() {
+		t.Errorf("%s = %q, want %q", DeliveryHeader, h, id.Hex())
+	}
+	if ct := r.header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	if d.Status != StatusDelivered || d.Attempts != 1 {
+		t.Errorf("delivery is %s after %d attempts, want delivered after 1", d.Status, d.Attempts)
+	}
+	if d.FinishedAt == nil || !d.FinishedAt.Equal(start) {
+		t.Errorf("delivery finished at %v, want %v", d.FinishedAt, start)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	w, q, c, id := setup(t, srv)
+	w.Backoff = 5 * time.Second
+	w.MaxBackoff = 30 * time.Second
+	w.MaxAttempts = 6
+
+	// 5s, doubling per attempt, capped at 30s.
+	for i, want := range []time.Duration{5, 10, 20, 30, 30} {
+		want *= time.Second
+		if err := w.work(context.Background()); err != nil {
+			t.Fatalf("attempt %d: work() = %v", i+1, err)
+		}
+		d := q.delivery(id)
+		if d.Status != StatusPending || d.Attempts != i+1 || d.FinishedAt != nil {
+			t.Fatalf("attempt %d: delivery is %s after %d attempts", i+1, d.Status, d.Attempts)
+		}
+		if next := d.NextAttempt.Sub(c.now()); next != want {
+			t.Errorf("attempt %d: next attempt in %v, want %v", i+1, next, want)
+		}
+		if d.LastError != "receiver answered 503 Service Unavailable" {
+			t.Errorf("attempt %d: last error %q", i+1, d.LastError)
+		}
+
+		// Not due before the backoff is over.
+		c.advance(want - time.Millisecond)
+		if err := w.work(context.Background()); err != errQueueEmpty {
+			t.Fatalf("attempt %d: work() before the backoff = %v, want errQueueEmpty", i+1, err)
+		}
+		c.advance(time.Millisecond)
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "no", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	w, q, c, id := setup(t, srv)
+	w.MaxAttempts = 3
+	var last time.Time
+	for i := 0; i < w.MaxAttempts; i++ {
+		last = c.now()
+		if err := w.work(context.Background()); err != nil {
+			t.Fatalf("attempt %d: work() = %v", i+1, err)
+		}
+		c.set(q.delivery(id).NextAttempt)
+	}
+
+	d := q.delivery(id)
+	if d.Status != StatusDead || d.Attempts != 3 {
+		t.Fatalf("delivery is %s after %d attempts, want dead after 3", d.Status, d.Attempts)
+	}
+	if d.FinishedAt == nil || !d.FinishedAt.Equal(last) {
+		t.Errorf("delivery finished at %v, want %v", d.FinishedAt, last)
+	}
+	if d.LastError != "receiver answered 500 Internal Server Error" {
+		t.Errorf("last error %q", d.LastError)
+	}
+	c.advance(24 * time.Hour)
+	if err := w.work(context.Background()); err != errQueueEmpty {
+		t.Errorf("work() after dead-lettering = %v, want errQueueEmpty", err)
+	}
+	if calls != 3 {
+		t.Errorf("receiver called %d times, want 3", calls)
+	}
+}
+
+func TestDeletedSubscription(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("delivered to a deleted webhook")
+	}))
+	defer srv.Close()
+
+	w, q, _, id := setup(t, srv)
+	delete(q.subs, "sub")
+	if err := w.work(context.Background()); err != nil {
+		t.Fatalf("work() = %v", err)
+	}
+	if d := q.delivery(id); d.Status != StatusDead || d.LastError != "webhook was deleted" {
+		t.Errorf("delivery is %s with %q, want dead", d.Status, d.LastError)
+	}
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	w, q, c, id := setup(t, srv)
+	w.Lease = time.Minute
+
+	// A worker claims the delivery and dies before sending it.
+	if _, err := q.claim(context.Background(), c.now(), w.Lease); err != nil {
+		t.Fatalf("claim() = %v", err)
+	}
+
+	// Hidden from other workers during the lease...
+	c.advance(w.Lease - time.Second)
+	if err := w.work(context.Background()); err != errQueueEmpty {
+		t.Fatalf("work() during the lease = %v, want errQueueEmpty", err)
+	}
+
+	// ...and picked up again once it expired.
+	c.advance(time.Second)
+	if err := w.work(context.Background()); err != nil {
+		t.Fatalf("work() after the lease = %v", err)
+	}
+	if d := q.delivery(id); d.Status != StatusDelivered || d.Attempts != 2 {
+		t.Errorf("delivery is %s after %d attempts, want delivered after 2", d.Status, d.Attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	w := &Worker{Backoff: 5 * time.Second, MaxBackoff: time.Hour}
+	for _, tc := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{8, 640 * time.Second},
+		{10, 2560 * time.Second},
+		{11, time.Hour},
+		{100, time.Hour},
+	} {
+		if got := w.backoff(tc.attempts); got != tc.want {
+			t.Errorf("backoff(%d) = %v, want %v", tc.attempts, got, tc.want)
+		}
+	}
+}
+
+func TestForbiddenTarget(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("delivered to a loopback address")
+	}))
+	defer srv.Close()
+
+	w, q, _, id := setup(t, srv)
+	w.Client = newClient()
+	if err := w.work(context.Background()); err != nil {
+		t.Fatalf("work() = %v", err)
+	}
+	d := q.delivery(id)
+	if d.Status != StatusPending || d.LastError == "" {
+		t.Fatalf("delivery is %s with %q, want a failed attempt", d.Status, d.LastError)
+	}
+
+	// The error reaches the client wrapped in a *url.Error.
+	_, err := newClient().Get(srv.URL)
+	if !errors.Is(err, ErrForbiddenTarget) {
+		t.Errorf("Get(%s) = %v, want ErrForbiddenTarget", srv.URL, err)
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+	for _, tc := range []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+	} {
+		if got := publicAddr(netip.MustParseAddr(tc.addr)); got != tc.want {
+			t.Errorf("publicAddr(%s) = %v, want %v", tc.addr, got, tc.want)
+		}
+	}
+}
+
+func TestCheckTarget(t *testing.T) {
+	for _, tc := range []struct {
+		url string
+		ok  bool
+	}{
+		{"https://hooks.example.com/books", true},
+		{"http://93.184.216.34:8080/", true},
+		{"http://localhost:3030/", false},
+		{"http://api.localhost/", false},
+		{"http://127.0.0.1/", false},
+		{"http://[::1]/", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://10.0.0.5/", false},
+	} {
+		u, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkTarget(u); (err == nil) != tc.ok {
+			t.Errorf("checkTarget(%s) = %v, want ok %v", tc.url, err, tc.ok)
+		}
+	}
+}
+
+func TestSubscriptionRequestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		req subscriptionRequest
+		ok  bool
+	}{
+		{subscriptionRequest{URL: "https://hooks.example.com/"}, true},
+		{subscriptionRequest{URL: "https://hooks.example.com/", Events: []string{"book.created"}}, true},
+		{subscriptionRequest{URL: "ftp://hooks.example.com/"}, false},
+		{subscriptionRequest{URL: "/relative"}, false},
+		{subscriptionRequest{URL: "http://169.254.169.254/"}, false},
+		{subscriptionRequest{URL: "https://hooks.example.com/", Events: []string{"book.burnt"}}, false},
+	} {
+		if err := tc.req.validate(); (err == nil) != tc.ok {
+			t.Errorf("validate(%+v) = %v, want ok %v", tc.req, err, tc.ok)
+		}
+	}
+}